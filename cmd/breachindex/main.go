@@ -0,0 +1,74 @@
+// breachindex 将 Have I Been Pwned 格式的泄露密码数据转换为服务使用的本地索引（BREACH_INDEX_FILE）。
+//
+// 用法：
+//
+//	breachindex -out pwned.idx -hashes pwned-passwords-sha1-ordered-by-hash.txt
+//	breachindex -out pwned.idx -range-dir ./ranges
+//	breachindex -index pwned.idx -check 'P@ssw0rd'
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/bryantaolong/system/pkg/breach"
+)
+
+func main() {
+	out := flag.String("out", "", "生成的索引文件")
+	hashes := flag.String("hashes", "", "HASH:次数 格式的文件，须按哈希排序")
+	rangeDir := flag.String("range-dir", "", "按 5 位前缀分桶的目录，每个文件内容为 后缀:次数")
+	index := flag.String("index", "", "查询使用的索引文件")
+	check := flag.String("check", "", "查询该密码在索引中出现的次数")
+	flag.Parse()
+
+	switch {
+	case *check != "" && *index != "":
+		lookup(*index, *check)
+	case *out != "" && (*hashes != "") != (*rangeDir != ""):
+		build(*out, *hashes, *rangeDir)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func build(out, hashes, rangeDir string) {
+	start := time.Now()
+	w, err := breach.CreateIndex(out)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if hashes != "" {
+		err = w.ImportHashes(hashes)
+	} else {
+		err = w.ImportRangeDir(rangeDir)
+	}
+	if err != nil {
+		w.Close()
+		os.Remove(out)
+		log.Fatalf("❌ 导入失败: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		os.Remove(out)
+		log.Fatalf("❌ 写入索引失败: %v", err)
+	}
+	log.Printf("✅ 已写入 %d 个哈希到 %s，耗时 %s", w.Len(), out, time.Since(start).Round(time.Second))
+}
+
+func lookup(path, password string) {
+	x, err := breach.OpenIndex(path)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	defer x.Close()
+	n, err := x.Count(context.Background(), password)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	fmt.Println(n)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bryantaolong/system/internal/service"
+)
+
+// runImport 命令行导入用户，以系统身份执行（不限定租户），返回进程退出码。
+//
+// 用法：
+//
+//	go run ./cmd import -file users.xlsx -dry-run
+//	go run ./cmd import -file users.csv -report users.errors.csv
+func runImport(importService *service.UserImportService, args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	file := fs.String("file", "", "要导入的 CSV 或 XLSX 文件")
+	dryRun := fs.Bool("dry-run", false, "只校验不写入")
+	report := fs.String("report", "", "错误报告文件，默认为 <file>.errors.csv")
+	_ = fs.Parse(args)
+	if *file == "" {
+		fs.Usage()
+		return 2
+	}
+	if *report == "" {
+		*report = strings.TrimSuffix(*file, filepath.Ext(*file)) + ".errors.csv"
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+	table, err := importService.ParseImportFile(data)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+
+	start := time.Now()
+	summary := importService.Run(service.SystemContext(context.Background()), table, *dryRun, func(p service.ImportSummary) {
+		log.Printf("⏳ %d/%d，成功 %d，失败 %d", p.Processed, p.Total, p.Succeeded, p.Failed)
+	})
+	action := "导入"
+	if *dryRun {
+		action = "校验"
+	}
+	log.Printf("✅ %s完成：共 %d 行，成功 %d，失败 %d，耗时 %s", action, summary.Total, summary.Succeeded, summary.Failed, time.Since(start).Round(time.Millisecond))
+	if summary.Failed == 0 {
+		return 0
+	}
+
+	f, err := os.Create(*report)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+	defer f.Close()
+	if err := importService.WriteReport(f, table, summary); err != nil {
+		log.Printf("❌ 写入错误报告失败: %v", err)
+		return 1
+	}
+	log.Printf("⚠️ 错误报告已写入 %s", *report)
+	return 1
+}
@@ -1,12 +1,34 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/bryantaolong/system/internal/config"
+	"github.com/bryantaolong/system/internal/model/request"
 	"github.com/bryantaolong/system/internal/router"
 	"github.com/bryantaolong/system/internal/service"
+	"github.com/bryantaolong/system/pkg/blob"
+	"github.com/bryantaolong/system/pkg/breach"
 	"github.com/bryantaolong/system/pkg/db"
+	"github.com/bryantaolong/system/pkg/geoip"
+	http2 "github.com/bryantaolong/system/pkg/http"
+	"github.com/bryantaolong/system/pkg/notify"
+	"github.com/bryantaolong/system/pkg/password"
+	"github.com/bryantaolong/system/pkg/ratelimit"
+	"github.com/bryantaolong/system/pkg/siem"
 	"github.com/go-redis/redis/v8"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
@@ -17,6 +39,12 @@ func main() {
 
 	cfg := config.Load()
 
+	request.RegisterBindingValidators()
+
+	if err := http2.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("❌ 受信任代理配置错误: %v", err)
+	}
+
 	db := db.Init(cfg)
 
 	redisClient := redis.NewClient(&redis.Options{
@@ -28,12 +56,286 @@ func main() {
 	logger := logrus.New()
 	logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
 
-	authService := service.NewAuthService(db, redisClient)
-	userService := service.NewUserService(db, authService)
+	forwarder, err := newSIEMPipeline(cfg)
+	if err != nil {
+		log.Fatalf("❌ 初始化 SIEM 转发失败: %v", err)
+	}
+
+	auditService := service.NewAuditService(db, forwarder)
+
+	var geo *geoip.DB
+	if cfg.GeoIPFile != "" {
+		if geo, err = geoip.Open(cfg.GeoIPFile); err != nil {
+			log.Fatalf("❌ 加载 GeoIP 数据失败: %v", err)
+		}
+	}
+	var notifier notify.Notifier = notify.LogNotifier{}
+	if cfg.NotifySMTPAddr != "" {
+		notifier = notify.SMTPNotifier{
+			Addr:     cfg.NotifySMTPAddr,
+			From:     cfg.NotifySMTPFrom,
+			Username: cfg.NotifySMTPUsername,
+			Password: cfg.NotifySMTPPassword,
+		}
+	}
+	riskService := service.NewLoginRiskService(db, geo, notifier, auditService, redisClient, service.LoginRiskConfig{
+		FailureBurstThreshold: cfg.LoginFailureBurstThreshold,
+		FailureBurstWindow:    cfg.LoginFailureBurstWindow,
+	})
+
+	denyList, err := password.LoadDenyList(cfg.PasswordDenyFile)
+	if err != nil {
+		log.Fatalf("❌ 加载密码黑名单失败: %v", err)
+	}
+	hasher, err := password.NewHasher(password.HasherConfig{
+		Algorithm: cfg.PasswordHashAlgorithm,
+		Argon2id: password.Argon2idParams{
+			Memory:      uint32(cfg.PasswordArgon2Memory),
+			Iterations:  uint32(cfg.PasswordArgon2Iterations),
+			Parallelism: uint8(cfg.PasswordArgon2Parallelism),
+		},
+		BcryptCost: cfg.PasswordBcryptCost,
+		Pepper:     cfg.PasswordPepper,
+	})
+	if err != nil {
+		log.Fatalf("❌ 密码哈希配置错误: %v", err)
+	}
+	passwordPolicy := &password.Policy{
+		MinLength:       cfg.PasswordMinLength,
+		MaxLength:       cfg.PasswordMaxLength,
+		MinClasses:      cfg.PasswordMinClasses,
+		MinScore:        cfg.PasswordMinScore,
+		DenyUserInfo:    cfg.PasswordDenyUserInfo,
+		Deny:            denyList,
+		BreachThreshold: cfg.BreachThreshold,
+	}
+	switch {
+	case cfg.BreachIndexFile != "":
+		index, err := breach.OpenIndex(cfg.BreachIndexFile)
+		if err != nil {
+			log.Fatalf("❌ 加载泄露密码索引失败: %v", err)
+		}
+		passwordPolicy.Breach = index
+	case cfg.BreachAPIURL != "":
+		passwordPolicy.Breach = breach.NewRangeClient(cfg.BreachAPIURL, cfg.BreachAPITimeout)
+	}
+
+	attributeService := service.NewAttributeSchemaService(db)
+	authService := service.NewAuthService(db, redisClient, auditService, riskService, service.LockoutPolicy{
+		Threshold:    cfg.LockoutThreshold,
+		Window:       cfg.LockoutWindow,
+		BaseDuration: cfg.LockoutBaseDuration,
+		MaxDuration:  cfg.LockoutMaxDuration,
+		ResetAfter:   cfg.LockoutResetAfter,
+	}, passwordPolicy, service.PasswordRotationPolicy{
+		MaxAge:     cfg.PasswordMaxAge,
+		WarnBefore: cfg.PasswordExpiryWarning,
+		History:    cfg.PasswordHistory,
+	}, hasher, attributeService)
+	userService := service.NewUserService(db, authService, auditService, attributeService)
+	blobStore, err := newBlobStore(cfg)
+	if err != nil {
+		log.Fatalf("❌ 初始化对象存储失败: %v", err)
+	}
+	avatarOptions, err := newAvatarOptions(cfg)
+	if err != nil {
+		log.Fatalf("❌ 头像配置错误: %v", err)
+	}
+	profileService := service.NewUserProfileService(db, userService, auditService, blobStore, avatarOptions)
+	importService := service.NewUserImportService(authService, profileService, redisClient, blobStore, service.ImportOptions{
+		MaxSize:      int64(cfg.ImportMaxSize),
+		MaxRows:      cfg.ImportMaxRows,
+		JobTTL:       cfg.ImportJobTTL,
+		ReportExpiry: cfg.ImportReportURLExpiry,
+	})
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		code := runImport(importService, os.Args[2:])
+		closeSIEMPipeline(forwarder)
+		os.Exit(code)
+	}
 	userRoleService := service.NewUserRoleService(db)
+	roleChangeService := service.NewRoleChangeService(db, userService, cfg.SensitiveRoles)
+	policyService, err := service.NewPolicyService(db, cfg.PolicyFile)
+	if err != nil {
+		log.Fatalf("❌ 加载访问控制策略失败: %v", err)
+	}
+
+	bulkService := service.NewUserBulkService(db, userService, roleChangeService, policyService, auditService, redisClient)
+	purgeService := service.NewUserPurgeService(db, auditService, profileService, redisClient, service.UserPurgeOptions{
+		Retention: time.Duration(cfg.UserPurgeRetentionDays) * 24 * time.Hour,
+		Interval:  cfg.UserPurgeInterval,
+	})
+	// 收到退出信号时取消后台任务，并在退出前关闭 HTTP 服务与 SIEM 转发
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	purgeService.Start(service.SystemContext(ctx))
 
-	router := router.NewRouter(redisClient, authService, userService, userRoleService)
+	orgService := service.NewOrganizationService(db)
+	groupService := service.NewUserGroupService(db, redisClient, cfg.SensitiveRoles)
+	impersonationService := service.NewImpersonationService(db, redisClient)
+	loginLogService := service.NewLoginLogService(db)
 
+	limiter, limits, err := newRateLimiter(cfg, redisClient)
+	if err != nil {
+		log.Fatalf("❌ 限流配置错误: %v", err)
+	}
+
+	router := router.NewRouter(redisClient, authService, userService, profileService, attributeService, importService, bulkService, purgeService, userRoleService, roleChangeService, policyService, orgService, groupService, impersonationService, auditService, loginLogService, blobStore, limiter, limits)
+
+	srv := &http.Server{Addr: ":8080", Handler: router}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("❌ 服务启动失败: %v", err)
+		}
+	}()
 	log.Println("🚀 项目已启动，监听 :8080")
-	log.Fatal(router.Run(":8080"))
+
+	<-ctx.Done()
+	log.Println("⏹️ 收到退出信号，正在关闭")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("关闭 HTTP 服务失败: %v", err)
+	}
+	closeSIEMPipeline(forwarder)
+}
+
+// shutdownTimeout 退出时等待处理中的请求和 SIEM 队列中事件发送完成的最长时间
+const shutdownTimeout = 10 * time.Second
+
+// closeSIEMPipeline 发送队列中剩余的审计事件后关闭转发管道，pipeline 为空时直接返回
+func closeSIEMPipeline(pipeline *siem.Pipeline) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := pipeline.Close(ctx); err != nil {
+		log.Printf("关闭 SIEM 转发失败: %v", err)
+	}
+}
+
+// newBlobStore 根据配置创建对象存储
+func newBlobStore(cfg *config.Config) (blob.Store, error) {
+	switch cfg.BlobBackend {
+	case "local":
+		key := cfg.BlobSigningKey
+		if key == "" {
+			b := make([]byte, 32)
+			if _, err := rand.Read(b); err != nil {
+				return nil, err
+			}
+			key = hex.EncodeToString(b)
+			log.Println("⚠️ 未配置 BLOB_SIGNING_KEY，使用随机密钥，重启后已签发的访问地址失效")
+		}
+		return blob.NewLocalStore(cfg.BlobLocalDir, cfg.BlobLocalURL, key)
+	case "s3":
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+			Timeout:   cfg.S3Timeout,
+		})
+	default:
+		return nil, fmt.Errorf("不支持的对象存储: %s", cfg.BlobBackend)
+	}
+}
+
+// newAvatarOptions 解析头像缩略图尺寸等配置
+func newAvatarOptions(cfg *config.Config) (service.AvatarOptions, error) {
+	opts := service.AvatarOptions{
+		MaxSize:   int64(cfg.AvatarMaxSize),
+		Quality:   85,
+		URLExpiry: cfg.AvatarURLExpiry,
+	}
+	for _, v := range cfg.AvatarSizes {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 16 || size > 1024 {
+			return opts, fmt.Errorf("头像尺寸应为 16-1024 之间的整数: %s", v)
+		}
+		opts.Sizes = append(opts.Sizes, size)
+	}
+	if len(opts.Sizes) == 0 {
+		return opts, fmt.Errorf("至少需要一个头像尺寸")
+	}
+	return opts, nil
+}
+
+// newRateLimiter 根据配置创建限流器并解析各接口的限流速率
+func newRateLimiter(cfg *config.Config, redisClient *redis.Client) (ratelimit.Limiter, router.RateLimits, error) {
+	var limiter ratelimit.Limiter
+	switch cfg.RateLimitBackend {
+	case "redis":
+		limiter = ratelimit.NewRedisLimiter(redisClient, "ratelimit:")
+	case "memory":
+		limiter = ratelimit.NewMemoryLimiter()
+	default:
+		return nil, router.RateLimits{}, fmt.Errorf("不支持的限流后端: %s", cfg.RateLimitBackend)
+	}
+
+	var limits router.RateLimits
+	for _, item := range []struct {
+		spec string
+		rate *ratelimit.Rate
+	}{
+		{cfg.RateLimitLoginIP, &limits.LoginPerIP},
+		{cfg.RateLimitLoginUsername, &limits.LoginPerUsername},
+		{cfg.RateLimitRegisterIP, &limits.RegisterPerIP},
+		{cfg.RateLimitReauthUser, &limits.ReauthPerUser},
+	} {
+		rate, err := ratelimit.ParseRate(item.spec)
+		if err != nil {
+			return nil, router.RateLimits{}, err
+		}
+		*item.rate = rate
+	}
+	return limiter, limits, nil
+}
+
+// newSIEMPipeline 根据配置创建审计事件转发管道，未配置任何目标时返回 nil
+func newSIEMPipeline(cfg *config.Config) (*siem.Pipeline, error) {
+	var sinks []siem.Sink
+	if cfg.SIEMSyslogAddr != "" {
+		var formatter siem.Formatter
+		switch cfg.SIEMSyslogFormat {
+		case "cef":
+			formatter = siem.CEFFormatter{Vendor: "bryantaolong", Product: cfg.SIEMAppName, Version: "1.0"}
+		case "json":
+			formatter = siem.JSONFormatter{}
+		default:
+			return nil, fmt.Errorf("不支持的 syslog 消息格式: %s", cfg.SIEMSyslogFormat)
+		}
+
+		var tlsConfig *tls.Config
+		if cfg.SIEMSyslogCAFile != "" {
+			pem, err := os.ReadFile(cfg.SIEMSyslogCAFile)
+			if err != nil {
+				return nil, err
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("CA 文件中没有有效证书: %s", cfg.SIEMSyslogCAFile)
+			}
+			tlsConfig = &tls.Config{RootCAs: pool}
+		}
+
+		sink, err := siem.NewSyslogSink(siem.SyslogConfig{
+			Network:   cfg.SIEMSyslogNetwork,
+			Addr:      cfg.SIEMSyslogAddr,
+			TLSConfig: tlsConfig,
+			AppName:   cfg.SIEMAppName,
+			Formatter: formatter,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if cfg.SIEMWebhookURL != "" {
+		sinks = append(sinks, siem.NewWebhookSink(cfg.SIEMWebhookURL, cfg.SIEMWebhookSecret))
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	return siem.NewPipeline(cfg.SIEMBufferSize, sinks...), nil
 }
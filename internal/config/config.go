@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -15,6 +18,105 @@ type Config struct {
 	RedisPort  string
 	RedisPass  string
 	JWTSecret  string
+
+	// SensitiveRoles 变更时需要第二位管理员审批的角色
+	SensitiveRoles []string
+	// PolicyFile 访问控制策略规则文件（JSON），为空时仅使用内置规则
+	PolicyFile string
+
+	// SIEM 审计事件转发，地址为空时不启用对应目标
+	SIEMSyslogAddr    string // syslog 服务器 host:port
+	SIEMSyslogNetwork string // udp、tcp 或 tls
+	SIEMSyslogFormat  string // cef 或 json
+	SIEMSyslogCAFile  string // tls 传输时用于校验服务器证书的 CA 文件，为空时使用系统根证书
+	SIEMWebhookURL    string
+	SIEMWebhookSecret string // Webhook 请求 HMAC 签名密钥
+	SIEMAppName       string
+	SIEMBufferSize    int // 每个转发目标的缓冲事件数
+
+	// GeoIPFile 本地 GeoIP CSV 文件，为空时不做位置相关的可疑登录检测
+	GeoIPFile string
+	// 同一 IP 在窗口内登录失败的不同账号数达到阈值时告警
+	LoginFailureBurstThreshold int
+	LoginFailureBurstWindow    time.Duration
+
+	// 邮件通知，未配置 SMTP 地址时通知只写入日志
+	NotifySMTPAddr     string
+	NotifySMTPFrom     string
+	NotifySMTPUsername string
+	NotifySMTPPassword string
+
+	// 受信任的反向代理（IP 或 CIDR），只有经由它们转发的请求才采信 X-Forwarded-For
+	TrustedProxies []string
+
+	// 认证接口限流，速率格式为 次数/时长（如 10/1m），次数为 0 表示不限制
+	RateLimitBackend       string // redis 或 memory
+	RateLimitLoginIP       string
+	RateLimitLoginUsername string
+	RateLimitRegisterIP    string
+	RateLimitReauthUser    string
+
+	// 账号锁定策略：窗口内连续失败达到阈值即锁定，再次锁定时长翻倍直至上限，阈值为 0 表示不锁定
+	LockoutThreshold    int
+	LockoutWindow       time.Duration
+	LockoutBaseDuration time.Duration
+	LockoutMaxDuration  time.Duration
+	LockoutResetAfter   time.Duration // 距上次失败超过该时长后锁定次数清零
+
+	// 密码策略，数值为 0 表示不检查该项
+	PasswordMinLength    int
+	PasswordMaxLength    int
+	PasswordMinClasses   int    // 大写、小写、数字、符号四类中至少包含的类别数
+	PasswordMinScore     int    // 最低强度评分 0-4
+	PasswordDenyUserInfo bool   // 密码不得包含用户名、邮箱、手机号
+	PasswordDenyFile     string // 追加的常见密码黑名单文件，每行一个
+
+	// 密码轮换：最长使用时间（0 表示永不过期）、过期前提醒时长、禁止重复使用的最近密码个数
+	PasswordMaxAge        time.Duration
+	PasswordExpiryWarning time.Duration
+	PasswordHistory       int
+
+	// 密码哈希：新密码使用的算法（argon2id 或 bcrypt）及参数，旧哈希在用户下次登录时自动升级
+	PasswordHashAlgorithm     string
+	PasswordArgon2Memory      int // KiB
+	PasswordArgon2Iterations  int
+	PasswordArgon2Parallelism int
+	PasswordBcryptCost        int
+	PasswordPepper            string // 服务端 pepper，设置后不能随意更换，否则已有密码无法校验
+
+	// 泄露密码检查：优先使用本地索引（cmd/breachindex 生成），其次使用范围查询接口，均为空时不检查
+	BreachIndexFile  string
+	BreachAPIURL     string // 如 https://api.pwnedpasswords.com 或内网替代服务
+	BreachAPITimeout time.Duration
+	BreachThreshold  int // 出现次数达到该值即拒绝
+
+	// 对象存储（头像等）：local 保存在本地目录并由 /api/blob 提供签名下载，s3 使用 S3 兼容服务
+	BlobBackend    string
+	BlobLocalDir   string
+	BlobLocalURL   string // 本地存储签名地址的前缀
+	BlobSigningKey string // 本地存储签名密钥，为空时使用 JWT_SECRET
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3PathStyle    bool // MinIO 等自建服务通常需要 path-style 地址
+	S3Timeout      time.Duration
+
+	// 头像上传：文件大小上限（字节）、生成的缩略图边长、签名地址有效期
+	AvatarMaxSize   int
+	AvatarSizes     []string
+	AvatarURLExpiry time.Duration
+
+	// 批量导入用户：文件大小上限（字节）、行数上限、任务保留时间、错误报告下载地址有效期
+	ImportMaxSize         int
+	ImportMaxRows         int
+	ImportJobTTL          time.Duration
+	ImportReportURLExpiry time.Duration
+
+	// 已删除用户的保留天数（0 表示不彻底清除）和清除任务的执行间隔
+	UserPurgeRetentionDays int
+	UserPurgeInterval      time.Duration
 }
 
 func Load() *Config {
@@ -29,5 +131,137 @@ func Load() *Config {
 		RedisPort:  os.Getenv("REDIS_PORT"),
 		RedisPass:  os.Getenv("REDIS_PASSWORD"),
 		JWTSecret:  os.Getenv("JWT_SECRET"),
+
+		SensitiveRoles: getEnvList("SENSITIVE_ROLES", []string{"ROLE_ADMIN"}),
+		PolicyFile:     os.Getenv("POLICY_FILE"),
+
+		SIEMSyslogAddr:    os.Getenv("SIEM_SYSLOG_ADDR"),
+		SIEMSyslogNetwork: getEnv("SIEM_SYSLOG_NETWORK", "udp"),
+		SIEMSyslogFormat:  getEnv("SIEM_SYSLOG_FORMAT", "cef"),
+		SIEMSyslogCAFile:  os.Getenv("SIEM_SYSLOG_CA_FILE"),
+		SIEMWebhookURL:    os.Getenv("SIEM_WEBHOOK_URL"),
+		SIEMWebhookSecret: os.Getenv("SIEM_WEBHOOK_SECRET"),
+		SIEMAppName:       getEnv("SIEM_APP_NAME", "user-system"),
+		SIEMBufferSize:    getEnvInt("SIEM_BUFFER_SIZE", 1000),
+
+		GeoIPFile:                  os.Getenv("GEOIP_FILE"),
+		LoginFailureBurstThreshold: getEnvInt("LOGIN_FAILURE_BURST_THRESHOLD", 10),
+		LoginFailureBurstWindow:    getEnvDuration("LOGIN_FAILURE_BURST_WINDOW", 10*time.Minute),
+
+		NotifySMTPAddr:     os.Getenv("NOTIFY_SMTP_ADDR"),
+		NotifySMTPFrom:     os.Getenv("NOTIFY_SMTP_FROM"),
+		NotifySMTPUsername: os.Getenv("NOTIFY_SMTP_USERNAME"),
+		NotifySMTPPassword: os.Getenv("NOTIFY_SMTP_PASSWORD"),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES", nil),
+
+		RateLimitBackend:       getEnv("RATE_LIMIT_BACKEND", "redis"),
+		RateLimitLoginIP:       getEnv("RATE_LIMIT_LOGIN_IP", "20/1m"),
+		RateLimitLoginUsername: getEnv("RATE_LIMIT_LOGIN_USERNAME", "5/1m"),
+		RateLimitRegisterIP:    getEnv("RATE_LIMIT_REGISTER_IP", "5/1h"),
+		RateLimitReauthUser:    getEnv("RATE_LIMIT_REAUTH_USER", "5/5m"),
+
+		LockoutThreshold:    getEnvInt("LOCKOUT_THRESHOLD", 5),
+		LockoutWindow:       getEnvDuration("LOCKOUT_WINDOW", 15*time.Minute),
+		LockoutBaseDuration: getEnvDuration("LOCKOUT_BASE_DURATION", 15*time.Minute),
+		LockoutMaxDuration:  getEnvDuration("LOCKOUT_MAX_DURATION", 24*time.Hour),
+		LockoutResetAfter:   getEnvDuration("LOCKOUT_RESET_AFTER", 24*time.Hour),
+
+		PasswordMinLength:    getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMaxLength:    getEnvInt("PASSWORD_MAX_LENGTH", 72),
+		PasswordMinClasses:   getEnvInt("PASSWORD_MIN_CLASSES", 3),
+		PasswordMinScore:     getEnvInt("PASSWORD_MIN_SCORE", 2),
+		PasswordDenyUserInfo: getEnvBool("PASSWORD_DENY_USER_INFO", true),
+		PasswordDenyFile:     os.Getenv("PASSWORD_DENY_FILE"),
+
+		PasswordMaxAge:        getEnvDuration("PASSWORD_MAX_AGE", 90*24*time.Hour),
+		PasswordExpiryWarning: getEnvDuration("PASSWORD_EXPIRY_WARNING", 14*24*time.Hour),
+		PasswordHistory:       getEnvInt("PASSWORD_HISTORY", 5),
+
+		PasswordHashAlgorithm:     getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+		PasswordArgon2Memory:      getEnvInt("PASSWORD_ARGON2_MEMORY", 19456),
+		PasswordArgon2Iterations:  getEnvInt("PASSWORD_ARGON2_ITERATIONS", 2),
+		PasswordArgon2Parallelism: getEnvInt("PASSWORD_ARGON2_PARALLELISM", 1),
+		PasswordBcryptCost:        getEnvInt("PASSWORD_BCRYPT_COST", 10),
+		PasswordPepper:            os.Getenv("PASSWORD_PEPPER"),
+
+		BreachIndexFile:  os.Getenv("BREACH_INDEX_FILE"),
+		BreachAPIURL:     os.Getenv("BREACH_API_URL"),
+		BreachAPITimeout: getEnvDuration("BREACH_API_TIMEOUT", 3*time.Second),
+		BreachThreshold:  getEnvInt("BREACH_THRESHOLD", 1),
+
+		BlobBackend:    getEnv("BLOB_BACKEND", "local"),
+		BlobLocalDir:   getEnv("BLOB_LOCAL_DIR", "./data/blob"),
+		BlobLocalURL:   getEnv("BLOB_LOCAL_URL", "/api/blob"),
+		BlobSigningKey: getEnv("BLOB_SIGNING_KEY", os.Getenv("JWT_SECRET")),
+		S3Endpoint:     os.Getenv("S3_ENDPOINT"),
+		S3Region:       getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:       os.Getenv("S3_BUCKET"),
+		S3AccessKey:    os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:    os.Getenv("S3_SECRET_KEY"),
+		S3PathStyle:    getEnvBool("S3_PATH_STYLE", true),
+		S3Timeout:      getEnvDuration("S3_TIMEOUT", 10*time.Second),
+
+		AvatarMaxSize:   getEnvInt("AVATAR_MAX_SIZE", 5<<20),
+		AvatarSizes:     getEnvList("AVATAR_SIZES", []string{"64", "128", "256"}),
+		AvatarURLExpiry: getEnvDuration("AVATAR_URL_EXPIRY", time.Hour),
+
+		ImportMaxSize:         getEnvInt("IMPORT_MAX_SIZE", 10<<20),
+		ImportMaxRows:         getEnvInt("IMPORT_MAX_ROWS", 5000),
+		ImportJobTTL:          getEnvDuration("IMPORT_JOB_TTL", 24*time.Hour),
+		ImportReportURLExpiry: getEnvDuration("IMPORT_REPORT_URL_EXPIRY", time.Hour),
+
+		UserPurgeRetentionDays: getEnvInt("USER_PURGE_RETENTION_DAYS", 0),
+		UserPurgeInterval:      getEnvDuration("USER_PURGE_INTERVAL", time.Hour),
+	}
+}
+
+// getEnv 读取环境变量，未设置时返回默认值
+func getEnv(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// getEnvInt 读取整数环境变量，未设置或格式错误时返回默认值
+func getEnvInt(key string, def int) int {
+	v, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvBool 读取布尔环境变量（true/false/1/0），未设置或格式错误时返回默认值
+func getEnvBool(key string, def bool) bool {
+	b, err := strconv.ParseBool(getEnv(key, ""))
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// getEnvDuration 读取时长环境变量（如 10m、1h），未设置或格式错误时返回默认值
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(getEnv(key, ""))
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// getEnvList 读取以英文逗号分隔的环境变量
+func getEnvList(key string, def []string) []string {
+	v := getEnv(key, "")
+	if v == "" {
+		return def
+	}
+	list := make([]string, 0)
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
 	}
+	return list
 }
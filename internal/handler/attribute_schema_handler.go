@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maxSchemaSize 扩展属性 schema 的最大字节数
+const maxSchemaSize = 64 << 10
+
+type AttributeSchemaHandler struct {
+	attributeService *service.AttributeSchemaService
+}
+
+func NewAttributeSchemaHandler(attributeService *service.AttributeSchemaService) *AttributeSchemaHandler {
+	return &AttributeSchemaHandler{attributeService: attributeService}
+}
+
+// Get GET /api/user/attributes/schema?tenantId=1
+func (h *AttributeSchemaHandler) Get(c *gin.Context) {
+	def, err := h.attributeService.Get(c, queryTenantID(c))
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, def)
+}
+
+// Save PUT /api/user/attributes/schema?tenantId=1，请求体为 JSON Schema 文档
+func (h *AttributeSchemaHandler) Save(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSchemaSize+1))
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	if len(body) > maxSchemaSize {
+		response.Fail(c, "schema 不能超过 64 KB")
+		return
+	}
+	if !json.Valid(body) {
+		response.Fail(c, "schema 必须是合法的 JSON")
+		return
+	}
+	def, err := h.attributeService.Save(c, queryTenantID(c), body)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, def)
+}
+
+// Delete DELETE /api/user/attributes/schema?tenantId=1
+func (h *AttributeSchemaHandler) Delete(c *gin.Context) {
+	if err := h.attributeService.Delete(c, queryTenantID(c)); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, nil)
+}
+
+// queryTenantID 读取查询参数 tenantId，未指定或格式错误时返回 nil
+func queryTenantID(c *gin.Context) *int64 {
+	v, err := strconv.ParseInt(c.Query("tenantId"), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
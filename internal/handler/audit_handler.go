@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// auditCSVHeader 审计事件 CSV 导出的列
+var auditCSVHeader = []string{
+	"id", "createdAt", "tenantId", "action", "result", "reason",
+	"actorId", "actorName", "impersonator", "targetId", "targetName",
+	"ip", "userAgent", "requestId", "changes",
+}
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// Search GET /api/audit/events?actorId=&targetId=&action=&startTime=&endTime=&ip=&pageNum=&pageSize=
+func (h *AuditHandler) Search(c *gin.Context) {
+	var req request.AuditSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	var pageReq request.PageRequest
+	if err := c.ShouldBindQuery(&pageReq); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	events, total, err := h.auditService.Search(c, req, pageReq)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"list": events, "total": total})
+}
+
+// Export GET /api/audit/events/export?format=csv|jsonl，查询条件与 Search 相同，按 ID 升序流式输出
+func (h *AuditHandler) Export(c *gin.Context) {
+	var req request.AuditSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	var contentType string
+	switch format {
+	case "csv":
+		contentType = "text/csv; charset=utf-8"
+	case "jsonl":
+		contentType = "application/x-ndjson"
+	default:
+		response.Fail(c, "导出格式只支持 csv 或 jsonl")
+		return
+	}
+
+	filename := fmt.Sprintf("audit_events_%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(200)
+
+	var err error
+	if format == "csv" {
+		err = h.exportCSV(c, req)
+	} else {
+		err = h.exportJSONLines(c, req)
+	}
+	// 响应头已发出，出错时只能中断输出并记录日志
+	if err != nil {
+		log.Printf("导出审计事件失败: %v", err)
+		_ = c.Error(err)
+	}
+}
+
+// exportCSV 以 CSV 格式逐批写出审计事件
+func (h *AuditHandler) exportCSV(c *gin.Context, req request.AuditSearchRequest) error {
+	w := csv.NewWriter(c.Writer)
+	// UTF-8 BOM，保证 Excel 正确识别中文
+	if _, err := c.Writer.WriteString("\ufeff"); err != nil {
+		return err
+	}
+	if err := w.Write(auditCSVHeader); err != nil {
+		return err
+	}
+	return h.auditService.Export(c, req, func(batch []entity.AuditEvent) error {
+		for i := range batch {
+			if err := w.Write(auditCSVRecord(&batch[i])); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		c.Writer.Flush()
+		return w.Error()
+	})
+}
+
+// exportJSONLines 以 JSON Lines 格式逐批写出审计事件，每行一个事件
+func (h *AuditHandler) exportJSONLines(c *gin.Context, req request.AuditSearchRequest) error {
+	enc := json.NewEncoder(c.Writer)
+	return h.auditService.Export(c, req, func(batch []entity.AuditEvent) error {
+		for i := range batch {
+			if err := enc.Encode(&batch[i]); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+}
+
+// auditCSVRecord 将审计事件转换为 CSV 行
+func auditCSVRecord(e *entity.AuditEvent) []string {
+	return []string{
+		strconv.FormatInt(e.ID, 10),
+		e.CreatedAt.Format(time.RFC3339),
+		strconv.FormatInt(e.TenantID, 10),
+		e.Action,
+		e.Result,
+		csvSafe(e.Reason),
+		strconv.FormatInt(e.ActorID, 10),
+		csvSafe(e.ActorName),
+		csvSafe(e.Impersonator),
+		strconv.FormatInt(e.TargetID, 10),
+		csvSafe(e.TargetName),
+		csvSafe(e.IP),
+		csvSafe(e.UserAgent),
+		csvSafe(e.RequestID),
+		e.Changes,
+	}
+}
+
+// csvSafe 防止表格软件把以 = + - @ 开头的内容当作公式执行
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"errors"
 	_ "net/http"
 
+	"github.com/bryantaolong/system/internal/model/entity"
 	"github.com/bryantaolong/system/internal/model/request"
 	"github.com/bryantaolong/system/internal/model/response"
 	"github.com/bryantaolong/system/internal/service"
+	"github.com/bryantaolong/system/pkg/jsonschema"
+	"github.com/bryantaolong/system/pkg/jwt"
+	"github.com/bryantaolong/system/pkg/password"
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,26 +28,65 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		response.Fail(c, err.Error())
 		return
 	}
-	user, err := h.authService.Register(c.Request.Context(), req) // 注意这里
+	user, err := h.authService.Register(c, req)
 	if err != nil {
-		response.Fail(c, err.Error())
+		failWithViolations(c, err)
 		return
 	}
 	response.Success(c, user)
 }
 
+// CheckPassword POST /api/auth/password/check 返回密码强度评分及不满足的策略，不保存任何内容
+func (h *AuthHandler) CheckPassword(c *gin.Context) {
+	var req request.PasswordCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	strength, violations := h.authService.PasswordStrength(c, req)
+	response.Success(c, gin.H{
+		"score":      strength.Score,
+		"entropy":    strength.Entropy,
+		"valid":      len(violations) == 0,
+		"violations": violations,
+	})
+}
+
+// failWithViolations 密码不满足策略时在 data 中返回违规列表，扩展属性不满足定义时返回各属性的错误，
+// 其他错误按普通失败处理
+func failWithViolations(c *gin.Context, err error) {
+	var policyErr *password.PolicyError
+	if errors.As(err, &policyErr) {
+		response.FailWithData(c, err.Error(), gin.H{"violations": policyErr.Violations})
+		return
+	}
+	var schemaErr *jsonschema.ValidationError
+	if errors.As(err, &schemaErr) {
+		response.FailWithData(c, err.Error(), gin.H{"errors": schemaErr.Errors})
+		return
+	}
+	response.Fail(c, err.Error())
+}
+
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req request.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.Fail(c, err.Error())
 		return
 	}
-	token, err := h.authService.Login(req, c.Request)
+	result, err := h.authService.Login(req, c.Request)
 	if err != nil {
 		response.Fail(c, err.Error())
 		return
 	}
-	response.Success(c, gin.H{"token": token})
+	response.Success(c, result)
+}
+
+// meResponse 当前用户信息，模拟登录时标明真实操作人
+type meResponse struct {
+	*entity.User
+	Impersonated bool       `json:"impersonated"`
+	Impersonator *jwt.Actor `json:"impersonator,omitempty"`
 }
 
 func (h *AuthHandler) Me(c *gin.Context) {
@@ -52,12 +96,35 @@ func (h *AuthHandler) Me(c *gin.Context) {
 		response.Unauthorized(c, err.Error())
 		return
 	}
-	response.Success(c, user)
+	resp := meResponse{User: user}
+	if v, exists := c.Get(jwt.ContextKey); exists {
+		if claims, ok := v.(*jwt.CustomClaims); ok && claims.IsImpersonation() {
+			resp.Impersonated = true
+			resp.Impersonator = claims.Act
+		}
+	}
+	response.Success(c, resp)
+}
+
+// Reauth POST /api/auth/reauth 重新验证密码，刷新 Token 中的认证时间
+func (h *AuthHandler) Reauth(c *gin.Context) {
+	var req request.ReauthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	token := c.GetHeader("Authorization")[7:]
+	newToken, err := h.authService.Reauthenticate(c, token, req)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"token": newToken})
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
 	token := c.GetHeader("Authorization")[7:]
-	err := h.authService.Logout(token)
+	err := h.authService.Logout(c, token)
 	if err != nil {
 		response.Fail(c, err.Error())
 		return
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/pkg/blob"
+	"github.com/gin-gonic/gin"
+)
+
+// BlobHandler 提供本地对象存储的下载接口，只接受 LocalStore 签名过的地址
+type BlobHandler struct {
+	store *blob.LocalStore
+}
+
+func NewBlobHandler(store *blob.LocalStore) *BlobHandler {
+	return &BlobHandler{store: store}
+}
+
+// Serve GET /api/blob/*key?expires=..&signature=..
+func (h *BlobHandler) Serve(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := h.store.Verify(key, c.Query("expires"), c.Query("signature")); err != nil {
+		response.Forbidden(c, err.Error())
+		return
+	}
+	rc, err := h.store.Get(c, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		response.InternalError(c, err.Error())
+		return
+	}
+	defer rc.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, rc)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ImpersonationHandler struct {
+	impersonationService *service.ImpersonationService
+	userService          *service.UserService
+	policyService        *service.PolicyService
+}
+
+func NewImpersonationHandler(impersonationService *service.ImpersonationService, userService *service.UserService, policyService *service.PolicyService) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: impersonationService,
+		userService:          userService,
+		policyService:        policyService,
+	}
+}
+
+// Impersonate POST /api/admin/impersonate/:userId
+func (h *ImpersonationHandler) Impersonate(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		response.Fail(c, "userId 必须是整数")
+		return
+	}
+	var req request.ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+
+	target, err := h.userService.GetUserByID(c, userID)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	decision, err := h.policyService.Authorize(c, service.ActionUserImpersonate, target)
+	if err != nil {
+		response.InternalError(c, err.Error())
+		return
+	}
+	if !decision.Allowed {
+		response.Forbidden(c, "权限不足")
+		return
+	}
+
+	token, claims, err := h.impersonationService.Start(c, target, req.Reason)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{
+		"token":     token,
+		"expiresAt": claims.ExpiresAt.Time,
+		"actor":     claims.Act,
+	})
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type LoginLogHandler struct {
+	loginLogService *service.LoginLogService
+}
+
+func NewLoginLogHandler(loginLogService *service.LoginLogService) *LoginLogHandler {
+	return &LoginLogHandler{loginLogService: loginLogService}
+}
+
+// UserLogins GET /api/user/:userId/logins?pageNum=1&pageSize=10
+func (h *LoginLogHandler) UserLogins(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		response.Fail(c, "userId 必须是整数")
+		return
+	}
+	var pageReq request.PageRequest
+	if err := c.ShouldBindQuery(&pageReq); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	logs, total, err := h.loginLogService.ListByUser(c, userID, pageReq)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"list": logs, "total": total})
+}
+
+// MyLogins GET /api/me/logins?pageNum=1&pageSize=10
+func (h *LoginLogHandler) MyLogins(c *gin.Context) {
+	var pageReq request.PageRequest
+	if err := c.ShouldBindQuery(&pageReq); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	logs, total, err := h.loginLogService.ListMine(c, pageReq)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"list": logs, "total": total})
+}
@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bryantaolong/system/internal/model/request"
+)
+
+// TestMain 与 main 一样在 gin 的校验器上注册自定义规则
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	request.RegisterBindingValidators()
+	os.Exit(m.Run())
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// MeHandler 当前登录用户的自助接口，用户一律取自 Token，不接受路径中的用户 ID
+type MeHandler struct {
+	userService    *service.UserService
+	profileService *service.UserProfileService
+	authService    *service.AuthService
+}
+
+func NewMeHandler(userService *service.UserService, profileService *service.UserProfileService, authService *service.AuthService) *MeHandler {
+	return &MeHandler{userService: userService, profileService: profileService, authService: authService}
+}
+
+// UpdateProfile PUT /api/me 修改自己的手机号、邮箱
+func (h *MeHandler) UpdateProfile(c *gin.Context) {
+	var req request.ProfileUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	user, err := h.userService.UpdateOwnProfile(c, req)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, user)
+}
+
+// GetProfile GET /api/me/profile 查看自己的资料
+func (h *MeHandler) GetProfile(c *gin.Context) {
+	profile, err := h.profileService.GetOwnProfile(c)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, profile)
+}
+
+// SaveProfile PUT /api/me/profile 保存自己的姓名、性别、生日、头像
+func (h *MeHandler) SaveProfile(c *gin.Context) {
+	var req request.UserProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	profile, err := h.profileService.SaveOwnProfile(c, req)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, profile)
+}
+
+// UploadAvatar POST /api/me/avatar 上传自己的头像（multipart 表单字段 avatar）
+func (h *MeHandler) UploadAvatar(c *gin.Context) {
+	data, err := readUpload(c, "avatar", h.profileService.AvatarMaxSize())
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	profile, err := h.profileService.UploadOwnAvatar(c, data)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, profile)
+}
+
+// RemoveAvatar DELETE /api/me/avatar 删除自己上传的头像
+func (h *MeHandler) RemoveAvatar(c *gin.Context) {
+	profile, err := h.profileService.RemoveOwnAvatar(c)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, profile)
+}
+
+// ChangePassword PUT /api/me/password 修改自己的密码，成功后返回新的 Token
+func (h *MeHandler) ChangePassword(c *gin.Context) {
+	var req request.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	user, token, err := h.userService.ChangeOwnPassword(c, req)
+	if err != nil {
+		failWithViolations(c, err)
+		return
+	}
+	response.Success(c, gin.H{"user": user, "token": token})
+}
+
+// Sessions GET /api/me/sessions 查看自己当前有效的会话
+func (h *MeHandler) Sessions(c *gin.Context) {
+	sessions, err := h.authService.ListSessions(c)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, sessions)
+}
+
+// DeleteAccount DELETE /api/me 注销自己的账号
+func (h *MeHandler) DeleteAccount(c *gin.Context) {
+	user, err := h.userService.DeleteOwnAccount(c)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, user)
+}
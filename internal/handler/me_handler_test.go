@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+)
+
+// 校验通过的请求交给 UpdateOwnProfile，未登录时在访问数据库前返回“未登录”，
+// 因此不需要数据库即可区分校验失败与校验通过
+func TestMeHandlerUpdateProfileBinding(t *testing.T) {
+	h := NewMeHandler(service.NewUserService(nil, nil, nil, nil), nil, nil)
+	r := gin.New()
+	r.PUT("/api/me", h.UpdateProfile)
+
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"不修改手机号", `{"email":"alice@example.com"}`, "未登录"},
+		{"合法手机号", `{"phone":"13800138000"}`, "未登录"},
+		{"空请求体", `{}`, "未登录"},
+		{"手机号格式错误", `{"phone":"12345"}`, "phone"},
+		{"邮箱格式错误", `{"email":"alice"}`, "email"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/me", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			var res response.Result
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatalf("响应不是 JSON: %s", w.Body.String())
+			}
+			if w.Code != http.StatusBadRequest || !strings.Contains(res.Message, tt.message) {
+				t.Errorf("响应 = %d %q, 期望 400 且包含 %q", w.Code, res.Message, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	orgService *service.OrganizationService
+}
+
+func NewOrganizationHandler(orgService *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{orgService: orgService}
+}
+
+// Create POST /api/org
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	var req request.OrganizationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	org, err := h.orgService.Create(c, req)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, org)
+}
+
+// List GET /api/org
+func (h *OrganizationHandler) List(c *gin.Context) {
+	var pageReq request.PageRequest
+	if err := c.ShouldBindQuery(&pageReq); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	orgs, total, err := h.orgService.List(c, pageReq)
+	if err != nil {
+		response.InternalError(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"list": orgs, "total": total})
+}
+
+// Get GET /api/org/:orgId
+func (h *OrganizationHandler) Get(c *gin.Context) {
+	orgID, _ := strconv.ParseInt(c.Param("orgId"), 10, 64)
+	org, err := h.orgService.GetByID(c, orgID)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, org)
+}
+
+// Update PUT /api/org/:orgId
+func (h *OrganizationHandler) Update(c *gin.Context) {
+	orgID, _ := strconv.ParseInt(c.Param("orgId"), 10, 64)
+	var req request.OrganizationUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	org, err := h.orgService.Update(c, orgID, req)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, org)
+}
+
+// AssignUser PUT /api/org/:orgId/users/:userId
+func (h *OrganizationHandler) AssignUser(c *gin.Context) {
+	orgID, err := strconv.ParseInt(c.Param("orgId"), 10, 64)
+	if err != nil {
+		response.Fail(c, "orgId 必须是整数")
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		response.Fail(c, "userId 必须是整数")
+		return
+	}
+	user, err := h.orgService.AssignUser(c, orgID, userID)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, user)
+}
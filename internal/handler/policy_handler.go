@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PolicyHandler struct {
+	policyService *service.PolicyService
+}
+
+func NewPolicyHandler(policyService *service.PolicyService) *PolicyHandler {
+	return &PolicyHandler{policyService: policyService}
+}
+
+// ListRules GET /api/policy/rules
+func (h *PolicyHandler) ListRules(c *gin.Context) {
+	response.Success(c, h.policyService.Rules())
+}
+
+// Evaluate POST /api/policy/evaluate
+func (h *PolicyHandler) Evaluate(c *gin.Context) {
+	var req request.PolicyEvaluateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	decision, attrs, err := h.policyService.DryRun(c, req)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"decision": decision, "attributes": attrs})
+}
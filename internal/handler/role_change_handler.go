@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleChangeHandler struct {
+	roleChangeService *service.RoleChangeService
+}
+
+func NewRoleChangeHandler(roleChangeService *service.RoleChangeService) *RoleChangeHandler {
+	return &RoleChangeHandler{roleChangeService: roleChangeService}
+}
+
+// ListRequests GET /api/user/role/requests?status=0
+func (h *RoleChangeHandler) ListRequests(c *gin.Context) {
+	var pageReq request.PageRequest
+	if err := c.ShouldBindQuery(&pageReq); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	var status *int
+	if s := c.Query("status"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			response.Fail(c, "status 必须是整数")
+			return
+		}
+		status = &v
+	}
+	list, total, err := h.roleChangeService.ListRequests(c, status, pageReq)
+	if err != nil {
+		response.InternalError(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"list": list, "total": total})
+}
+
+// Approve PUT /api/user/role/requests/:requestId/approve
+func (h *RoleChangeHandler) Approve(c *gin.Context) {
+	requestID, err := strconv.ParseInt(c.Param("requestId"), 10, 64)
+	if err != nil {
+		response.Fail(c, "requestId 必须是整数")
+		return
+	}
+	var req request.ReviewRoleChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	changeReq, err := h.roleChangeService.Approve(c, requestID, req)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, changeReq)
+}
+
+// Reject PUT /api/user/role/requests/:requestId/reject
+func (h *RoleChangeHandler) Reject(c *gin.Context) {
+	requestID, err := strconv.ParseInt(c.Param("requestId"), 10, 64)
+	if err != nil {
+		response.Fail(c, "requestId 必须是整数")
+		return
+	}
+	var req request.ReviewRoleChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	changeReq, err := h.roleChangeService.Reject(c, requestID, req)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, changeReq)
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readUpload 读取 multipart 表单中的单个文件，超过 maxSize 字节时返回错误
+func readUpload(c *gin.Context, field string, maxSize int64) ([]byte, error) {
+	// 请求体留出表单字段等开销的余量，超出后直接中断读取
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+64<<10)
+	header, err := c.FormFile(field)
+	if err != nil {
+		return nil, fmt.Errorf("请上传文件（表单字段 %s），大小不能超过 %d KB", field, maxSize/1024)
+	}
+	if header.Size > maxSize {
+		return nil, fmt.Errorf("文件大小不能超过 %d KB", maxSize/1024)
+	}
+	f, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("文件大小不能超过 %d KB", maxSize/1024)
+	}
+	return data, nil
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type UserBulkHandler struct {
+	bulkService *service.UserBulkService
+}
+
+func NewUserBulkHandler(bulkService *service.UserBulkService) *UserBulkHandler {
+	return &UserBulkHandler{bulkService: bulkService}
+}
+
+// Handle 返回批量操作的处理函数，对应 POST /api/user/bulk/<action>[?preview=true]。
+// 请求体指定 userIds 或 filter（与 SearchUsers 相同），preview 为 true 时只返回受影响的用户数和示例
+func (h *UserBulkHandler) Handle(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req request.BulkUserRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Fail(c, err.Error())
+			return
+		}
+		if c.Query("preview") == "true" {
+			preview, err := h.bulkService.Preview(c, action, req)
+			if err != nil {
+				failBulk(c, err)
+				return
+			}
+			response.Success(c, preview)
+			return
+		}
+		summary, err := h.bulkService.Execute(c, action, req)
+		if err != nil {
+			failBulk(c, err)
+			return
+		}
+		response.Success(c, summary)
+	}
+}
+
+// failBulk 鉴权失败返回 403，其余错误返回 400
+func failBulk(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrForbidden) {
+		response.Forbidden(c, err.Error())
+		return
+	}
+	response.Fail(c, err.Error())
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/bryantaolong/system/pkg/jwt"
+)
+
+// 非管理员不能预览或执行批量操作，鉴权在查询用户之前完成，因此不需要数据库
+func TestUserBulkHandlerRequiresPolicy(t *testing.T) {
+	policyService, err := service.NewPolicyService(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewUserBulkHandler(service.NewUserBulkService(nil, nil, nil, policyService, nil, nil))
+
+	tests := []struct {
+		name   string
+		roles  []string
+		action string
+		query  string
+		body   string
+	}{
+		{"普通用户预览恢复", []string{"ROLE_USER"}, service.BulkRestore, "?preview=true", `{"filter":{}}`},
+		{"普通用户按 ID 预览", []string{"ROLE_USER"}, service.BulkBlock, "?preview=true", `{"userIds":[1,2]}`},
+		{"普通用户执行删除", []string{"ROLE_USER"}, service.BulkDelete, "", `{"userIds":[1]}`},
+		{"未登录预览", nil, service.BulkLogout, "?preview=true", `{"userIds":[1]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.POST("/api/user/bulk/:action", func(c *gin.Context) {
+				if tt.roles != nil {
+					c.Set(jwt.ContextKey, &jwt.CustomClaims{UserId: "2", Username: "bob", Roles: tt.roles, TenantId: 1})
+				}
+				c.Next()
+			}, h.Handle(tt.action))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/bulk/"+tt.action+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("状态码 = %d, 期望 403，响应 %s", w.Code, w.Body.String())
+			}
+			if strings.Contains(w.Body.String(), "sample") {
+				t.Errorf("拒绝时不应返回预览数据: %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type UserGroupHandler struct {
+	groupService *service.UserGroupService
+}
+
+func NewUserGroupHandler(groupService *service.UserGroupService) *UserGroupHandler {
+	return &UserGroupHandler{groupService: groupService}
+}
+
+// Create POST /api/group
+func (h *UserGroupHandler) Create(c *gin.Context) {
+	var req request.UserGroupCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	group, err := h.groupService.Create(c, req)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, group)
+}
+
+// List GET /api/group
+func (h *UserGroupHandler) List(c *gin.Context) {
+	var pageReq request.PageRequest
+	if err := c.ShouldBindQuery(&pageReq); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	groups, total, err := h.groupService.List(c, pageReq)
+	if err != nil {
+		response.InternalError(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"list": groups, "total": total})
+}
+
+// Get GET /api/group/:groupId
+func (h *UserGroupHandler) Get(c *gin.Context) {
+	groupID, _ := strconv.ParseInt(c.Param("groupId"), 10, 64)
+	group, err := h.groupService.GetByID(c, groupID)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, group)
+}
+
+// Update PUT /api/group/:groupId
+func (h *UserGroupHandler) Update(c *gin.Context) {
+	groupID, _ := strconv.ParseInt(c.Param("groupId"), 10, 64)
+	var req request.UserGroupUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	group, err := h.groupService.Update(c, groupID, req)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, group)
+}
+
+// Delete DELETE /api/group/:groupId
+func (h *UserGroupHandler) Delete(c *gin.Context) {
+	groupID, _ := strconv.ParseInt(c.Param("groupId"), 10, 64)
+	if err := h.groupService.Delete(c, groupID); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"success": true})
+}
+
+// ListMembers GET /api/group/:groupId/members
+func (h *UserGroupHandler) ListMembers(c *gin.Context) {
+	groupID, _ := strconv.ParseInt(c.Param("groupId"), 10, 64)
+	var pageReq request.PageRequest
+	if err := c.ShouldBindQuery(&pageReq); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	users, total, err := h.groupService.ListMembers(c, groupID, pageReq)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"list": users, "total": total})
+}
+
+// AddMembers POST /api/group/:groupId/members
+func (h *UserGroupHandler) AddMembers(c *gin.Context) {
+	groupID, _ := strconv.ParseInt(c.Param("groupId"), 10, 64)
+	var req request.UserGroupMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	if err := h.groupService.AddMembers(c, groupID, req.UserIds); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"success": true})
+}
+
+// RemoveMember DELETE /api/group/:groupId/members/:userId
+func (h *UserGroupHandler) RemoveMember(c *gin.Context) {
+	groupID, _ := strconv.ParseInt(c.Param("groupId"), 10, 64)
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		response.Fail(c, "userId 必须是整数")
+		return
+	}
+	if err := h.groupService.RemoveMember(c, groupID, userID); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"success": true})
+}
+
+// ListRoles GET /api/group/:groupId/roles
+func (h *UserGroupHandler) ListRoles(c *gin.Context) {
+	groupID, _ := strconv.ParseInt(c.Param("groupId"), 10, 64)
+	roles, err := h.groupService.ListRoles(c, groupID)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, roles)
+}
+
+// SetRoles PUT /api/group/:groupId/roles
+func (h *UserGroupHandler) SetRoles(c *gin.Context) {
+	groupID, _ := strconv.ParseInt(c.Param("groupId"), 10, 64)
+	var req request.ChangeRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	roles, err := h.groupService.SetRoles(c, groupID, req.RoleIds)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, roles)
+}
+
+// UserGroups GET /api/user/:userId/groups
+func (h *UserGroupHandler) UserGroups(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		response.Fail(c, "userId 必须是整数")
+		return
+	}
+	groups, roles, err := h.groupService.UserGroups(c, userID)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"groups": groups, "effectiveRoles": roles})
+}
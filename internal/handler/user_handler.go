@@ -2,7 +2,9 @@ package handler
 
 import (
 	"strconv"
+	"strings"
 
+	"github.com/bryantaolong/system/internal/model/entity"
 	"github.com/bryantaolong/system/internal/model/request"
 	"github.com/bryantaolong/system/internal/model/response"
 	"github.com/bryantaolong/system/internal/service"
@@ -10,14 +12,45 @@ import (
 )
 
 type UserHandler struct {
-	userService *service.UserService
+	userService       *service.UserService
+	profileService    *service.UserProfileService
+	roleChangeService *service.RoleChangeService
+	policyService     *service.PolicyService
+	purgeService      *service.UserPurgeService
 }
 
-func NewUserHandler(userService *service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService *service.UserService, profileService *service.UserProfileService, roleChangeService *service.RoleChangeService, policyService *service.PolicyService, purgeService *service.UserPurgeService) *UserHandler {
+	return &UserHandler{userService: userService, profileService: profileService, roleChangeService: roleChangeService, policyService: policyService, purgeService: purgeService}
+}
+
+// authorize 按策略判定当前用户能否对目标用户执行操作，拒绝时直接写入响应并返回 false
+func (h *UserHandler) authorize(c *gin.Context, action string, target *entity.User) bool {
+	decision, err := h.policyService.Authorize(c, action, target)
+	if err != nil {
+		response.InternalError(c, err.Error())
+		return false
+	}
+	if !decision.Allowed {
+		response.Forbidden(c, "权限不足")
+		return false
+	}
+	return true
+}
+
+// authorizeUserID 加载目标用户后按策略鉴权
+func (h *UserHandler) authorizeUserID(c *gin.Context, action string, userID int64) bool {
+	target, err := h.userService.GetUserByID(c, userID)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return false
+	}
+	return h.authorize(c, action, target)
 }
 
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
+	if !h.authorize(c, service.ActionUserList, nil) {
+		return
+	}
 	var pageReq request.PageRequest
 	if err := c.ShouldBindQuery(&pageReq); err != nil {
 		response.Fail(c, err.Error())
@@ -39,9 +72,87 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 		response.Fail(c, err.Error())
 		return
 	}
+	if !h.authorize(c, service.ActionUserRead, user) {
+		return
+	}
+	if included(c, "profile") {
+		detail, err := h.profileService.WithProfile(c, user)
+		if err != nil {
+			response.InternalError(c, err.Error())
+			return
+		}
+		response.Success(c, detail)
+		return
+	}
 	response.Success(c, user)
 }
 
+// GetProfile GET /api/user/:userId/profile
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	userID, _ := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if !h.authorizeUserID(c, service.ActionUserRead, userID) {
+		return
+	}
+	profile, err := h.profileService.GetProfile(c, userID)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, profile)
+}
+
+// SaveProfile PUT /api/user/:userId/profile 保存用户资料，不存在时创建
+func (h *UserHandler) SaveProfile(c *gin.Context) {
+	userID, _ := strconv.ParseInt(c.Param("userId"), 10, 64)
+	var req request.UserProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	if !h.authorizeUserID(c, service.ActionUserUpdate, userID) {
+		return
+	}
+	profile, err := h.profileService.SaveProfile(c, userID, req)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, profile)
+}
+
+// UploadAvatar POST /api/user/:userId/avatar 为用户上传头像（multipart 表单字段 avatar）
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	userID, _ := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if !h.authorizeUserID(c, service.ActionUserUpdate, userID) {
+		return
+	}
+	data, err := readUpload(c, "avatar", h.profileService.AvatarMaxSize())
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	profile, err := h.profileService.UploadAvatar(c, userID, data)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, profile)
+}
+
+// RemoveAvatar DELETE /api/user/:userId/avatar 删除用户上传的头像
+func (h *UserHandler) RemoveAvatar(c *gin.Context) {
+	userID, _ := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if !h.authorizeUserID(c, service.ActionUserUpdate, userID) {
+		return
+	}
+	profile, err := h.profileService.RemoveAvatar(c, userID)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, profile)
+}
+
 func (h *UserHandler) GetUserByUsername(c *gin.Context) {
 	username := c.Param("username")
 	user, err := h.userService.GetUserByUsername(c, username)
@@ -49,10 +160,16 @@ func (h *UserHandler) GetUserByUsername(c *gin.Context) {
 		response.Fail(c, err.Error())
 		return
 	}
+	if !h.authorize(c, service.ActionUserRead, user) {
+		return
+	}
 	response.Success(c, user)
 }
 
 func (h *UserHandler) SearchUsers(c *gin.Context) {
+	if !h.authorize(c, service.ActionUserList, nil) {
+		return
+	}
 	var searchReq request.UserSearchRequest
 	var pageReq request.PageRequest
 	if err := c.ShouldBindJSON(&searchReq); err != nil {
@@ -78,9 +195,12 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		response.Fail(c, err.Error())
 		return
 	}
+	if !h.authorizeUserID(c, service.ActionUserUpdate, userID) {
+		return
+	}
 	user, err := h.userService.UpdateUser(c, userID, req)
 	if err != nil {
-		response.Fail(c, err.Error())
+		failWithViolations(c, err)
 		return
 	}
 	response.Success(c, user)
@@ -98,12 +218,20 @@ func (h *UserHandler) ChangeRole(c *gin.Context) {
 		response.Fail(c, err.Error())
 		return
 	}
+	if !h.authorizeUserID(c, service.ActionUserRole, userID) {
+		return
+	}
 
-	user, err := h.userService.ChangeRoleByIds(c.Request.Context(), userID, req)
+	user, changeReq, err := h.roleChangeService.ChangeRole(c, userID, req)
 	if err != nil {
 		response.Fail(c, err.Error())
 		return
 	}
+	if changeReq != nil {
+		// 涉及敏感角色，需等待第二位管理员审批
+		response.Success(c, gin.H{"pending": true, "request": changeReq})
+		return
+	}
 	response.Success(c, user)
 }
 
@@ -114,9 +242,12 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 		response.Fail(c, err.Error())
 		return
 	}
+	if !h.authorizeUserID(c, service.ActionUserPassword, userID) {
+		return
+	}
 	user, err := h.userService.ChangePassword(c, userID, req)
 	if err != nil {
-		response.Fail(c, err.Error())
+		failWithViolations(c, err)
 		return
 	}
 	response.Success(c, user)
@@ -125,9 +256,12 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 func (h *UserHandler) ChangePasswordForcefully(c *gin.Context) {
 	userID, _ := strconv.ParseInt(c.Param("userId"), 10, 64)
 	newPassword := c.Param("newPassword")
+	if !h.authorizeUserID(c, service.ActionUserPassword, userID) {
+		return
+	}
 	user, err := h.userService.ChangePasswordForcefully(c, userID, newPassword)
 	if err != nil {
-		response.Fail(c, err.Error())
+		failWithViolations(c, err)
 		return
 	}
 	response.Success(c, user)
@@ -135,6 +269,9 @@ func (h *UserHandler) ChangePasswordForcefully(c *gin.Context) {
 
 func (h *UserHandler) BlockUser(c *gin.Context) {
 	userID, _ := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if !h.authorizeUserID(c, service.ActionUserBlock, userID) {
+		return
+	}
 	user, err := h.userService.BlockUser(c, userID)
 	if err != nil {
 		response.Fail(c, err.Error())
@@ -143,8 +280,24 @@ func (h *UserHandler) BlockUser(c *gin.Context) {
 	response.Success(c, user)
 }
 
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	userID, _ := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if !h.authorizeUserID(c, service.ActionUserUnlock, userID) {
+		return
+	}
+	user, err := h.userService.UnlockUser(c, userID)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, user)
+}
+
 func (h *UserHandler) UnblockUser(c *gin.Context) {
 	userID, _ := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if !h.authorizeUserID(c, service.ActionUserUnblock, userID) {
+		return
+	}
 	user, err := h.userService.UnblockUser(c, userID)
 	if err != nil {
 		response.Fail(c, err.Error())
@@ -155,6 +308,9 @@ func (h *UserHandler) UnblockUser(c *gin.Context) {
 
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	userID, _ := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if !h.authorizeUserID(c, service.ActionUserDelete, userID) {
+		return
+	}
 	user, err := h.userService.DeleteUser(c, userID)
 	if err != nil {
 		response.Fail(c, err.Error())
@@ -162,3 +318,13 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	}
 	response.Success(c, user)
 }
+
+// included 判断查询参数 include（逗号分隔）是否包含指定的关联数据
+func included(c *gin.Context, name string) bool {
+	for _, v := range strings.Split(c.Query("include"), ",") {
+		if strings.TrimSpace(v) == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type UserImportHandler struct {
+	importService *service.UserImportService
+}
+
+func NewUserImportHandler(importService *service.UserImportService) *UserImportHandler {
+	return &UserImportHandler{importService: importService}
+}
+
+// Import POST /api/user/import?dryRun=true，multipart 字段 file 为 CSV 或 XLSX 文件。
+// 文件格式和表头在请求中检查，逐行导入在后台执行，返回的任务通过 Job 查询进度
+func (h *UserImportHandler) Import(c *gin.Context) {
+	data, err := readUpload(c, "file", h.importService.MaxSize())
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	table, err := h.importService.ParseImportFile(data)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	job, err := h.importService.Start(c, table, c.Query("dryRun") == "true")
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, job)
+}
+
+// Job GET /api/user/import/:jobId 查询导入进度，完成后有失败行时返回错误报告的下载地址
+func (h *UserImportHandler) Job(c *gin.Context) {
+	job, err := h.importService.GetJob(c, c.Param("jobId"))
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, job)
+}
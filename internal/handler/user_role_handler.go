@@ -16,7 +16,7 @@ func NewUserRoleHandler(userRoleSvc *service.UserRoleService) *UserRoleHandler {
 
 // ListRoles  GET /api/user/role/all
 func (h *UserRoleHandler) ListRoles(c *gin.Context) {
-	list, err := h.userRoleSvc.ListAll(c.Request.Context())
+	list, err := h.userRoleSvc.ListAll(c)
 	if err != nil {
 		response.Fail(c, err.Error())
 		return
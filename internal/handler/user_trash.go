@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/internal/model/response"
+	"github.com/bryantaolong/system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// trashUser 回收站中的用户，附带计划彻底清除的时间
+type trashUser struct {
+	entity.User
+	PurgeAt *time.Time `json:"purgeAt,omitempty"` // 未配置保留期时为空
+}
+
+// ListTrash POST /api/user/trash，请求体与 SearchUsers 相同，分页返回已删除的用户
+func (h *UserHandler) ListTrash(c *gin.Context) {
+	if !h.authorize(c, service.ActionUserTrash, nil) {
+		return
+	}
+	var searchReq request.UserSearchRequest
+	var pageReq request.PageRequest
+	if err := c.ShouldBindJSON(&searchReq); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	if err := c.ShouldBindQuery(&pageReq); err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	users, total, err := h.userService.ListDeletedUsers(c, searchReq, pageReq)
+	if err != nil {
+		response.InternalError(c, err.Error())
+		return
+	}
+	list := make([]trashUser, len(users))
+	for i := range users {
+		list[i].User = users[i]
+		if at := h.purgeService.PurgeAt(&users[i]); !at.IsZero() {
+			list[i].PurgeAt = &at
+		}
+	}
+	response.Success(c, gin.H{"list": list, "total": total})
+}
+
+// RestoreUser PUT /api/user/:userId/restore 恢复已删除的用户
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		response.Fail(c, "userId 必须是整数")
+		return
+	}
+	target, err := h.userService.GetDeletedUserByID(c, userID)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	if !h.authorize(c, service.ActionUserRestore, target) {
+		return
+	}
+	user, err := h.userService.RestoreUser(c, userID)
+	if err != nil {
+		response.Fail(c, err.Error())
+		return
+	}
+	response.Success(c, user)
+}
@@ -4,14 +4,25 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/bryantaolong/system/pkg/jwt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 )
 
-// AuthRequired 验证请求头中的 JWT 并与 Redis 中的 token 做一致性校验。
+// AuthRequired 验证请求头中的 JWT 并与 Redis 中的 token 做一致性校验，拒绝受限 Token。
 func AuthRequired(rdb *redis.Client) gin.HandlerFunc {
+	return authenticate(rdb, "")
+}
+
+// PasswordChangeAuth 与 AuthRequired 相同，但同时接受需要修改密码时签发的受限 Token，只用于修改密码接口。
+func PasswordChangeAuth(rdb *redis.Client) gin.HandlerFunc {
+	return authenticate(rdb, jwt.ScopePasswordChange)
+}
+
+// authenticate 校验 Token，allowScope 为允许的受限 Token 范围，为空时只接受普通 Token
+func authenticate(rdb *redis.Client, allowScope string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenStr, err := jwt.GetTokenFromRequest(c)
 		if err != nil {
@@ -24,22 +35,29 @@ func AuthRequired(rdb *redis.Client) gin.HandlerFunc {
 		}
 
 		claims, _ := jwt.ParseToken(tokenStr)
-		redisToken, _ := rdb.Get(context.Background(), claims.Username).Result()
+		key := jwt.SessionKey(claims)
+		redisToken, _ := rdb.Get(context.Background(), key).Result()
 		if redisToken != tokenStr {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "Token已失效"})
 			return
 		}
+		if claims.IsRestricted() && claims.Scope != allowScope {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": 403, "message": "请先修改密码", "passwordChangeRequired": true})
+			return
+		}
 
-		// 可选：刷新 TTL
-		_ = rdb.Expire(context.Background(), claims.Username, jwt.Expiration)
+		// 可选：刷新 TTL（模拟登录与受限 Token 有效期固定，不续期）
+		if !claims.IsImpersonation() && !claims.IsRestricted() {
+			_ = rdb.Expire(context.Background(), key, jwt.Expiration)
+		}
 
 		c.Set(jwt.ContextKey, claims)
 		c.Next()
 	}
 }
 
-// RoleRequired 要求当前用户必须具备指定角色。
-func RoleRequired(requiredRole string) gin.HandlerFunc {
+// RoleRequired 要求当前用户至少具备指定角色之一。
+func RoleRequired(requiredRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		claims, exists := c.Get(jwt.ContextKey)
 		if !exists {
@@ -48,11 +66,60 @@ func RoleRequired(requiredRole string) gin.HandlerFunc {
 		}
 		cc := claims.(*jwt.CustomClaims)
 		for _, r := range cc.Roles {
-			if r == requiredRole || r == jwt.RolePrefix+requiredRole {
-				c.Next()
-				return
+			for _, requiredRole := range requiredRoles {
+				if r == requiredRole || r == jwt.RolePrefix+requiredRole {
+					c.Next()
+					return
+				}
 			}
 		}
 		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": 403, "message": "权限不足"})
 	}
 }
+
+// RecentAuthRequired 要求最近 maxAge 内完成过身份认证，否则需先调用 /api/auth/reauth 重新验证。
+func RecentAuthRequired(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v, exists := c.Get(jwt.ContextKey)
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "未提供Token"})
+			return
+		}
+		cc := v.(*jwt.CustomClaims)
+		if age, ok := cc.AuthAge(); !ok || age > maxAge {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "该操作需要重新验证身份", "reauthRequired": true})
+			return
+		}
+		c.Next()
+	}
+}
+
+// NoImpersonation 禁止模拟登录 Token 执行敏感操作（如修改密码、变更角色）。
+func NoImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if v, exists := c.Get(jwt.ContextKey); exists {
+			if cc, ok := v.(*jwt.CustomClaims); ok && cc.IsImpersonation() {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": 403, "message": "模拟登录状态下不允许执行此操作"})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// ImpersonationRecorder 记录模拟登录期间发起的请求
+type ImpersonationRecorder interface {
+	RecordImpersonatedRequest(c *gin.Context, claims *jwt.CustomClaims)
+}
+
+// ImpersonationAudit 在请求完成后记录所有使用模拟登录 Token 发起的请求。
+func ImpersonationAudit(recorder ImpersonationRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if v, exists := c.Get(jwt.ContextKey); exists {
+			if cc, ok := v.(*jwt.CustomClaims); ok && cc.IsImpersonation() {
+				recorder.RecordImpersonatedRequest(c, cc)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	http2 "github.com/bryantaolong/system/pkg/http"
+	"github.com/bryantaolong/system/pkg/jwt"
+	"github.com/bryantaolong/system/pkg/ratelimit"
+)
+
+// maxRateLimitBodySize 按用户名限流时最多读取的请求体大小
+const maxRateLimitBodySize = 64 << 10
+
+// maxRateLimitKeyLength 限流维度取值的最大长度，防止超长取值占用存储
+const maxRateLimitKeyLength = 64
+
+// RateLimitKeyFunc 从请求中提取限流维度的取值，返回空字符串表示该请求不参与此规则
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitRule 限流规则：同一路由下按 Key 的取值分别计数
+type RateLimitRule struct {
+	Name string // 维度名称，如 ip、username
+	Rate ratelimit.Rate
+	Key  RateLimitKeyFunc
+}
+
+// ByIP 按客户端 IP 限流，只在请求经受信任代理转发时采信 X-Forwarded-For
+func ByIP(c *gin.Context) string {
+	return http2.GetClientIP(c.Request)
+}
+
+// ByBodyUsernameAndIP 按请求体中的 username 与客户端 IP 的组合限流。
+// 只按用户名计数时，任何人都能用他人的用户名耗尽额度，使其无法登录
+func ByBodyUsernameAndIP(c *gin.Context) string {
+	username := ByBodyUsername(c)
+	if username == "" {
+		return ""
+	}
+	return username + "@" + ByIP(c)
+}
+
+// ByBodyUsername 按请求体 JSON 中的 username 限流，读取后恢复请求体供后续绑定使用
+func ByBodyUsername(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRateLimitBodySize))
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+	var payload struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	if len(payload.Username) > maxRateLimitKeyLength {
+		return payload.Username[:maxRateLimitKeyLength]
+	}
+	return payload.Username
+}
+
+// ByCurrentUser 按当前登录用户限流，需在 AuthRequired 之后使用
+func ByCurrentUser(c *gin.Context) string {
+	if v, exists := c.Get(jwt.ContextKey); exists {
+		if cc, ok := v.(*jwt.CustomClaims); ok {
+			return cc.UserId
+		}
+	}
+	return ""
+}
+
+// RateLimit 依次检查各规则，任一规则超限即返回 429 并通过 Retry-After 告知等待秒数。
+// 限流后端故障时放行请求，避免限流组件拖垮认证接口。
+func RateLimit(limiter ratelimit.Limiter, rules ...RateLimitRule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, rule := range rules {
+			if !rule.Rate.Enabled() {
+				continue
+			}
+			value := rule.Key(c)
+			if value == "" {
+				continue
+			}
+			key := c.FullPath() + ":" + rule.Name + ":" + value
+			result, err := limiter.Allow(c, key, rule.Rate)
+			if err != nil {
+				log.Printf("限流检查失败: %v", err)
+				continue
+			}
+			if !result.Allowed {
+				retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+				if retryAfter < 1 {
+					retryAfter = 1
+				}
+				c.Header("Retry-After", strconv.Itoa(retryAfter))
+				c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+				c.Header("X-RateLimit-Remaining", "0")
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"code": 429, "message": "请求过于频繁，请稍后再试"})
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bryantaolong/system/pkg/ratelimit"
+)
+
+func TestRateLimitByBodyUsernameAndIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/login", RateLimit(ratelimit.NewMemoryLimiter(),
+		RateLimitRule{Name: "username", Rate: ratelimit.Rate{Limit: 1, Window: time.Minute}, Key: ByBodyUsernameAndIP},
+	), func(c *gin.Context) {
+		// 限流读取请求体后，后续处理仍能完整绑定
+		var req struct {
+			Username string `json:"username"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, req.Username)
+	})
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		body   string
+		status int
+	}{
+		{"首次请求放行", "203.0.113.7:5000", "", `{"username":"alice","password":"x"}`, http.StatusOK},
+		{"同一用户名与 IP 超限", "203.0.113.7:5001", "", `{"username":"alice","password":"y"}`, http.StatusTooManyRequests},
+		{"其他 IP 使用同一用户名不受影响", "198.51.100.9:5000", "", `{"username":"alice","password":"z"}`, http.StatusOK},
+		{"同一 IP 使用其他用户名不受影响", "203.0.113.7:5002", "", `{"username":"bob","password":"x"}`, http.StatusOK},
+		{"伪造转发头无法绕过", "203.0.113.7:5003", "192.0.2.1", `{"username":"alice","password":"x"}`, http.StatusTooManyRequests},
+		{"请求体不含用户名时不参与此规则", "203.0.113.7:5004", "", `{"password":"x"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(tt.body))
+			req.RemoteAddr = tt.remote
+			req.Header.Set("Content-Type", "application/json")
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("状态码 = %d, 期望 %d，响应 %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+				t.Error("超限响应缺少 Retry-After")
+			}
+		})
+	}
+}
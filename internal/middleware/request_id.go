@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+
+	http2 "github.com/bryantaolong/system/pkg/http"
+)
+
+// maxRequestIDLength 客户端传入请求ID的最大长度，超出时重新生成
+const maxRequestIDLength = 64
+
+// RequestID 为每个请求分配请求ID：沿用客户端传入的 X-Request-ID，否则随机生成，
+// 并写回请求头与响应头，便于日志与审计事件关联。
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := http2.GetRequestID(c.Request)
+		if id == "" || len(id) > maxRequestIDLength {
+			buf := make([]byte, 16)
+			_, _ = rand.Read(buf)
+			id = hex.EncodeToString(buf)
+			c.Request.Header.Set(http2.RequestIDHeader, id)
+		}
+		c.Header(http2.RequestIDHeader, id)
+		c.Next()
+	}
+}
@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+// 审计事件动作
+const (
+	AuditUserUpdate        = "user.update"         // 修改用户信息
+	AuditUserRole          = "user.role"           // 变更用户角色
+	AuditUserPassword      = "user.password"       // 用户修改密码
+	AuditUserPasswordForce = "user.password.force" // 管理员强制修改密码
+	AuditUserBlock         = "user.block"          // 封禁用户
+	AuditUserUnblock       = "user.unblock"        // 解封用户
+	AuditUserUnlock        = "user.unlock"         // 管理员解除登录锁定
+	AuditUserDelete        = "user.delete"         // 删除用户
+	AuditUserRestore       = "user.restore"        // 恢复已删除的用户
+	AuditUserPurge         = "user.purge"          // 超过保留期后彻底清除已删除的用户
+	AuditUserLogout        = "user.logout"         // 管理员强制下线
+	AuditUserProfile       = "user.profile"        // 修改用户资料
+	AuditUserImport        = "user.import"         // 管理员批量导入用户
+	AuditUserExport        = "user.export"         // 导出用户数据
+
+	AuditRegister    = "auth.register"     // 注册
+	AuditLogin       = "auth.login"        // 登录成功
+	AuditLoginFailed = "auth.login.failed" // 登录失败（用户名不存在、密码错误、封禁等）
+	AuditLockout     = "auth.lockout"      // 连续失败导致账号锁定
+	AuditLogout      = "auth.logout"       // 登出
+	AuditReauth      = "auth.reauth"       // 重新验证身份
+
+	AuditSuspiciousLogin = "security.suspicious_login" // 可疑登录（新设备、新网段、不可能的位移）
+	AuditFailureBurst    = "security.failure_burst"    // 同一 IP 短时间内大量账号登录失败
+)
+
+// 审计事件结果
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditEvent 审计事件：记录谁在什么时候从哪里对哪个用户做了什么，以及用户字段的前后变化
+type AuditEvent struct {
+	ID           int64     `json:"id" db:"id"`
+	TenantID     int64     `json:"tenantId" db:"tenant_id"`
+	Action       string    `json:"action" db:"action"`
+	Result       string    `json:"result" db:"result"`             // success 或 failure
+	Reason       string    `json:"reason" db:"reason"`             // 失败原因或补充说明
+	ActorID      int64     `json:"actorId" db:"actor_id"`          // 操作人，登录等事件为用户本人
+	ActorName    string    `json:"actorName" db:"actor_name"`      // 操作人用户名
+	Impersonator string    `json:"impersonator" db:"impersonator"` // 模拟登录时的真实操作人
+	TargetID     int64     `json:"targetId" db:"target_id"`        // 目标用户
+	TargetName   string    `json:"targetName" db:"target_name"`    // 目标用户名
+	Changes      string    `json:"changes" db:"changes"`           // 用户字段变化（JSON，格式为 {"字段": {"before": .., "after": ..}}）
+	IP           string    `json:"ip" db:"ip"`
+	UserAgent    string    `json:"userAgent" db:"user_agent"`
+	RequestID    string    `json:"requestId" db:"request_id"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// TableName 返回表名
+func (AuditEvent) TableName() string {
+	return "audit_event"
+}
@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// 模拟登录审计动作
+const (
+	ImpersonationStart   = "start"   // 签发模拟登录 Token
+	ImpersonationRequest = "request" // 模拟登录期间发起的请求
+)
+
+// ImpersonationLog 模拟登录审计记录：每次签发以及模拟期间的每个请求都会写入
+type ImpersonationLog struct {
+	ID         int64     `json:"id" db:"id"`
+	TenantID   int64     `json:"tenantId" db:"tenant_id"`
+	ActorID    string    `json:"actorId" db:"actor_id"`       // 真实操作人
+	ActorName  string    `json:"actorName" db:"actor_name"`   // 真实操作人用户名
+	TargetID   string    `json:"targetId" db:"target_id"`     // 被模拟用户
+	TargetName string    `json:"targetName" db:"target_name"` // 被模拟用户名
+	TokenID    string    `json:"tokenId" db:"token_id"`       // 模拟登录 Token 的 jti
+	Action     string    `json:"action" db:"action"`          // start 或 request
+	Method     string    `json:"method" db:"method"`
+	Path       string    `json:"path" db:"path"`
+	StatusCode int       `json:"statusCode" db:"status_code"`
+	Reason     string    `json:"reason" db:"reason"` // 发起模拟登录的原因
+	IP         string    `json:"ip" db:"ip"`
+	UserAgent  string    `json:"userAgent" db:"user_agent"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+}
+
+// TableName 返回表名
+func (ImpersonationLog) TableName() string {
+	return "impersonation_log"
+}
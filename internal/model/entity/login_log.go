@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+// 登录结果
+const (
+	LoginSuccess     = "success"      // 登录成功
+	LoginBadPassword = "bad_password" // 密码错误
+	LoginLocked      = "locked"       // 账号已锁定（含本次失败触发锁定）
+	LoginBanned      = "banned"       // 账号已封禁
+	LoginOrgDisabled = "org_disabled" // 所属组织已停用
+	LoginUnknownUser = "unknown_user" // 用户名不存在
+)
+
+// 登录风险标记
+const (
+	RiskNewDevice        = "new_device"        // 从未使用过的设备
+	RiskNewIPRange       = "new_ip_range"      // 从未使用过的 IP 网段
+	RiskImpossibleTravel = "impossible_travel" // 与上次登录地点的距离在间隔时间内无法到达
+	RiskFailureBurst     = "failure_burst"     // 同一 IP 短时间内大量账号登录失败
+)
+
+// LoginLog 登录记录：每次登录尝试无论成功与否都会写入一条
+type LoginLog struct {
+	ID         int64     `json:"id" db:"id"`
+	TenantID   int64     `json:"tenantId" db:"tenant_id"`
+	UserID     int64     `json:"userId" db:"user_id"`    // 用户名不存在时为 0
+	Username   string    `json:"username" db:"username"` // 登录时提交的用户名
+	Result     string    `json:"result" db:"result"`
+	FailReason string    `json:"failReason" db:"fail_reason"`
+	IP         string    `json:"ip" db:"ip"`
+	OS         string    `json:"os" db:"os"`
+	Browser    string    `json:"browser" db:"browser"`
+	UserAgent  string    `json:"userAgent" db:"user_agent"`
+	Device     string    `json:"device" db:"device"`        // 设备指纹（操作系统与浏览器的摘要）
+	Location   string    `json:"location" db:"location"`    // IP 对应的地理位置
+	RiskFlags  string    `json:"riskFlags" db:"risk_flags"` // 命中的风险规则，多个用英文逗号分隔
+	RequestID  string    `json:"requestId" db:"request_id"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+}
+
+// TableName 返回表名
+func (LoginLog) TableName() string {
+	return "login_log"
+}
+
+// IsSuccess 判断是否登录成功
+func (l *LoginLog) IsSuccess() bool {
+	return l.Result == LoginSuccess
+}
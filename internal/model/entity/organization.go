@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DefaultTenantID 默认租户，未指定组织的用户与全局共享的角色均归属于此
+const DefaultTenantID int64 = 0
+
+// Organization 组织（租户）实体结构体
+type Organization struct {
+	ID        int64        `json:"id" db:"id"`
+	Name      string       `json:"name" db:"name"`
+	Code      string       `json:"code" db:"code"`     // 组织编码，注册时用于指定所属组织
+	Status    int          `json:"status" db:"status"` // 状态（0-正常，1-停用）
+	Deleted   int          `json:"-" db:"deleted"`     // 软删除标记不暴露给前端
+	Version   int          `json:"version" db:"version"`
+	CreatedAt time.Time    `json:"createdAt" db:"created_at"`
+	UpdatedAt sql.NullTime `json:"updatedAt" db:"updated_at"`
+	CreatedBy string       `json:"createdBy" db:"created_by"`
+	UpdatedBy string       `json:"updatedBy" db:"updated_by"`
+}
+
+// TableName 返回表名
+func (Organization) TableName() string {
+	return "organization"
+}
+
+// IsEnabled 检查组织是否启用
+func (o *Organization) IsEnabled() bool {
+	return o.Status == 0 && o.Deleted == 0
+}
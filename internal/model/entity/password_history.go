@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// PasswordHistory 用户曾经使用过的密码哈希，用于禁止重复使用最近的密码
+type PasswordHistory struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    int64     `json:"userId" db:"user_id"`
+	Password  string    `json:"-" db:"password"` // 旧密码哈希
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// TableName 返回表名
+func (PasswordHistory) TableName() string {
+	return "password_history"
+}
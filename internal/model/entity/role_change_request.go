@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// 角色变更申请状态
+const (
+	RoleChangePending  = 0 // 待审批
+	RoleChangeApproved = 1 // 已通过
+	RoleChangeRejected = 2 // 已驳回
+)
+
+// RoleChangeRequest 敏感角色变更申请，需由第二位管理员审批后生效
+type RoleChangeRequest struct {
+	ID            int64        `json:"id" db:"id"`
+	TenantID      int64        `json:"tenantId" db:"tenant_id"`            // 目标用户所属租户
+	UserID        int64        `json:"userId" db:"user_id"`                // 目标用户
+	RoleIds       string       `json:"roleIds" db:"role_ids"`              // 申请的角色 ID，多个用英文逗号分隔
+	OldRoles      string       `json:"oldRoles" db:"old_roles"`            // 申请时用户的角色
+	NewRoles      string       `json:"newRoles" db:"new_roles"`            // 申请的角色名
+	Status        int          `json:"status" db:"status"`                 // 状态（0-待审批，1-已通过，2-已驳回）
+	RequestedBy   string       `json:"requestedBy" db:"requested_by"`      // 申请人
+	RequestedByID int64        `json:"requestedById" db:"requested_by_id"` // 申请人 ID，用户名可以修改，审批资格按 ID 判断
+	ReviewedBy    string       `json:"reviewedBy" db:"reviewed_by"`        // 审批人
+	ReviewedAt    sql.NullTime `json:"reviewedAt" db:"reviewed_at"`        // 审批时间
+	ReviewComment string       `json:"reviewComment" db:"review_comment"`  // 审批意见
+	CreatedAt     time.Time    `json:"createdAt" db:"created_at"`
+	UpdatedAt     sql.NullTime `json:"updatedAt" db:"updated_at"`
+}
+
+// TableName 返回表名
+func (RoleChangeRequest) TableName() string {
+	return "role_change_request"
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// SoftDelete 软删除标记（0-未删除，1-已删除）。与 gorm.DeletedAt 类似，
+// 使用该类型的模型在查询时默认追加 deleted = 0 条件，需要查询已删除记录时使用 Unscoped()。
+// 只作用于查询（Find、First、Count 等），更新和删除语句不受影响
+type SoftDelete int
+
+// QueryClauses 实现 gorm.QueryClausesInterface
+func (SoftDelete) QueryClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{softDeleteQueryClause{field: f}}
+}
+
+type softDeleteQueryClause struct {
+	field *schema.Field
+}
+
+func (softDeleteQueryClause) Name() string { return "" }
+
+func (softDeleteQueryClause) Build(clause.Builder) {}
+
+func (softDeleteQueryClause) MergeClause(*clause.Clause) {}
+
+// ModifyStatement 追加 deleted = 0 条件。已有 WHERE 中包含单独的 OR 条件时先整体加括号，
+// 避免 a OR b AND deleted = 0 改变原有语义
+func (sd softDeleteQueryClause) ModifyStatement(stmt *gorm.Statement) {
+	if _, ok := stmt.Clauses["soft_delete_enabled"]; ok || stmt.Statement.Unscoped {
+		return
+	}
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) >= 1 {
+			for _, expr := range where.Exprs {
+				if orCond, ok := expr.(clause.OrConditions); ok && len(orCond.Exprs) == 1 {
+					where.Exprs = []clause.Expression{clause.And(where.Exprs...)}
+					c.Expression = where
+					stmt.Clauses["WHERE"] = c
+					break
+				}
+			}
+		}
+	}
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sd.field.DBName}, Value: 0},
+	}})
+	stmt.Clauses["soft_delete_enabled"] = clause.Clause{}
+}
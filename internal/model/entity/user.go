@@ -5,29 +5,36 @@ import (
 	"strings"
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
+	"github.com/bryantaolong/system/pkg/password"
 )
 
 // User 用户实体结构体
 type User struct {
-	ID              int64        `json:"id" db:"id"`
-	Username        string       `json:"username" db:"username"`
-	Password        string       `json:"-" db:"password"` // 密码不序列化到JSON
-	Phone           string       `json:"phone" db:"phone"`
-	Email           string       `json:"email" db:"email"`
-	Status          int          `json:"status" db:"status"` // 状态（0-正常，1-封禁，2-锁定）
-	Roles           string       `json:"roles" db:"roles"`   // 角色标识，多个用英文逗号分隔
-	LastLoginAt     sql.NullTime `json:"LastLoginAt" db:"last_login_at"`
-	LastLoginIP     string       `json:"loginIp" db:"login_ip"`
-	PasswordResetAt sql.NullTime `json:"passwordResetTime" db:"password_reset_at"`
-	LoginFailCount  int          `json:"loginFailCount" db:"login_fail_count"`
-	LockedAt        sql.NullTime `json:"lockedAt" db:"locked_at"`
-	Deleted         int          `json:"-" db:"deleted"`       // 软删除标记不暴露给前端
-	Version         int          `json:"version" db:"version"` // 乐观锁版本号
-	CreatedAt       time.Time    `json:"createAt" db:"created_at"`
-	UpdatedAt       sql.NullTime `json:"updatedAt" db:"updated_ta"`
-	CreatedBy       string       `json:"createdBy" db:"created_by"`
-	UpdatedBy       string       `json:"updatedBy" db:"updated_by"`
+	ID                 int64        `json:"id" db:"id"`
+	TenantID           int64        `json:"tenantId" db:"tenant_id"` // 所属组织（租户）
+	Username           string       `json:"username" db:"username"`
+	Password           string       `json:"-" db:"password"` // 密码不序列化到JSON
+	Phone              string       `json:"phone" db:"phone"`
+	Email              string       `json:"email" db:"email"`
+	Status             int          `json:"status" db:"status"` // 状态（0-正常，1-封禁，2-锁定）
+	Roles              string       `json:"roles" db:"roles"`   // 角色标识，多个用英文逗号分隔
+	LastLoginAt        sql.NullTime `json:"LastLoginAt" db:"last_login_at"`
+	LastLoginIP        string       `json:"loginIp" db:"login_ip"`
+	PasswordResetAt    sql.NullTime `json:"passwordResetTime" db:"password_reset_at"`
+	LoginFailCount     int          `json:"loginFailCount" db:"login_fail_count"`
+	LockedAt           sql.NullTime `json:"lockedAt" db:"locked_at"`
+	LockedUntil        sql.NullTime `json:"lockedUntil" db:"locked_until"`                // 锁定到期时间
+	LockCount          int          `json:"lockCount" db:"lock_count"`                    // 连续被锁定的次数，用于递增锁定时长
+	LastLoginFailAt    sql.NullTime `json:"lastLoginFailAt" db:"last_login_fail_at"`      // 最近一次登录失败时间
+	MustChangePassword bool         `json:"mustChangePassword" db:"must_change_password"` // 下次登录必须修改密码
+	Attributes         Attributes   `json:"attributes" db:"attributes"`                   // 扩展属性（JSONB）
+	Deleted            SoftDelete   `json:"-" db:"deleted"`                               // 软删除标记不暴露给前端，查询默认排除已删除的用户
+	DeletedAt          sql.NullTime `json:"deletedAt" db:"deleted_at"`                    // 删除时间，超过保留期后被彻底清除
+	Version            int          `json:"version" db:"version"`                         // 乐观锁版本号
+	CreatedAt          time.Time    `json:"createAt" db:"created_at"`
+	UpdatedAt          sql.NullTime `json:"updatedAt" db:"updated_ta"`
+	CreatedBy          string       `json:"createdBy" db:"created_by"`
+	UpdatedBy          string       `json:"updatedBy" db:"updated_by"`
 }
 
 // TableName 返回表名
@@ -35,33 +42,42 @@ func (User) TableName() string {
 	return "user"
 }
 
-// CheckPassword 检查密码是否匹配
-func (u *User) CheckPassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
-	return err == nil
+// CheckPassword 检查密码是否匹配，rehash 为 true 表示哈希已过时，应按当前配置重新生成
+func (u *User) CheckPassword(hasher *password.Hasher, plain string) (ok bool, rehash bool) {
+	return hasher.Verify(plain, u.Password)
 }
 
-// HashPassword 对密码进行哈希处理
-func (u *User) HashPassword() error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+// HashPassword 对 Password 中的明文密码进行哈希处理
+func (u *User) HashPassword(hasher *password.Hasher) error {
+	hashed, err := hasher.Hash(u.Password)
 	if err != nil {
 		return err
 	}
-	u.Password = string(hashedPassword)
+	u.Password = hashed
 	return nil
 }
 
-// IsAccountNonLocked 检查账户是否未锁定
+// IsAccountNonLocked 检查账户是否未锁定，锁定时长由锁定策略写入 LockedUntil
 func (u *User) IsAccountNonLocked() bool {
 	if u.Status == 0 {
 		return true
 	}
-	if u.Status == 2 && u.LockedAt.Valid {
-		return time.Now().After(u.LockedAt.Time.Add(time.Hour))
+	if u.Status == 2 {
+		return !u.LockedUntil.Valid || !time.Now().Before(u.LockedUntil.Time)
 	}
 	return false
 }
 
+// Unlock 解除锁定并清空登录失败计数
+func (u *User) Unlock() {
+	if u.Status == 2 {
+		u.Status = 0
+	}
+	u.LoginFailCount = 0
+	u.LockedAt = sql.NullTime{}
+	u.LockedUntil = sql.NullTime{}
+}
+
 // IsEnabled 检查账户是否启用
 func (u *User) IsEnabled() bool {
 	return u.Status != 1 && u.Deleted == 0
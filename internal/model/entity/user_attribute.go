@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Attributes 用户扩展属性（如工号、部门、成本中心），以 JSONB 存储，结构由所属租户的属性 schema 约束
+type Attributes map[string]interface{}
+
+// Value 实现 driver.Valuer，空值存为 {}
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner
+func (a *Attributes) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为 Attributes", src)
+	}
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*a = m
+	return nil
+}
+
+// AttributeSchema 租户的用户扩展属性定义（JSON Schema），每个租户一份
+type AttributeSchema struct {
+	TenantID  int64     `json:"tenantId" db:"tenant_id" gorm:"primaryKey;autoIncrement:false"`
+	Schema    string    `json:"schema" db:"schema"`   // JSON Schema 文档，根节点为 object
+	Version   int       `json:"version" db:"version"` // 每次修改加一
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+	CreatedBy string    `json:"createdBy" db:"created_by"`
+	UpdatedBy string    `json:"updatedBy" db:"updated_by"`
+}
+
+// TableName 返回表名
+func (AttributeSchema) TableName() string {
+	return "user_attribute_schema"
+}
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UserGroup 用户组实体结构体，支持通过 ParentID 嵌套：
+// 子组成员同时视为所有祖先组的成员，继承祖先组的角色
+type UserGroup struct {
+	ID          int64         `json:"id" db:"id"`
+	TenantID    int64         `json:"tenantId" db:"tenant_id"`
+	Name        string        `json:"name" db:"name"`
+	Description string        `json:"description" db:"description"`
+	ParentID    sql.NullInt64 `json:"parentId" db:"parent_id"` // 上级组，为空表示顶级组
+	Deleted     int           `json:"-" db:"deleted"`          // 软删除标记不暴露给前端
+	Version     int           `json:"version" db:"version"`
+	CreatedAt   time.Time     `json:"createdAt" db:"created_at"`
+	UpdatedAt   sql.NullTime  `json:"updatedAt" db:"updated_at"`
+	CreatedBy   string        `json:"createdBy" db:"created_by"`
+	UpdatedBy   string        `json:"updatedBy" db:"updated_by"`
+}
+
+// TableName 返回表名
+func (UserGroup) TableName() string {
+	return "user_group"
+}
+
+// UserGroupMember 用户组成员关系
+type UserGroupMember struct {
+	GroupID   int64     `json:"groupId" db:"group_id" gorm:"primaryKey"`
+	UserID    int64     `json:"userId" db:"user_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	CreatedBy string    `json:"createdBy" db:"created_by"`
+}
+
+// TableName 返回表名
+func (UserGroupMember) TableName() string {
+	return "user_group_member"
+}
+
+// UserGroupRole 用户组被授予的角色
+type UserGroupRole struct {
+	GroupID   int64     `json:"groupId" db:"group_id" gorm:"primaryKey"`
+	RoleID    int64     `json:"roleId" db:"role_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	CreatedBy string    `json:"createdBy" db:"created_by"`
+}
+
+// TableName 返回表名
+func (UserGroupRole) TableName() string {
+	return "user_group_role"
+}
@@ -3,21 +3,34 @@ package entity
 import (
 	"database/sql"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// 性别
+const (
+	GenderUnknown = 0 // 未知
+	GenderMale    = 1 // 男
+	GenderFemale  = 2 // 女
 )
 
-// UserProfile 用户资料实体结构体
+// UserProfile 用户资料实体结构体，与用户一对一
 type UserProfile struct {
-	UserID    int64        `json:"userId" db:"user_id"`
+	UserID    int64        `json:"userId" db:"user_id" gorm:"primaryKey;autoIncrement:false"`
 	RealName  string       `json:"realName" db:"real_name"`
-	Gender    int          `json:"gender" db:"gender"`
+	Gender    int          `json:"gender" db:"gender"` // 性别（0-未知，1-男，2-女）
 	Birthday  sql.NullTime `json:"birthday" db:"birthday"`
-	Avatar    string       `json:"avatar" db:"avatar"`
+	Avatar    string       `json:"avatar" db:"avatar"`   // 外部头像地址
+	AvatarKey string       `json:"-" db:"avatar_key"`    // 上传的头像在对象存储中的 key 前缀，缩略图为 {AvatarKey}/{尺寸}.jpg
 	Deleted   int          `json:"-" db:"deleted"`       // 软删除标记不暴露给前端
 	Version   int          `json:"version" db:"version"` // 乐观锁版本号
 	CreatedAt time.Time    `json:"createdAt" db:"created_at"`
-	UpdatedAt time.Time    `json:"updatedAt" db:"updated_ta"`
+	UpdatedAt time.Time    `json:"updatedAt" db:"updated_at"`
 	CreatedBy string       `json:"createdBy" db:"created_by"`
 	UpdatedBy string       `json:"updatedBy" db:"updated_by"`
+
+	// AvatarURLs 上传头像各尺寸的签名访问地址，按尺寸索引，查询时生成
+	AvatarURLs map[string]string `json:"avatarUrls,omitempty" gorm:"-"`
 }
 
 // TableName 返回表名
@@ -25,14 +38,23 @@ func (UserProfile) TableName() string {
 	return "user_profile"
 }
 
-// BeforeUpdate 更新前的钩子函数，设置更新时间
-func (up *UserProfile) BeforeUpdate() {
-	up.CreatedAt = time.Now()
-	up.UpdatedAt = time.Now()
-	if up.Deleted == 0 {
-		up.Deleted = 0 // 默认未删除
-	}
-	if up.Version == 0 {
-		up.Version = 0 // 默认版本号
+// BeforeCreate 创建前的钩子函数，设置创建与更新时间
+func (up *UserProfile) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	if up.CreatedAt.IsZero() {
+		up.CreatedAt = now
 	}
+	up.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate 更新前的钩子函数，只刷新更新时间，创建时间保持不变
+func (up *UserProfile) BeforeUpdate(tx *gorm.DB) error {
+	up.UpdatedAt = time.Now()
+	return nil
+}
+
+// ValidGender 判断性别取值是否合法
+func ValidGender(gender int) bool {
+	return gender == GenderUnknown || gender == GenderMale || gender == GenderFemale
 }
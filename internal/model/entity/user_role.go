@@ -6,6 +6,7 @@ import (
 
 type UserRole struct {
 	ID        int64     `json:"id" db:"id"`
+	TenantID  int64     `json:"tenant_id" db:"tenant_id"` // 所属租户，0 表示全局共享角色
 	RoleName  string    `json:"role_name" db:"role_name"`
 	IsDefault bool      `json:"is_default" db:"is_default"`
 	Deleted   int       `json:"-" db:"deleted"`       // 软删除标记不暴露给前端
@@ -0,0 +1,29 @@
+package request
+
+import "time"
+
+// AuditSearchRequest 审计事件查询条件，时间使用 RFC 3339 格式
+type AuditSearchRequest struct {
+	ActorId    *int64    `form:"actorId" binding:"omitempty,min=1"`
+	ActorName  string    `form:"actorName" binding:"omitempty,max=50"`
+	TargetId   *int64    `form:"targetId" binding:"omitempty,min=1"`
+	TargetName string    `form:"targetName" binding:"omitempty,max=50"`
+	Action     string    `form:"action" binding:"omitempty,max=50"` // 以 . 结尾时按前缀匹配，如 auth.
+	Result     string    `form:"result" binding:"omitempty,oneof=success failure"`
+	IP         string    `form:"ip" binding:"omitempty,ip"`
+	RequestId  string    `form:"requestId" binding:"omitempty,max=64"`
+	StartTime  time.Time `form:"startTime"`
+	EndTime    time.Time `form:"endTime"`
+}
+
+// AuditSearchRequestValidationMessages 审计事件查询验证消息
+var AuditSearchRequestValidationMessages = map[string]string{
+	"ActorId.min":    "操作人ID不合法",
+	"ActorName.max":  "操作人名称过长",
+	"TargetId.min":   "目标用户ID不合法",
+	"TargetName.max": "目标用户名过长",
+	"Action.max":     "操作类型过长",
+	"Result.oneof":   "结果只能是 success 或 failure",
+	"IP.ip":          "IP地址格式不正确",
+	"RequestId.max":  "请求ID过长",
+}
@@ -0,0 +1,14 @@
+package request
+
+// BulkUserRequest 批量操作请求，UserIds 与 Filter 二选一
+type BulkUserRequest struct {
+	UserIds []int64            `json:"userIds" binding:"omitempty,max=5000,dive,min=1"` // 明确指定的用户
+	Filter  *UserSearchRequest `json:"filter"`                                          // 按搜索条件选择用户，与 SearchUsers 相同
+	RoleIds []int64            `json:"roleIds" binding:"omitempty,dive,required"`       // 批量分配角色时的新角色
+}
+
+// BulkUserRequestValidationMessages 批量操作请求验证消息
+var BulkUserRequestValidationMessages = map[string]string{
+	"UserIds.max": "单次最多操作5000个用户",
+	"UserIds.min": "用户 ID 不合法",
+}
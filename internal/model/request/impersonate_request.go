@@ -0,0 +1,12 @@
+package request
+
+// ImpersonateRequest 模拟登录请求结构体
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required,max=200"` // 模拟登录原因，如工单号
+}
+
+// ImpersonateRequestValidationMessages 模拟登录请求验证消息
+var ImpersonateRequestValidationMessages = map[string]string{
+	"Reason.required": "请填写模拟登录原因",
+	"Reason.max":      "模拟登录原因不能超过200个字符",
+}
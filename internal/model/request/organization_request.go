@@ -0,0 +1,30 @@
+package request
+
+// OrganizationCreateRequest 创建组织请求结构体
+type OrganizationCreateRequest struct {
+	Name string `json:"name" binding:"required,max=100"`               // 组织名称
+	Code string `json:"code" binding:"required,min=2,max=50,alphanum"` // 组织编码
+}
+
+// OrganizationCreateRequestValidationMessages 创建组织请求验证消息
+var OrganizationCreateRequestValidationMessages = map[string]string{
+	"Name.required": "组织名称不能为空",
+	"Name.max":      "组织名称不能超过100个字符",
+	"Code.required": "组织编码不能为空",
+	"Code.min":      "组织编码长度应在2-50个字符之间",
+	"Code.max":      "组织编码长度应在2-50个字符之间",
+	"Code.alphanum": "组织编码只能包含字母和数字",
+}
+
+// OrganizationUpdateRequest 更新组织请求结构体
+type OrganizationUpdateRequest struct {
+	Name   string `json:"name" binding:"omitempty,max=100"`
+	Status *int   `json:"status" binding:"omitempty,min=0,max=1"` // 状态（0-正常，1-停用）
+}
+
+// OrganizationUpdateRequestValidationMessages 更新组织请求验证消息
+var OrganizationUpdateRequestValidationMessages = map[string]string{
+	"Name.max":   "组织名称不能超过100个字符",
+	"Status.min": "状态不合法",
+	"Status.max": "状态不合法",
+}
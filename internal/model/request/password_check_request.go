@@ -0,0 +1,14 @@
+package request
+
+// PasswordCheckRequest 密码强度检测请求结构体，用户信息用于检查密码是否包含用户名、邮箱等
+type PasswordCheckRequest struct {
+	Password string `json:"password" binding:"required"` // 待检测的密码
+	Username string `json:"username,omitempty"`          // 用户名
+	Email    string `json:"email,omitempty"`             // 邮箱地址
+	Phone    string `json:"phone,omitempty"`             // 电话号码
+}
+
+// PasswordCheckRequestValidationMessages 密码强度检测请求验证消息
+var PasswordCheckRequestValidationMessages = map[string]string{
+	"Password.required": "密码不能为空",
+}
@@ -0,0 +1,17 @@
+package request
+
+// PolicyEvaluateRequest 策略试算请求结构体
+type PolicyEvaluateRequest struct {
+	SubjectId  int64                  `json:"subjectId" binding:"required,min=1"`   // 主体用户 ID
+	Action     string                 `json:"action" binding:"required"`            // 操作，如 user:read
+	ResourceId int64                  `json:"resourceId" binding:"omitempty,min=1"` // 目标用户 ID，可为空
+	Context    map[string]interface{} `json:"context"`                              // 额外的请求上下文属性
+}
+
+// PolicyEvaluateRequestValidationMessages 策略试算请求验证消息
+var PolicyEvaluateRequestValidationMessages = map[string]string{
+	"SubjectId.required": "主体用户不能为空",
+	"SubjectId.min":      "主体用户不合法",
+	"Action.required":    "操作不能为空",
+	"ResourceId.min":     "资源用户不合法",
+}
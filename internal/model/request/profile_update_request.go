@@ -0,0 +1,13 @@
+package request
+
+// ProfileUpdateRequest 用户修改自己资料的请求结构体，用户名不允许自行修改
+type ProfileUpdateRequest struct {
+	Phone string `json:"phone" binding:"omitempty,phone"`
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+// ProfileUpdateRequestValidationMessages 修改个人资料请求验证消息
+var ProfileUpdateRequestValidationMessages = map[string]string{
+	"Phone.phone": "手机号格式不正确",
+	"Email.email": "邮箱格式不正确",
+}
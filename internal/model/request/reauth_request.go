@@ -0,0 +1,11 @@
+package request
+
+// ReauthRequest 重新验证身份请求结构体
+type ReauthRequest struct {
+	Password string `json:"password" binding:"required"` // 当前密码
+}
+
+// ReauthRequestValidationMessages 重新验证身份请求验证消息
+var ReauthRequestValidationMessages = map[string]string{
+	"Password.required": "密码不能为空",
+}
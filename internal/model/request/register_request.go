@@ -3,9 +3,12 @@ package request
 // RegisterRequest 注册请求结构体
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=2,max=20"`                // 用户名
-	Password string `json:"password" binding:"required,min=6"`                       // 密码
+	Password string `json:"password" binding:"required"`                             // 密码，强度由密码策略校验
 	Phone    string `json:"phone,omitempty" binding:"omitempty,startswith=1,len=11"` // 电话号码
 	Email    string `json:"email,omitempty" binding:"omitempty,email"`               // 邮箱地址
+	OrgCode  string `json:"orgCode,omitempty" binding:"omitempty,max=50"`            // 所属组织编码，为空时归属默认租户
+	// Attributes 扩展属性，按所属组织的属性定义校验
+	Attributes map[string]interface{} `json:"attributes,omitempty" binding:"omitempty,max=50"`
 }
 
 // RegisterRequestValidationMessages 注册请求验证消息
@@ -14,8 +17,9 @@ var RegisterRequestValidationMessages = map[string]string{
 	"Username.min":      "用户名长度应在2-20个字符之间",
 	"Username.max":      "用户名长度应在2-20个字符之间",
 	"Password.required": "密码不能为空",
-	"Password.min":      "密码至少6位",
 	"Phone.startswith":  "电话号码格式不正确",
 	"Phone.len":         "电话号码格式不正确",
 	"Email.email":       "邮箱格式不正确",
+	"OrgCode.max":       "组织编码过长",
+	"Attributes.max":    "扩展属性过多",
 }
@@ -0,0 +1,11 @@
+package request
+
+// ReviewRoleChangeRequest 角色变更审批请求结构体
+type ReviewRoleChangeRequest struct {
+	Comment string `json:"comment" binding:"omitempty,max=200"` // 审批意见
+}
+
+// ReviewRoleChangeRequestValidationMessages 角色变更审批请求验证消息
+var ReviewRoleChangeRequestValidationMessages = map[string]string{
+	"Comment.max": "审批意见不能超过200个字符",
+}
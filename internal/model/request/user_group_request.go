@@ -0,0 +1,43 @@
+package request
+
+// UserGroupCreateRequest 创建用户组请求结构体
+type UserGroupCreateRequest struct {
+	Name        string `json:"name" binding:"required,max=50"`
+	Description string `json:"description" binding:"omitempty,max=200"`
+	ParentId    *int64 `json:"parentId" binding:"omitempty,min=1"` // 上级组
+	TenantId    *int64 `json:"tenantId" binding:"omitempty,min=0"` // 仅超级管理员可指定，默认为当前租户
+}
+
+// UserGroupCreateRequestValidationMessages 创建用户组请求验证消息
+var UserGroupCreateRequestValidationMessages = map[string]string{
+	"Name.required":   "用户组名称不能为空",
+	"Name.max":        "用户组名称不能超过50个字符",
+	"Description.max": "描述不能超过200个字符",
+	"ParentId.min":    "上级组不合法",
+	"TenantId.min":    "租户不合法",
+}
+
+// UserGroupUpdateRequest 更新用户组请求结构体
+type UserGroupUpdateRequest struct {
+	Name        string `json:"name" binding:"omitempty,max=50"`
+	Description string `json:"description" binding:"omitempty,max=200"`
+	ParentId    *int64 `json:"parentId" binding:"omitempty,min=0"` // 0 表示移为顶级组
+}
+
+// UserGroupUpdateRequestValidationMessages 更新用户组请求验证消息
+var UserGroupUpdateRequestValidationMessages = map[string]string{
+	"Name.max":        "用户组名称不能超过50个字符",
+	"Description.max": "描述不能超过200个字符",
+	"ParentId.min":    "上级组不合法",
+}
+
+// UserGroupMembersRequest 批量添加成员请求结构体
+type UserGroupMembersRequest struct {
+	UserIds []int64 `json:"userIds" binding:"required,min=1,dive,min=1"`
+}
+
+// UserGroupMembersRequestValidationMessages 批量添加成员请求验证消息
+var UserGroupMembersRequestValidationMessages = map[string]string{
+	"UserIds.required": "用户不能为空",
+	"UserIds.min":      "用户不能为空",
+}
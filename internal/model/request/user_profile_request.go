@@ -0,0 +1,18 @@
+package request
+
+// UserProfileRequest 保存用户资料请求结构体，字段为空表示不修改
+type UserProfileRequest struct {
+	RealName string `json:"realName" binding:"omitempty,max=50"`
+	Gender   *int   `json:"gender" binding:"omitempty,oneof=0 1 2"`           // 0-未知，1-男，2-女
+	Birthday string `json:"birthday" binding:"omitempty,datetime=2006-01-02"` // 格式 yyyy-MM-dd
+	Avatar   string `json:"avatar" binding:"omitempty,http_url,max=255"`      // 只允许 http/https 地址
+}
+
+// UserProfileRequestValidationMessages 保存用户资料请求验证消息
+var UserProfileRequestValidationMessages = map[string]string{
+	"RealName.max":      "姓名不能超过50个字符",
+	"Gender.oneof":      "性别只能是0（未知）、1（男）或2（女）",
+	"Birthday.datetime": "生日格式应为yyyy-MM-dd",
+	"Avatar.http_url":   "头像地址必须是http或https链接",
+	"Avatar.max":        "头像地址不能超过255个字符",
+}
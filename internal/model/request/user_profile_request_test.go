@@ -0,0 +1,34 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestUserProfileRequestAvatar(t *testing.T) {
+	v := validator.New()
+	v.SetTagName("binding")
+
+	tests := []struct {
+		avatar string
+		valid  bool
+	}{
+		{"", true},
+		{"https://cdn.example.com/a.png", true},
+		{"http://cdn.example.com/a.png", true},
+		{"HTTPS://cdn.example.com/a.png", true},
+		{"javascript:alert(1)", false},
+		{"data:image/png;base64,iVBORw0KGgo=", false},
+		{"ftp://example.com/a.png", false},
+		{"file:///etc/passwd", false},
+		{"//cdn.example.com/a.png", false},
+		{"https://", false},
+	}
+	for _, tt := range tests {
+		err := v.Struct(UserProfileRequest{Avatar: tt.avatar})
+		if (err == nil) != tt.valid {
+			t.Errorf("Avatar %q 校验错误 = %v, 期望合法 %v", tt.avatar, err, tt.valid)
+		}
+	}
+}
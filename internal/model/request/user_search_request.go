@@ -14,6 +14,7 @@ type UserSearchRequest struct {
 	Phone           string    `form:"phone" binding:"omitempty,phone"`
 	Email           string    `form:"email" binding:"omitempty,email,max=100"`
 	Status          *int      `form:"status" binding:"omitempty,min=0,max=1"`
+	TenantId        *int64    `form:"tenantId" binding:"omitempty,min=0"` // 仅超级管理员跨租户查询时有意义
 	Roles           string    `form:"roles" binding:"omitempty,rolesFormat"`
 	LastLoginAt     time.Time `form:"lastLoginAt" binding:"omitempty,ltnow"`
 	LastLoginIp     string    `form:"lastLoginIp" binding:"omitempty,ip"`
@@ -30,6 +31,8 @@ type UserSearchRequest struct {
 	UpdateTimeEnd   time.Time `form:"updateTimeEnd" binding:"omitempty,ltnow"`
 	CreatedBy       string    `form:"createdBy" binding:"omitempty,max=50"`
 	UpdatedBy       string    `form:"updatedBy" binding:"omitempty,max=50"`
+	// Attributes 按扩展属性精确匹配，多个属性需同时满足
+	Attributes map[string]interface{} `form:"-" json:"attributes" binding:"omitempty,max=10"`
 }
 
 // UserSearchRequestValidationMessages 用户搜索请求验证消息
@@ -42,6 +45,7 @@ var UserSearchRequestValidationMessages = map[string]string{
 	"Email.max":               "邮箱长度不能超过100个字符",
 	"Status.min":              "状态不合法",
 	"Status.max":              "状态不合法",
+	"TenantId.min":            "租户不合法",
 	"Roles.rolesFormat":       "角色格式不正确",
 	"LastLoginAt.ltnow":       "登录时间不能是未来时间",
 	"LastLoginIp.ip":          "IP地址格式不正确",
@@ -60,6 +64,7 @@ var UserSearchRequestValidationMessages = map[string]string{
 	"UpdateTimeEnd.ltnow":     "结束时间不能是未来时间",
 	"CreatedBy.max":           "创建人名称过长",
 	"UpdatedBy.max":           "更新人名称过长",
+	"Attributes.max":          "最多按10个扩展属性查询",
 }
 
 // ValidateUserSearchRequest 自定义验证方法
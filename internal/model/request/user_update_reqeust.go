@@ -5,12 +5,15 @@ type UserUpdateRequest struct {
 	Username string `json:"username" binding:"omitempty,min=2,max=20"`
 	Phone    string `json:"phone" binding:"omitempty,phone"`
 	Email    string `json:"email" binding:"omitempty,email"`
+	// Attributes 要修改的扩展属性，与原有属性合并，值为 null 表示删除该属性
+	Attributes map[string]interface{} `json:"attributes" binding:"omitempty,max=50"`
 }
 
 // UserUpdateRequestValidationMessages 用户更新请求验证消息
 var UserUpdateRequestValidationMessages = map[string]string{
-	"Username.min": "用户名长度应在2-20个字符之间",
-	"Username.max": "用户名长度应在2-20个字符之间",
-	"Phone.phone":  "手机号格式不正确",
-	"Email.email":  "邮箱格式不正确",
+	"Username.min":   "用户名长度应在2-20个字符之间",
+	"Username.max":   "用户名长度应在2-20个字符之间",
+	"Phone.phone":    "手机号格式不正确",
+	"Email.email":    "邮箱格式不正确",
+	"Attributes.max": "一次最多修改50个扩展属性",
 }
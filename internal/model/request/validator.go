@@ -0,0 +1,14 @@
+package request
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterBindingValidators 在 gin 默认的校验器上注册请求结构体使用的自定义规则（phone、usernameFormat 等），
+// 必须在处理请求前调用，否则绑定带有这些规则的请求时校验器会 panic
+func RegisterBindingValidators() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		RegisterUserSearchValidators(v)
+	}
+}
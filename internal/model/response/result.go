@@ -16,6 +16,11 @@ func Fail(c *gin.Context, msg string) {
 	c.JSON(400, Result{Code: 400, Message: msg})
 }
 
+// FailWithData 返回 400 并附带错误详情，如密码策略的违规列表
+func FailWithData(c *gin.Context, msg string, data interface{}) {
+	c.JSON(400, Result{Code: 400, Message: msg, Data: data})
+}
+
 func Unauthorized(c *gin.Context, msg string) {
 	c.JSON(401, Result{Code: 401, Message: msg})
 }
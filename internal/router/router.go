@@ -8,63 +8,215 @@ import (
 	"github.com/bryantaolong/system/internal/handler"
 	"github.com/bryantaolong/system/internal/middleware"
 	"github.com/bryantaolong/system/internal/service"
+	"github.com/bryantaolong/system/pkg/blob"
+	"github.com/bryantaolong/system/pkg/ratelimit"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
+// RateLimits 认证接口的限流速率，速率为零值表示不限制
+type RateLimits struct {
+	LoginPerIP       ratelimit.Rate
+	LoginPerUsername ratelimit.Rate
+	RegisterPerIP    ratelimit.Rate
+	ReauthPerUser    ratelimit.Rate
+}
+
+// stepUpMaxAge 删除用户、强制改密、变更角色等操作要求的最近认证时间
+const stepUpMaxAge = 5 * time.Minute
+
 func NewRouter(
 	redisClient *redis.Client,
 	authService *service.AuthService,
 	userService *service.UserService,
+	profileService *service.UserProfileService,
+	attributeService *service.AttributeSchemaService,
+	importService *service.UserImportService,
+	bulkService *service.UserBulkService,
+	purgeService *service.UserPurgeService,
 	userRoleService *service.UserRoleService,
+	roleChangeService *service.RoleChangeService,
+	policyService *service.PolicyService,
+	orgService *service.OrganizationService,
+	groupService *service.UserGroupService,
+	impersonationService *service.ImpersonationService,
+	auditService *service.AuditService,
+	loginLogService *service.LoginLogService,
+	blobStore blob.Store,
+	limiter ratelimit.Limiter,
+	limits RateLimits,
 ) *gin.Engine {
 	r := gin.New()
-	r.Use(gin.Logger(), gin.Recovery())
+	r.Use(middleware.RequestID(), gin.Logger(), gin.Recovery())
 
 	// CORS 配置
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Request-ID"},
+		ExposeHeaders:    []string{"X-Request-ID"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	authHandler := handler.NewAuthHandler(authService)
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, profileService, roleChangeService, policyService, purgeService)
 	userRoleHandler := handler.NewUserRoleHandler(userRoleService)
+	roleChangeHandler := handler.NewRoleChangeHandler(roleChangeService)
+	policyHandler := handler.NewPolicyHandler(policyService)
+	orgHandler := handler.NewOrganizationHandler(orgService)
+	groupHandler := handler.NewUserGroupHandler(groupService)
+	impersonationHandler := handler.NewImpersonationHandler(impersonationService, userService, policyService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	loginLogHandler := handler.NewLoginLogHandler(loginLogService)
+	attributeHandler := handler.NewAttributeSchemaHandler(attributeService)
+	importHandler := handler.NewUserImportHandler(importService)
+	bulkHandler := handler.NewUserBulkHandler(bulkService)
+	meHandler := handler.NewMeHandler(userService, profileService, authService)
 
 	// 公开接口
 	public := r.Group("/api/auth")
 	{
-		public.POST("/register", authHandler.Register)
-		public.POST("/login", authHandler.Login)
+		public.POST("/register", middleware.RateLimit(limiter,
+			middleware.RateLimitRule{Name: "ip", Rate: limits.RegisterPerIP, Key: middleware.ByIP},
+		), authHandler.Register)
+		public.POST("/login", middleware.RateLimit(limiter,
+			middleware.RateLimitRule{Name: "ip", Rate: limits.LoginPerIP, Key: middleware.ByIP},
+			middleware.RateLimitRule{Name: "username", Rate: limits.LoginPerUsername, Key: middleware.ByBodyUsernameAndIP},
+		), authHandler.Login)
+		public.POST("/password/check", authHandler.CheckPassword)
 		public.GET("/validate", authHandler.Validate)
 	}
 
+	// 本地对象存储的下载地址由签名鉴权，使用 S3 时签名地址直接指向对象存储
+	if local, ok := blobStore.(*blob.LocalStore); ok {
+		r.GET("/api/blob/*key", handler.NewBlobHandler(local).Serve)
+	}
+
+	// 修改自己的密码，同时接受密码过期或被重置时登录返回的受限 Token
+	r.PUT("/api/me/password", middleware.PasswordChangeAuth(redisClient), middleware.NoImpersonation(), meHandler.ChangePassword)
+
 	// 受保护接口
 	protected := r.Group("/api")
-	protected.Use(middleware.AuthRequired(redisClient))
+	protected.Use(middleware.AuthRequired(redisClient), middleware.ImpersonationAudit(impersonationService))
 	{
 		protected.GET("/auth/me", authHandler.Me)
+		protected.GET("/auth/me/logins", loginLogHandler.MyLogins)
 		protected.GET("/auth/logout", authHandler.Logout)
+		protected.POST("/auth/reauth", middleware.RateLimit(limiter,
+			middleware.RateLimitRule{Name: "user", Rate: limits.ReauthPerUser, Key: middleware.ByCurrentUser},
+		), authHandler.Reauth)
 
-		admin := protected.Group("/user")
-		admin.Use(middleware.RoleRequired("ROLE_ADMIN"))
+		// 当前用户自助接口，用户取自 Token
+		me := protected.Group("/me")
 		{
-			admin.POST("/all", userHandler.GetAllUsers)
-			admin.GET("/:userId", userHandler.GetUserByID)
-			admin.GET("/username/:username", userHandler.GetUserByUsername)
-			admin.POST("/search", userHandler.SearchUsers)
-			admin.PUT("/:userId", userHandler.UpdateUser)
-			admin.PUT("/:userId/role", userHandler.ChangeRole)
-			admin.PUT("/:userId/password", userHandler.ChangePassword)
-			admin.PUT("/:userId/password/force/:newPassword", userHandler.ChangePasswordForcefully)
-			admin.PUT("/:userId/block", userHandler.BlockUser)
-			admin.PUT("/:userId/unblock", userHandler.UnblockUser)
-			admin.DELETE("/:userId", userHandler.DeleteUser)
+			me.GET("", authHandler.Me)
+			me.PUT("", meHandler.UpdateProfile)
+			me.GET("/profile", meHandler.GetProfile)
+			me.PUT("/profile", meHandler.SaveProfile)
+			me.POST("/avatar", meHandler.UploadAvatar)
+			me.DELETE("/avatar", meHandler.RemoveAvatar)
+			me.GET("/logins", loginLogHandler.MyLogins)
+			me.GET("/sessions", meHandler.Sessions)
+			me.DELETE("", middleware.NoImpersonation(), middleware.RecentAuthRequired(stepUpMaxAge), meHandler.DeleteAccount)
+		}
 
+		// 用户管理接口由策略引擎逐个操作鉴权
+		users := protected.Group("/user")
+		{
+			users.POST("/all", userHandler.GetAllUsers)
+			users.GET("/:userId", userHandler.GetUserByID)
+			users.GET("/username/:username", userHandler.GetUserByUsername)
+			users.POST("/search", userHandler.SearchUsers)
+			users.POST("/export", userHandler.Export)
+			users.POST("/trash", userHandler.ListTrash)
+			users.PUT("/:userId", userHandler.UpdateUser)
+			users.GET("/:userId/profile", userHandler.GetProfile)
+			users.PUT("/:userId/profile", userHandler.SaveProfile)
+			users.POST("/:userId/avatar", userHandler.UploadAvatar)
+			users.DELETE("/:userId/avatar", userHandler.RemoveAvatar)
+			users.PUT("/:userId/role", middleware.NoImpersonation(), middleware.RecentAuthRequired(stepUpMaxAge), userHandler.ChangeRole)
+			users.PUT("/:userId/password", middleware.NoImpersonation(), userHandler.ChangePassword)
+			users.PUT("/:userId/password/force/:newPassword", middleware.NoImpersonation(), middleware.RecentAuthRequired(stepUpMaxAge), userHandler.ChangePasswordForcefully)
+			users.PUT("/:userId/block", userHandler.BlockUser)
+			users.PUT("/:userId/unblock", userHandler.UnblockUser)
+			users.PUT("/:userId/unlock", userHandler.UnlockUser)
+			users.DELETE("/:userId", middleware.NoImpersonation(), middleware.RecentAuthRequired(stepUpMaxAge), userHandler.DeleteUser)
+			users.PUT("/:userId/restore", userHandler.RestoreUser)
+
+			// 批量操作逐个用户按策略鉴权，与对应的单用户接口使用相同的安全要求
+			bulk := users.Group("/bulk")
+			{
+				bulk.POST("/block", bulkHandler.Handle(service.BulkBlock))
+				bulk.POST("/unblock", bulkHandler.Handle(service.BulkUnblock))
+				bulk.POST("/delete", middleware.NoImpersonation(), middleware.RecentAuthRequired(stepUpMaxAge), bulkHandler.Handle(service.BulkDelete))
+				bulk.POST("/restore", bulkHandler.Handle(service.BulkRestore))
+				bulk.POST("/role", middleware.NoImpersonation(), middleware.RecentAuthRequired(stepUpMaxAge), bulkHandler.Handle(service.BulkRole))
+				bulk.POST("/logout", bulkHandler.Handle(service.BulkLogout))
+			}
+		}
+
+		admin := protected.Group("/user")
+		admin.Use(middleware.RoleRequired("ROLE_ADMIN", "ROLE_SUPER_ADMIN"))
+		{
 			admin.GET("/role/all", userRoleHandler.ListRoles)
+			admin.GET("/role/requests", roleChangeHandler.ListRequests)
+			admin.PUT("/role/requests/:requestId/approve", middleware.NoImpersonation(), middleware.RecentAuthRequired(stepUpMaxAge), roleChangeHandler.Approve)
+			admin.PUT("/role/requests/:requestId/reject", middleware.NoImpersonation(), roleChangeHandler.Reject)
+
+			admin.GET("/attributes/schema", attributeHandler.Get)
+			admin.PUT("/attributes/schema", attributeHandler.Save)
+			admin.DELETE("/attributes/schema", attributeHandler.Delete)
+
+			admin.POST("/import", middleware.NoImpersonation(), importHandler.Import)
+			admin.GET("/import/:jobId", importHandler.Job)
+
+			admin.GET("/:userId/groups", groupHandler.UserGroups)
+			admin.GET("/:userId/logins", loginLogHandler.UserLogins)
+		}
+
+		groupAdmin := protected.Group("/group")
+		groupAdmin.Use(middleware.RoleRequired("ROLE_ADMIN", "ROLE_SUPER_ADMIN"))
+		{
+			groupAdmin.POST("", groupHandler.Create)
+			groupAdmin.GET("", groupHandler.List)
+			groupAdmin.GET("/:groupId", groupHandler.Get)
+			groupAdmin.PUT("/:groupId", groupHandler.Update)
+			groupAdmin.DELETE("/:groupId", groupHandler.Delete)
+			groupAdmin.GET("/:groupId/members", groupHandler.ListMembers)
+			groupAdmin.POST("/:groupId/members", groupHandler.AddMembers)
+			groupAdmin.DELETE("/:groupId/members/:userId", groupHandler.RemoveMember)
+			groupAdmin.GET("/:groupId/roles", groupHandler.ListRoles)
+			groupAdmin.PUT("/:groupId/roles", groupHandler.SetRoles)
+		}
+
+		policyAdmin := protected.Group("/policy")
+		policyAdmin.Use(middleware.RoleRequired("ROLE_ADMIN", "ROLE_SUPER_ADMIN"))
+		{
+			policyAdmin.GET("/rules", policyHandler.ListRules)
+			policyAdmin.POST("/evaluate", policyHandler.Evaluate)
+		}
+
+		// 审计日志查询与导出，租户管理员只能看到本租户的事件
+		auditAdmin := protected.Group("/audit")
+		auditAdmin.Use(middleware.RoleRequired("ROLE_ADMIN", "ROLE_SUPER_ADMIN"))
+		{
+			auditAdmin.GET("/events", auditHandler.Search)
+			auditAdmin.GET("/events/export", auditHandler.Export)
+		}
+
+		// 模拟登录：权限由策略 user:impersonate 判定，模拟状态下不能再次发起
+		protected.POST("/admin/impersonate/:userId", middleware.NoImpersonation(), impersonationHandler.Impersonate)
+
+		// 组织（租户）管理仅限超级管理员
+		orgAdmin := protected.Group("/org")
+		orgAdmin.Use(middleware.RoleRequired("ROLE_SUPER_ADMIN"))
+		{
+			orgAdmin.POST("", orgHandler.Create)
+			orgAdmin.GET("", orgHandler.List)
+			orgAdmin.GET("/:orgId", orgHandler.Get)
+			orgAdmin.PUT("/:orgId", orgHandler.Update)
+			orgAdmin.PUT("/:orgId/users/:userId", orgHandler.AssignUser)
 		}
 	}
 
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/pkg/jsonschema"
+)
+
+// attributeNamePattern 扩展属性名：字母开头，只包含字母、数字和下划线
+var attributeNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+
+// AttributeSchemaService 管理各租户的用户扩展属性定义，并按定义校验用户的扩展属性
+type AttributeSchemaService struct {
+	db *gorm.DB
+
+	mu    sync.RWMutex
+	cache map[int64]compiledSchema // 按租户缓存编译后的 schema，版本变化时重新编译
+}
+
+type compiledSchema struct {
+	version int
+	schema  *jsonschema.Schema
+}
+
+// NewAttributeSchemaService 创建并返回一个 AttributeSchemaService 实例
+func NewAttributeSchemaService(db *gorm.DB) *AttributeSchemaService {
+	return &AttributeSchemaService{db: db, cache: make(map[int64]compiledSchema)}
+}
+
+// Get 获取租户的扩展属性定义，tenantID 仅对超级管理员有效，为空时取当前用户所在租户
+func (s *AttributeSchemaService) Get(ctx context.Context, tenantID *int64) (*entity.AttributeSchema, error) {
+	def, err := s.find(ctx, attributeTenant(ctx, tenantID))
+	if err != nil {
+		return nil, err
+	}
+	if def == nil {
+		return nil, fmt.Errorf("尚未定义扩展属性")
+	}
+	return def, nil
+}
+
+// Save 保存租户的扩展属性定义，根节点必须是 object，属性名只能包含字母、数字和下划线。
+// 修改定义不会校验已有用户的数据，这些用户下次修改扩展属性时按新定义校验
+func (s *AttributeSchemaService) Save(ctx context.Context, tenant *int64, raw json.RawMessage) (*entity.AttributeSchema, error) {
+	tenantID := attributeTenant(ctx, tenant)
+	schema, err := jsonschema.Compile(raw)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Type != jsonschema.TypeObject {
+		return nil, fmt.Errorf("扩展属性 schema 的根节点 type 必须为 object")
+	}
+	for name := range schema.Properties {
+		if !attributeNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("属性名 %q 只能包含字母、数字和下划线，且以字母开头", name)
+		}
+	}
+
+	def, err := s.find(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	operator := currentOperator(ctx)
+	now := time.Now()
+	if def == nil {
+		def = &entity.AttributeSchema{TenantID: tenantID, Schema: string(raw), Version: 1, CreatedAt: now, UpdatedAt: now, CreatedBy: operator, UpdatedBy: operator}
+		if err := s.db.WithContext(ctx).Create(def).Error; err != nil {
+			return nil, err
+		}
+		return def, nil
+	}
+
+	version := def.Version
+	def.Schema = string(raw)
+	def.Version = version + 1
+	def.UpdatedAt = now
+	def.UpdatedBy = operator
+	res := s.db.WithContext(ctx).Model(def).
+		Where("version = ?", version).
+		Select("schema", "version", "updated_at", "updated_by").
+		Updates(def)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, fmt.Errorf("扩展属性定义已被修改，请刷新后重试")
+	}
+	return def, nil
+}
+
+// Delete 删除租户的扩展属性定义，之后该租户的用户不能再设置扩展属性，已有数据保留
+func (s *AttributeSchemaService) Delete(ctx context.Context, tenant *int64) error {
+	tenantID := attributeTenant(ctx, tenant)
+	if err := s.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Delete(&entity.AttributeSchema{}).Error; err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.cache, tenantID)
+	s.mu.Unlock()
+	return nil
+}
+
+// Validate 按租户的定义校验扩展属性，不满足时返回 *jsonschema.ValidationError；
+// 租户未定义扩展属性时只接受空属性
+func (s *AttributeSchemaService) Validate(ctx context.Context, tenantID int64, attrs entity.Attributes) error {
+	schema, err := s.compiled(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if schema == nil {
+		if len(attrs) > 0 {
+			return fmt.Errorf("所属组织未定义扩展属性")
+		}
+		return nil
+	}
+	if attrs == nil {
+		attrs = entity.Attributes{}
+	}
+	return schema.Validate(map[string]interface{}(attrs))
+}
+
+// compiled 返回租户编译后的 schema，未定义时返回 nil
+func (s *AttributeSchemaService) compiled(ctx context.Context, tenantID int64) (*jsonschema.Schema, error) {
+	def, err := s.find(ctx, tenantID)
+	if err != nil || def == nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	c, ok := s.cache[tenantID]
+	s.mu.RUnlock()
+	if ok && c.version == def.Version {
+		return c.schema, nil
+	}
+	schema, err := jsonschema.Compile([]byte(def.Schema))
+	if err != nil {
+		return nil, fmt.Errorf("扩展属性定义无效: %w", err)
+	}
+	s.mu.Lock()
+	s.cache[tenantID] = compiledSchema{version: def.Version, schema: schema}
+	s.mu.Unlock()
+	return schema, nil
+}
+
+func (s *AttributeSchemaService) find(ctx context.Context, tenantID int64) (*entity.AttributeSchema, error) {
+	var def entity.AttributeSchema
+	if err := s.db.WithContext(ctx).Where("tenant_id = ?", tenantID).First(&def).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询扩展属性定义失败: %w", err)
+	}
+	return &def, nil
+}
+
+// attributeTenant 确定要管理的租户：超级管理员可以指定租户，其他管理员只能管理自己所在的租户
+func attributeTenant(ctx context.Context, tenantID *int64) int64 {
+	if id, ok := tenantFilter(ctx); ok {
+		return id
+	}
+	if tenantID != nil {
+		return *tenantID
+	}
+	if claims := currentClaims(ctx); claims != nil {
+		return claims.TenantId
+	}
+	return entity.DefaultTenantID
+}
+
+// mergeAttributes 在原属性的副本上合并修改，值为 null 的属性被删除
+func mergeAttributes(current entity.Attributes, changes map[string]interface{}) entity.Attributes {
+	merged := make(entity.Attributes, len(current)+len(changes))
+	for k, v := range current {
+		merged[k] = v
+	}
+	for k, v := range changes {
+		if v == nil {
+			delete(merged, k)
+		} else {
+			merged[k] = v
+		}
+	}
+	return merged
+}
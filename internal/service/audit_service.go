@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/internal/model/request"
+	http2 "github.com/bryantaolong/system/pkg/http"
+	"github.com/bryantaolong/system/pkg/siem"
+)
+
+// auditMask 审计记录中敏感字段的掩码
+const auditMask = "******"
+
+// auditExportBatchSize 导出审计事件时每批读取的条数
+const auditExportBatchSize = 500
+
+// auditSkipFields 每次修改都会变化、不具备审计价值的字段
+var auditSkipFields = map[string]struct{}{
+	"CreatedAt": {},
+	"UpdatedAt": {},
+	"UpdatedBy": {},
+	"Version":   {},
+}
+
+// auditMaskFields 只记录“是否变化”、不记录具体值的字段
+var auditMaskFields = map[string]struct{}{
+	"Password": {},
+}
+
+// AuditChange 单个字段的前后变化
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditService 负责写入审计事件，事件与业务变更在同一事务中提交，提交成功后异步转发到 SIEM
+type AuditService struct {
+	db        *gorm.DB
+	forwarder *siem.Pipeline
+}
+
+// NewAuditService 创建并返回一个 AuditService 实例，forwarder 为空时不转发
+func NewAuditService(db *gorm.DB, forwarder *siem.Pipeline) *AuditService {
+	return &AuditService{db: db, forwarder: forwarder}
+}
+
+// Transaction 在同一事务中执行 fn 并写入 fn 内登记的审计事件，任一失败则整体回滚
+func (s *AuditService) Transaction(ctx context.Context, fn func(tx *gorm.DB, record func(*entity.AuditEvent)) error) error {
+	var events []*entity.AuditEvent
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		events = events[:0]
+		if err := fn(tx, func(e *entity.AuditEvent) { events = append(events, e) }); err != nil {
+			return err
+		}
+		for _, e := range events {
+			if err := tx.Create(e).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// 只转发已提交的事件，转发不阻塞请求
+	for _, e := range events {
+		s.forwarder.Publish(toSIEMEvent(e))
+	}
+	return nil
+}
+
+// SaveUser 保存用户并写入审计事件
+func (s *AuditService) SaveUser(ctx context.Context, user *entity.User, event *entity.AuditEvent) error {
+	return s.Transaction(ctx, func(tx *gorm.DB, record func(*entity.AuditEvent)) error {
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		record(event)
+		return nil
+	})
+}
+
+// Record 写入不伴随数据变更的审计事件（如登出、被拒绝的登录）
+func (s *AuditService) Record(ctx context.Context, event *entity.AuditEvent) error {
+	return s.Transaction(ctx, func(tx *gorm.DB, record func(*entity.AuditEvent)) error {
+		record(event)
+		return nil
+	})
+}
+
+// Search 按条件分页查询审计事件，租户管理员只能看到本租户的事件
+func (s *AuditService) Search(ctx context.Context, req request.AuditSearchRequest, page request.PageRequest) ([]entity.AuditEvent, int64, error) {
+	query, err := s.searchQuery(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var events []entity.AuditEvent
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("id DESC").
+		Limit(int(page.PageSize)).
+		Offset(int(page.GetOffset())).
+		Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// Export 按条件逐批读取审计事件并交给 fn 处理，用于流式导出，避免一次性加载全部数据
+func (s *AuditService) Export(ctx context.Context, req request.AuditSearchRequest, fn func(batch []entity.AuditEvent) error) error {
+	query, err := s.searchQuery(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	var batch []entity.AuditEvent
+	result := query.FindInBatches(&batch, auditExportBatchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	})
+	return result.Error
+}
+
+// searchQuery 构造审计事件查询
+func (s *AuditService) searchQuery(ctx context.Context, req request.AuditSearchRequest) (*gorm.DB, error) {
+	if !req.StartTime.IsZero() && !req.EndTime.IsZero() && req.EndTime.Before(req.StartTime) {
+		return nil, fmt.Errorf("时间范围不合法")
+	}
+
+	query := s.db.WithContext(ctx).Model(&entity.AuditEvent{}).Scopes(TenantScope(ctx, "tenant_id"))
+	if req.ActorId != nil {
+		query = query.Where("actor_id = ?", *req.ActorId)
+	}
+	if req.ActorName != "" {
+		query = query.Where("actor_name = ?", req.ActorName)
+	}
+	if req.TargetId != nil {
+		query = query.Where("target_id = ?", *req.TargetId)
+	}
+	if req.TargetName != "" {
+		query = query.Where("target_name = ?", req.TargetName)
+	}
+	if req.Action != "" {
+		if strings.HasSuffix(req.Action, ".") {
+			query = query.Where(`action LIKE ? ESCAPE '\'`, escapeLike(req.Action)+"%")
+		} else {
+			query = query.Where("action = ?", req.Action)
+		}
+	}
+	if req.Result != "" {
+		query = query.Where("result = ?", req.Result)
+	}
+	if req.IP != "" {
+		query = query.Where("ip = ?", req.IP)
+	}
+	if req.RequestId != "" {
+		query = query.Where("request_id = ?", req.RequestId)
+	}
+	if !req.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", req.StartTime)
+	}
+	if !req.EndTime.IsZero() {
+		query = query.Where("created_at <= ?", req.EndTime)
+	}
+	return query, nil
+}
+
+// likeEscaper 转义 LIKE 模式中的通配符，使输入按字面匹配
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike 转义 LIKE 通配符，配合 ESCAPE '\' 使用
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// toSIEMEvent 转换为转发给 SIEM 的事件
+func toSIEMEvent(e *entity.AuditEvent) siem.Event {
+	return siem.Event{
+		ID:           e.ID,
+		Time:         e.CreatedAt,
+		TenantID:     e.TenantID,
+		Action:       e.Action,
+		Result:       e.Result,
+		Reason:       e.Reason,
+		Severity:     auditSeverity(e),
+		ActorID:      e.ActorID,
+		ActorName:    e.ActorName,
+		Impersonator: e.Impersonator,
+		TargetID:     e.TargetID,
+		TargetName:   e.TargetName,
+		IP:           e.IP,
+		UserAgent:    e.UserAgent,
+		RequestID:    e.RequestID,
+		Changes:      e.Changes,
+	}
+}
+
+// auditSeverity 按 0-10 评估事件严重级别：账号锁定最高，其次是权限与凭据类变更和失败事件
+func auditSeverity(e *entity.AuditEvent) int {
+	switch e.Action {
+	case entity.AuditLockout, entity.AuditFailureBurst:
+		return 8
+	case entity.AuditSuspiciousLogin:
+		return 7
+	case entity.AuditUserRole, entity.AuditUserPasswordForce, entity.AuditUserDelete, entity.AuditUserBlock:
+		return 6
+	}
+	if e.Result == entity.AuditFailure {
+		return 5
+	}
+	return 3
+}
+
+// newAuditEvent 构造针对用户的审计事件：操作人取自当前登录用户，请求信息取自 gin.Context。
+// before 为空表示新建，after 为空表示无字段变化。
+func newAuditEvent(ctx context.Context, action string, before, after *entity.User) *entity.AuditEvent {
+	event := &entity.AuditEvent{
+		Action:    action,
+		Result:    entity.AuditSuccess,
+		CreatedAt: time.Now(),
+	}
+	if claims := currentClaims(ctx); claims != nil {
+		event.TenantID = claims.TenantId
+		event.ActorID, _ = strconv.ParseInt(claims.UserId, 10, 64)
+		event.ActorName = claims.Username
+		if claims.IsImpersonation() {
+			event.Impersonator = claims.Act.Username
+		}
+	}
+	if c, ok := ctx.(*gin.Context); ok {
+		fillAuditRequest(event, c.Request)
+	}
+	setAuditTarget(event, before, after)
+	return event
+}
+
+// newSelfAuditEvent 构造用户本人触发的认证类审计事件（注册、登录等），此时尚无登录态，操作人即用户本人
+func newSelfAuditEvent(r *http.Request, action string, before, after *entity.User) *entity.AuditEvent {
+	event := &entity.AuditEvent{
+		Action:    action,
+		Result:    entity.AuditSuccess,
+		CreatedAt: time.Now(),
+	}
+	fillAuditRequest(event, r)
+	setAuditTarget(event, before, after)
+	event.ActorID = event.TargetID
+	event.ActorName = event.TargetName
+	return event
+}
+
+// failAuditEvent 将事件标记为失败并记录原因
+func failAuditEvent(event *entity.AuditEvent, reason string) *entity.AuditEvent {
+	event.Result = entity.AuditFailure
+	event.Reason = reason
+	return event
+}
+
+// fillAuditRequest 记录请求来源
+func fillAuditRequest(event *entity.AuditEvent, r *http.Request) {
+	if r == nil {
+		return
+	}
+	event.IP = http2.GetClientIP(r)
+	event.UserAgent = r.UserAgent()
+	event.RequestID = http2.GetRequestID(r)
+}
+
+// setAuditTarget 记录目标用户及字段变化，事件租户以目标用户为准
+func setAuditTarget(event *entity.AuditEvent, before, after *entity.User) {
+	target := after
+	if target == nil {
+		target = before
+	}
+	if target == nil {
+		return
+	}
+	event.TenantID = target.TenantID
+	event.TargetID = target.ID
+	event.TargetName = target.Username
+	if after == nil {
+		return
+	}
+	if changes := diffUser(before, after); len(changes) > 0 {
+		if data, err := json.Marshal(changes); err == nil {
+			event.Changes = string(data)
+		}
+	}
+}
+
+// diffUser 逐字段比较用户变更，before 为空时视为与零值比较；密码只记录发生变化，不记录哈希值
+func diffUser(before, after *entity.User) map[string]AuditChange {
+	if before == nil {
+		before = &entity.User{}
+	}
+	return diffFields(before, after)
+}
+
+// diffFields 逐字段比较两个同类型的结构体指针，规则与 diffUser 相同
+func diffFields(before, after interface{}) map[string]AuditChange {
+	bv := reflect.ValueOf(before).Elem()
+	av := reflect.ValueOf(after).Elem()
+	t := bv.Type()
+
+	changes := make(map[string]AuditChange)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, skip := auditSkipFields[field.Name]; skip {
+			continue
+		}
+		b := auditValue(bv.Field(i))
+		a := auditValue(av.Field(i))
+		if reflect.DeepEqual(b, a) {
+			continue
+		}
+		if _, mask := auditMaskFields[field.Name]; mask {
+			b, a = auditMask, auditMask
+		}
+		changes[auditFieldName(field)] = AuditChange{Before: b, After: a}
+	}
+	return changes
+}
+
+// auditValue 将字段值转换为便于比较和序列化的形式
+func auditValue(v reflect.Value) interface{} {
+	switch val := v.Interface().(type) {
+	case sql.NullTime:
+		if !val.Valid {
+			return nil
+		}
+		return val.Time
+	default:
+		return val
+	}
+}
+
+// auditFieldName 优先使用 JSON 字段名，不序列化的字段使用首字母小写的字段名
+func auditFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		name = strings.ToLower(field.Name[:1]) + field.Name[1:]
+	}
+	return name
+}
+
+// requestFromContext 从 gin.Context 中取出原始请求，非 HTTP 调用时返回 nil
+func requestFromContext(ctx context.Context) *http.Request {
+	if c, ok := ctx.(*gin.Context); ok {
+		return c.Request
+	}
+	return nil
+}
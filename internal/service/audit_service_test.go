@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/internal/model/request"
+)
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"auth.", "auth."},
+		{"%", `\%`},
+		{"user_", `user\_`},
+		{`a\b`, `a\\b`},
+		{`%_\`, `\%\_\\`},
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.in); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, 期望 %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// 使用 DryRun 生成 SQL，不需要连接数据库
+func TestAuditSearchQueryAction(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewAuditService(db, nil)
+
+	tests := []struct {
+		name     string
+		action   string
+		wantSQL  string
+		wantVars []interface{}
+	}{
+		{"精确匹配", "auth.login", `SELECT * FROM "audit_event" WHERE action = $1`, []interface{}{"auth.login"}},
+		{"前缀匹配", "auth.", `SELECT * FROM "audit_event" WHERE action LIKE $1 ESCAPE '\'`, []interface{}{"auth.%"}},
+		{"通配符按字面匹配", "%_.", `SELECT * FROM "audit_event" WHERE action LIKE $1 ESCAPE '\'`, []interface{}{`\%\_.%`}},
+		{"不以 . 结尾时通配符也不生效", "%", `SELECT * FROM "audit_event" WHERE action = $1`, []interface{}{"%"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := s.searchQuery(SystemContext(context.Background()), request.AuditSearchRequest{Action: tt.action})
+			if err != nil {
+				t.Fatal(err)
+			}
+			stmt := query.Find(&[]entity.AuditEvent{}).Statement
+			if got := stmt.SQL.String(); got != tt.wantSQL {
+				t.Errorf("SQL = %s, 期望 %s", got, tt.wantSQL)
+			}
+			if len(stmt.Vars) != len(tt.wantVars) || stmt.Vars[0] != tt.wantVars[0] {
+				t.Errorf("参数 = %v, 期望 %v", stmt.Vars, tt.wantVars)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
 	"sync"
@@ -18,8 +19,8 @@ import (
 
 	"github.com/bryantaolong/system/internal/model/entity"
 	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/pkg/password"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -27,20 +28,151 @@ import (
 type AuthService struct {
 	db            *gorm.DB
 	redis         *redis.Client
+	audit         *AuditService
+	risk          *LoginRiskService
+	lockout       LockoutPolicy
+	passwords     *password.Policy
+	rotation      PasswordRotationPolicy
+	hasher        *password.Hasher
+	attributes    *AttributeSchemaService
 	defaultRole   string       // 缓存默认角色名
 	defaultRoleMu sync.RWMutex // 并发保护
 }
 
 // NewAuthService 创建并返回一个 AuthService 实例。
-func NewAuthService(db *gorm.DB, rdb *redis.Client) *AuthService {
+func NewAuthService(db *gorm.DB, rdb *redis.Client, audit *AuditService, risk *LoginRiskService, lockout LockoutPolicy, passwords *password.Policy, rotation PasswordRotationPolicy, hasher *password.Hasher, attributes *AttributeSchemaService) *AuthService {
 	return &AuthService{
-		db:    db,
-		redis: rdb,
+		db:         db,
+		redis:      rdb,
+		audit:      audit,
+		risk:       risk,
+		lockout:    lockout,
+		passwords:  passwords,
+		rotation:   rotation,
+		hasher:     hasher,
+		attributes: attributes,
 	}
 }
 
+// LoginResult 登录结果。需要修改密码时 Token 为只能调用修改密码接口的受限 Token
+type LoginResult struct {
+	Token                  string     `json:"token"`
+	PasswordChangeRequired bool       `json:"passwordChangeRequired"`
+	PasswordExpiresAt      *time.Time `json:"passwordExpiresAt,omitempty"`
+	Message                string     `json:"message,omitempty"` // 需要修改密码的原因或密码即将过期的提醒
+}
+
+// CheckPassword 按密码策略校验新密码，不满足时返回 *password.PolicyError
+func (s *AuthService) CheckPassword(ctx context.Context, pwd string, user *entity.User) error {
+	return s.passwords.Check(ctx, pwd, user.Username, user.Email, user.Phone)
+}
+
+// PasswordStrength 评估密码强度并列出不满足的策略，供前端实时提示
+func (s *AuthService) PasswordStrength(ctx context.Context, req request.PasswordCheckRequest) (password.Strength, []password.Violation) {
+	user := &entity.User{Username: req.Username, Email: req.Email, Phone: req.Phone}
+	var violations []password.Violation
+	var policyErr *password.PolicyError
+	if errors.As(s.CheckPassword(ctx, req.Password, user), &policyErr) {
+		violations = policyErr.Violations
+	}
+	return s.passwords.Strength(req.Password, user.Username, user.Email, user.Phone), violations
+}
+
 // Register 用户注册
 func (s *AuthService) Register(ctx context.Context, req request.RegisterRequest) (*entity.User, error) {
+	tenantID, err := s.resolveTenant(ctx, req.OrgCode)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.newUser(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+	if user.Password, err = s.hasher.Hash(req.Password); err != nil {
+		return nil, fmt.Errorf("密码加密失败")
+	}
+	user.CreatedBy = req.Username
+	user.UpdatedBy = req.Username
+
+	// 写入数据库，新用户 ID 生成后再记录审计事件
+	err = s.audit.Transaction(ctx, func(tx *gorm.DB, record func(*entity.AuditEvent)) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		record(newSelfAuditEvent(requestFromContext(ctx), entity.AuditRegister, nil, user))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ImportUser 管理员导入用户，校验规则与注册相同。未指定组织时归属操作人所在租户，
+// 租户管理员只能导入到本租户；导入的用户首次登录必须修改密码。dryRun 为 true 时只校验不写入
+func (s *AuthService) ImportUser(ctx context.Context, req request.RegisterRequest, dryRun bool) (*entity.User, error) {
+	var tenantID int64
+	var err error
+	filterID, filtered := tenantFilter(ctx)
+	if req.OrgCode == "" && filtered {
+		tenantID = filterID
+	} else if tenantID, err = s.resolveTenant(ctx, req.OrgCode); err != nil {
+		return nil, err
+	}
+	if filtered && tenantID != filterID {
+		return nil, fmt.Errorf("不能导入到其他组织")
+	}
+
+	user, err := s.newUser(ctx, tenantID, req)
+	if err != nil || dryRun {
+		return user, err
+	}
+	operator := currentOperator(ctx)
+	if operator == "" {
+		operator = "system"
+	}
+	if user.Password, err = s.hasher.Hash(req.Password); err != nil {
+		return nil, fmt.Errorf("密码加密失败")
+	}
+	user.MustChangePassword = true
+	user.CreatedBy = operator
+	user.UpdatedBy = operator
+
+	err = s.audit.Transaction(ctx, func(tx *gorm.DB, record func(*entity.AuditEvent)) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		record(newAuditEvent(ctx, entity.AuditUserImport, nil, user))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// resolveTenant 根据组织编码解析租户，未指定时归属默认租户
+func (s *AuthService) resolveTenant(ctx context.Context, orgCode string) (int64, error) {
+	if orgCode == "" {
+		return entity.DefaultTenantID, nil
+	}
+	var org entity.Organization
+	if err := s.db.WithContext(ctx).
+		Where("code = ? AND deleted = 0", orgCode).
+		First(&org).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("组织不存在")
+		}
+		return 0, fmt.Errorf("查询组织失败: %w", err)
+	}
+	if !org.IsEnabled() {
+		return 0, fmt.Errorf("组织已停用")
+	}
+	return org.ID, nil
+}
+
+// newUser 按注册规则校验用户名、密码和扩展属性，返回待写入的用户（尚未写入数据库，密码尚未加密）
+func (s *AuthService) newUser(ctx context.Context, tenantID int64, req request.RegisterRequest) (*entity.User, error) {
 	// 用户名唯一性检查
 	var cnt int64
 	if err := s.db.WithContext(ctx).
@@ -53,10 +185,12 @@ func (s *AuthService) Register(ctx context.Context, req request.RegisterRequest)
 		return nil, fmt.Errorf("用户名已存在")
 	}
 
-	// 密码加密
-	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, fmt.Errorf("密码加密失败")
+	if err := s.CheckPassword(ctx, req.Password, &entity.User{Username: req.Username, Email: req.Email, Phone: req.Phone}); err != nil {
+		return nil, err
+	}
+	attrs := entity.Attributes(req.Attributes)
+	if err := s.attributes.Validate(ctx, tenantID, attrs); err != nil {
+		return nil, err
 	}
 
 	// 查出默认角色
@@ -66,97 +200,192 @@ func (s *AuthService) Register(ctx context.Context, req request.RegisterRequest)
 	}
 
 	// 组装实体
-	now := sql.NullTime{Time: time.Now(), Valid: true}
-	user := &entity.User{
-		Username:  req.Username,
-		Password:  string(hashedPwd),
-		Email:     req.Email,
-		Phone:     req.Phone,
-		Roles:     defaultRole,
-		CreatedBy: req.Username,
-		UpdatedBy: req.Username,
-		UpdatedAt: now,
-	}
-
-	// 写入数据库
-	if err := s.db.WithContext(ctx).Create(user).Error; err != nil {
-		return nil, err
-	}
-	return user, nil
+	return &entity.User{
+		TenantID:   tenantID,
+		Username:   req.Username,
+		Email:      req.Email,
+		Phone:      req.Phone,
+		Roles:      defaultRole,
+		Attributes: attrs,
+		UpdatedAt:  sql.NullTime{Time: time.Now(), Valid: true},
+	}, nil
 }
 
-// Login 用户登录：验证密码、生成/复用 JWT、写 Redis、记录登录信息。
-func (s *AuthService) Login(loginReq request.LoginRequest, r *http.Request) (string, error) {
+// Login 用户登录：验证密码、生成 JWT、写 Redis、记录登录信息。
+func (s *AuthService) Login(loginReq request.LoginRequest, r *http.Request) (*LoginResult, error) {
 	var user entity.User
 	if err := s.db.Where("username = ?", loginReq.Username).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", fmt.Errorf("用户名或密码错误")
+			// 用户名不存在时没有目标用户，以尝试的用户名作为目标，便于发现针对不存在账号的猜测
+			event := failAuditEvent(newSelfAuditEvent(r, entity.AuditLoginFailed, nil, nil), "用户不存在")
+			event.TargetName = loginReq.Username
+			s.recordLoginAttempt(r, nil, event, newLoginLog(r, nil, loginReq.Username, entity.LoginUnknownUser, "用户不存在"))
+			return nil, fmt.Errorf("用户名或密码错误")
 		}
-		return "", fmt.Errorf("查询用户失败: %v", err)
+		return nil, fmt.Errorf("查询用户失败: %v", err)
+	}
+
+	before := user
+	now := time.Now()
+
+	// 锁定期间不校验密码，避免锁定期间仍可用于猜测密码
+	s.lockout.Refresh(&user, now)
+	if !user.IsAccountNonLocked() && user.Status == 2 {
+		s.recordLoginRejected(r, &user, entity.LoginLocked, "账号已被锁定")
+		return nil, fmt.Errorf("账号已被锁定，请于 %s 后再试", user.LockedUntil.Time.Format("2006-01-02 15:04:05"))
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginReq.Password)); err != nil {
-		user.LoginFailCount++
-		if user.LoginFailCount >= 5 {
-			user.Status = 2
-			user.LockedAt = sql.NullTime{Time: time.Now(), Valid: true}
-			s.db.Save(&user)
-			return "", fmt.Errorf("输入密码错误次数过多，账号锁定")
+	matched, rehash := user.CheckPassword(s.hasher, loginReq.Password)
+	if !matched {
+		if s.lockout.RegisterFailure(&user, now) {
+			event := failAuditEvent(newSelfAuditEvent(r, entity.AuditLockout, &before, &user), "连续输入密码错误")
+			if s.recordLoginAttempt(r, &user, event, newLoginLog(r, &user, user.Username, entity.LoginLocked, "连续输入密码错误，账号锁定")) == nil {
+				s.risk.NotifyLockout(&user)
+			}
+			return nil, fmt.Errorf("输入密码错误次数过多，账号锁定至 %s", user.LockedUntil.Time.Format("2006-01-02 15:04:05"))
 		}
-		s.db.Save(&user)
-		return "", fmt.Errorf("用户名或密码错误")
+		event := failAuditEvent(newSelfAuditEvent(r, entity.AuditLoginFailed, &before, &user), "密码错误")
+		s.recordLoginAttempt(r, &user, event, newLoginLog(r, &user, user.Username, entity.LoginBadPassword, "密码错误"))
+		return nil, fmt.Errorf("用户名或密码错误")
 	}
 
 	if user.Status == 1 {
-		return "", fmt.Errorf("账号已被封禁")
+		s.recordLoginRejected(r, &user, entity.LoginBanned, "账号已被封禁")
+		return nil, fmt.Errorf("账号已被封禁")
+	}
+
+	if user.TenantID != entity.DefaultTenantID {
+		var org entity.Organization
+		if err := s.db.First(&org, user.TenantID).Error; err != nil || !org.IsEnabled() {
+			s.recordLoginRejected(r, &user, entity.LoginOrgDisabled, "所属组织已停用")
+			return nil, fmt.Errorf("所属组织已停用")
+		}
 	}
 
-	if user.Status == 2 && user.LockedAt.Valid {
-		if time.Since(user.LockedAt.Time) < time.Hour {
-			return "", fmt.Errorf("账号已被锁定，请稍后再试")
+	// 哈希算法或参数已过时，借本次登录拿到的明文重新生成，随登录信息一起保存
+	if rehash {
+		if hashed, err := s.hasher.Hash(loginReq.Password); err != nil {
+			log.Printf("重新生成密码哈希失败: %v", err)
+		} else {
+			user.Password = hashed
 		}
-		user.Status = 0
-		user.LockedAt = sql.NullTime{Valid: false}
 	}
 
-	// 1. 先看 Redis 是否有未过期 token
-	existing, _ := s.redis.Get(context.Background(), user.Username).Result()
-	if existing != "" && jwt.ValidateToken(existing) {
-		_ = s.redis.Expire(context.Background(), user.Username, jwt.Expiration)
-		return existing, nil
+	// 密码过期或被管理员重置时只签发修改密码用的受限 Token，并顶替已有会话
+	if reason := s.rotation.ChangeReason(&user, now); reason != "" {
+		token, err := jwt.GeneratePasswordChangeToken(fmt.Sprint(user.ID), user.Username, user.TenantID)
+		if err != nil {
+			return nil, fmt.Errorf("生成Token失败: %v", err)
+		}
+		if err := s.redis.Set(context.Background(), user.Username, token, jwt.PasswordChangeExpiration).Err(); err != nil {
+			return nil, fmt.Errorf("Token存储失败: %v", err)
+		}
+		if err := s.recordLoginSuccess(r, &before, &user); err != nil {
+			return nil, err
+		}
+		return &LoginResult{Token: token, PasswordChangeRequired: true, Message: reason}, nil
 	}
+	result := &LoginResult{}
+	result.Message, result.PasswordExpiresAt = s.rotation.ExpiryWarning(&user, now)
 
-	// 2. 生成新 token
-	token, err := jwt.GenerateToken(fmt.Sprint(user.ID), user.Username, []string{user.Roles})
+	// 每次密码登录都签发新 Token：认证时间以本次登录为准，角色也按当前用户组重新计算；
+	// 会话按用户名存储，新 Token 顶替该用户已有的会话
+	token, err := s.issueToken(context.Background(), &user)
 	if err != nil {
-		return "", fmt.Errorf("生成Token失败: %v", err)
+		return nil, err
+	}
+	if err := s.recordLoginSuccess(r, &before, &user); err != nil {
+		return nil, err
 	}
+	result.Token = token
+	return result, nil
+}
 
-	// 3. 存 Redis
+// issueToken 计算有效角色（自身角色 ∪ 所在用户组角色），签发普通 Token 并存入 Redis
+func (s *AuthService) issueToken(ctx context.Context, user *entity.User) (string, error) {
+	roles, err := effectiveRoles(ctx, s.db, user)
+	if err != nil {
+		return "", fmt.Errorf("查询用户角色失败: %v", err)
+	}
+	token, err := jwt.GenerateToken(fmt.Sprint(user.ID), user.Username, user.TenantID, roles)
+	if err != nil {
+		return "", fmt.Errorf("生成Token失败: %v", err)
+	}
 	if err := s.redis.Set(context.Background(), user.Username, token, jwt.Expiration).Err(); err != nil {
 		return "", fmt.Errorf("Token存储失败: %v", err)
 	}
+	return token, nil
+}
 
+// recordLoginSuccess 更新最近登录信息并记录登录成功
+func (s *AuthService) recordLoginSuccess(r *http.Request, before, user *entity.User) error {
 	user.LastLoginAt = sql.NullTime{Time: time.Now(), Valid: true}
 	user.LastLoginIP = http2.GetClientIP(r)
 	user.LoginFailCount = 0
 	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	user.UpdatedBy = user.Username
 
-	if err := s.db.Save(&user).Error; err != nil {
-		return "", fmt.Errorf("更新用户信息失败: %v", err)
+	event := newSelfAuditEvent(r, entity.AuditLogin, before, user)
+	if err := s.recordLoginAttempt(r, user, event, newLoginLog(r, user, user.Username, entity.LoginSuccess, "")); err != nil {
+		return fmt.Errorf("更新用户信息失败: %v", err)
 	}
+	return nil
+}
 
-	token, err = jwt.GenerateToken(user.Username, user.Roles, []string{user.Roles})
-	if err != nil {
-		return "", fmt.Errorf("生成Token失败: %v", err)
+// recordLoginRejected 记录密码正确但因账号状态被拒绝的登录
+func (s *AuthService) recordLoginRejected(r *http.Request, user *entity.User, result, reason string) {
+	event := failAuditEvent(newSelfAuditEvent(r, entity.AuditLoginFailed, user, nil), reason)
+	s.recordLoginAttempt(r, nil, event, newLoginLog(r, user, user.Username, result, reason))
+}
+
+// recordLoginAttempt 在同一事务中保存用户状态（user 不为空时）、写入审计事件与登录记录，并进行可疑登录检测。
+// 登录失败的记录写入失败时只打日志，不改变返回给客户端的结果。
+func (s *AuthService) recordLoginAttempt(r *http.Request, user *entity.User, event *entity.AuditEvent, attempt *entity.LoginLog) error {
+	s.risk.Enrich(attempt)
+	if attempt.IsSuccess() {
+		s.risk.Assess(r.Context(), attempt)
+		if attempt.RiskFlags != "" && event != nil {
+			event.Reason = "可疑登录: " + attempt.RiskFlags
+		}
 	}
 
-	if err := s.redis.Set(context.Background(), user.Username, token, 24*time.Hour).Err(); err != nil {
-		return "", fmt.Errorf("Token存储失败: %v", err)
+	err := s.audit.Transaction(r.Context(), func(tx *gorm.DB, record func(*entity.AuditEvent)) error {
+		if user != nil {
+			if err := tx.Save(user).Error; err != nil {
+				return err
+			}
+		}
+		if event != nil {
+			record(event)
+		}
+		return tx.Create(attempt).Error
+	})
+	if err != nil {
+		log.Printf("记录登录信息失败: %v", err)
+		return err
 	}
+	s.risk.AfterAttempt(attempt)
+	return nil
+}
 
-	return token, nil
+// newLoginLog 根据请求构造登录记录，user 为空表示用户名不存在
+func newLoginLog(r *http.Request, user *entity.User, username, result, reason string) *entity.LoginLog {
+	entry := &entity.LoginLog{
+		Username:   username,
+		Result:     result,
+		FailReason: reason,
+		IP:         http2.GetClientIP(r),
+		OS:         http2.GetClientOS(r),
+		Browser:    http2.GetClientBrowser(r),
+		UserAgent:  r.UserAgent(),
+		RequestID:  http2.GetRequestID(r),
+		CreatedAt:  time.Now(),
+	}
+	if user != nil {
+		entry.TenantID = user.TenantID
+		entry.UserID = user.ID
+	}
+	return entry
 }
 
 // GetCurrentUser 根据 token 获取完整用户信息。
@@ -225,17 +454,87 @@ func (s *AuthService) RefreshToken(tokenString string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return jwt.GenerateToken(claims.UserId, claims.Username, claims.Roles)
+	if claims.IsImpersonation() {
+		return "", fmt.Errorf("模拟登录 Token 不支持刷新")
+	}
+	return jwt.ReissueToken(claims)
+}
+
+// Reauthenticate 校验当前用户密码并签发刷新了认证时间的新 Token，用于敏感操作前的二次验证。
+func (s *AuthService) Reauthenticate(ctx context.Context, tokenString string, req request.ReauthRequest) (string, error) {
+	claims, err := jwt.ParseToken(tokenString)
+	if err != nil {
+		return "", err
+	}
+	if claims.IsImpersonation() {
+		return "", fmt.Errorf("模拟登录状态下不能重新验证身份")
+	}
+
+	var user entity.User
+	if err := s.db.First(&user, claims.UserId).Error; err != nil {
+		return "", fmt.Errorf("查询用户失败: %v", err)
+	}
+	if !user.IsEnabled() {
+		return "", fmt.Errorf("账号已被封禁")
+	}
+
+	before := user
+	now := time.Now()
+	s.lockout.Refresh(&user, now)
+	if !user.IsAccountNonLocked() && user.Status == 2 {
+		return "", fmt.Errorf("账号已被锁定，请于 %s 后再试", user.LockedUntil.Time.Format("2006-01-02 15:04:05"))
+	}
+	if ok, _ := user.CheckPassword(s.hasher, req.Password); !ok {
+		// 与登录共用失败计数，持有被盗 Token 的人不能借重新验证无限次猜测密码
+		if s.lockout.RegisterFailure(&user, now) {
+			event := failAuditEvent(newAuditEvent(ctx, entity.AuditLockout, &before, &user), "重新验证时连续输入密码错误")
+			if err := s.audit.SaveUser(ctx, &user, event); err != nil {
+				log.Printf("记录账号锁定信息失败: %v", err)
+			} else {
+				s.risk.NotifyLockout(&user)
+			}
+			if err := s.redis.Del(context.Background(), jwt.SessionKey(claims)).Err(); err != nil {
+				log.Printf("删除用户会话失败: %v", err)
+			}
+			return "", fmt.Errorf("输入密码错误次数过多，账号锁定至 %s", user.LockedUntil.Time.Format("2006-01-02 15:04:05"))
+		}
+		if err := s.audit.SaveUser(ctx, &user, failAuditEvent(newAuditEvent(ctx, entity.AuditReauth, &before, &user), "密码错误")); err != nil {
+			log.Printf("记录重新验证失败信息失败: %v", err)
+		}
+		return "", fmt.Errorf("密码错误")
+	}
+
+	token, err := jwt.GenerateToken(claims.UserId, claims.Username, claims.TenantId, claims.Roles)
+	if err != nil {
+		return "", fmt.Errorf("生成Token失败: %v", err)
+	}
+	user.LoginFailCount = 0
+	if err := s.audit.SaveUser(ctx, &user, newAuditEvent(ctx, entity.AuditReauth, &before, &user)); err != nil {
+		return "", fmt.Errorf("记录审计事件失败: %v", err)
+	}
+	if err := s.redis.Set(context.Background(), jwt.SessionKey(claims), token, jwt.Expiration).Err(); err != nil {
+		return "", fmt.Errorf("Token存储失败: %v", err)
+	}
+	return token, nil
 }
 
 // Logout 删除 Redis 中的 token 实现登出。
-func (s *AuthService) Logout(tokenString string) error {
+func (s *AuthService) Logout(ctx context.Context, tokenString string) error {
 	claims, err := jwt.ParseToken(tokenString)
 	if err != nil {
 		return err
 	}
-	_, err = s.redis.Del(context.Background(), claims.Username).Result()
-	return err
+	if _, err = s.redis.Del(context.Background(), jwt.SessionKey(claims)).Result(); err != nil {
+		return err
+	}
+
+	event := newAuditEvent(ctx, entity.AuditLogout, nil, nil)
+	event.TargetID = event.ActorID
+	event.TargetName = event.ActorName
+	if err := s.audit.Record(ctx, event); err != nil {
+		log.Printf("记录登出审计事件失败: %v", err)
+	}
+	return nil
 }
 
 // getDefaultRole 保证只会在第一次调用时查库，之后直接读缓存
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	http2 "github.com/bryantaolong/system/pkg/http"
+	"github.com/bryantaolong/system/pkg/jwt"
+)
+
+// ImpersonationService 负责管理员模拟登录：签发短期 Token 并全程审计
+type ImpersonationService struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+// NewImpersonationService 创建并返回一个 ImpersonationService 实例。
+func NewImpersonationService(db *gorm.DB, rdb *redis.Client) *ImpersonationService {
+	return &ImpersonationService{db: db, redis: rdb}
+}
+
+// Start 以目标用户身份签发模拟登录 Token，Token 的 act 声明记录真实操作人
+func (s *ImpersonationService) Start(ctx context.Context, target *entity.User, reason string) (string, *jwt.CustomClaims, error) {
+	actor := currentClaims(ctx)
+	if actor == nil {
+		return "", nil, fmt.Errorf("无法识别当前操作人")
+	}
+	if actor.IsImpersonation() {
+		return "", nil, fmt.Errorf("模拟登录状态下不能再次模拟其他用户")
+	}
+	if actor.UserId == fmt.Sprint(target.ID) {
+		return "", nil, fmt.Errorf("不能模拟自己")
+	}
+	if !target.IsEnabled() {
+		return "", nil, fmt.Errorf("目标用户已被封禁或删除")
+	}
+
+	roles, err := effectiveRoles(ctx, s.db, target)
+	if err != nil {
+		return "", nil, err
+	}
+	// 只有超级管理员才能模拟管理员，防止借模拟登录提升权限
+	for _, r := range roles {
+		if (r == RoleAdmin || r == RoleSuperAdmin) && !isSuperAdmin(actor) {
+			return "", nil, fmt.Errorf("不能模拟管理员账号")
+		}
+	}
+
+	token, claims, err := jwt.GenerateImpersonationToken(fmt.Sprint(target.ID), target.Username, target.TenantID, roles,
+		jwt.Actor{UserId: actor.UserId, Username: actor.Username})
+	if err != nil {
+		return "", nil, fmt.Errorf("生成Token失败: %v", err)
+	}
+
+	key := jwt.SessionKey(claims)
+	if err := s.redis.Set(context.Background(), key, token, jwt.ImpersonationExpiration).Err(); err != nil {
+		return "", nil, fmt.Errorf("Token存储失败: %v", err)
+	}
+
+	// 审计记录写入失败时撤销 Token，保证每次模拟登录都有据可查
+	entry := s.newLog(ctx, claims, entity.ImpersonationStart)
+	entry.Reason = reason
+	if err := s.db.WithContext(ctx).Create(entry).Error; err != nil {
+		_ = s.redis.Del(context.Background(), key).Err()
+		return "", nil, fmt.Errorf("记录模拟登录审计失败: %v", err)
+	}
+	return token, claims, nil
+}
+
+// RecordImpersonatedRequest 记录模拟登录期间发起的请求，实现 middleware.ImpersonationRecorder
+func (s *ImpersonationService) RecordImpersonatedRequest(c *gin.Context, claims *jwt.CustomClaims) {
+	entry := s.newLog(c, claims, entity.ImpersonationRequest)
+	entry.StatusCode = c.Writer.Status()
+	if err := s.db.WithContext(c.Request.Context()).Create(entry).Error; err != nil {
+		log.Printf("记录模拟登录请求失败: %v", err)
+	}
+}
+
+// newLog 根据 Token 与当前请求构造审计记录
+func (s *ImpersonationService) newLog(ctx context.Context, claims *jwt.CustomClaims, action string) *entity.ImpersonationLog {
+	entry := &entity.ImpersonationLog{
+		TenantID:   claims.TenantId,
+		ActorID:    claims.Act.UserId,
+		ActorName:  claims.Act.Username,
+		TargetID:   claims.UserId,
+		TargetName: claims.Username,
+		TokenID:    claims.ID,
+		Action:     action,
+		CreatedAt:  time.Now(),
+	}
+	if c, ok := ctx.(*gin.Context); ok && c.Request != nil {
+		entry.Method = c.Request.Method
+		entry.Path = c.Request.URL.Path
+		entry.IP = http2.GetClientIP(c.Request)
+		entry.UserAgent = c.Request.UserAgent()
+	}
+	return entry
+}
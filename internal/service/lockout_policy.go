@@ -0,0 +1,62 @@
+package service
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+)
+
+// LockoutPolicy 账号锁定策略：窗口内连续失败达到阈值即锁定，
+// 每次再被锁定时长翻倍直至上限，长时间没有失败后锁定次数清零。
+type LockoutPolicy struct {
+	Threshold    int           // 触发锁定的失败次数
+	Window       time.Duration // 失败次数的统计窗口，距上次失败超过该时长重新计数
+	BaseDuration time.Duration // 首次锁定时长
+	MaxDuration  time.Duration // 锁定时长上限
+	ResetAfter   time.Duration // 距上次失败超过该时长后锁定次数清零
+}
+
+// Refresh 登录前整理锁定状态：锁定到期自动解除，安静期过后清零锁定次数
+func (p LockoutPolicy) Refresh(u *entity.User, now time.Time) {
+	if u.Status == 2 && u.IsAccountNonLocked() {
+		u.Status = 0
+		u.LockedAt = sql.NullTime{}
+		u.LockedUntil = sql.NullTime{}
+	}
+	if u.LastLoginFailAt.Valid && now.Sub(u.LastLoginFailAt.Time) > p.ResetAfter {
+		u.LockCount = 0
+		u.LoginFailCount = 0
+	}
+}
+
+// RegisterFailure 记录一次登录失败，达到阈值时锁定账号并返回 true
+func (p LockoutPolicy) RegisterFailure(u *entity.User, now time.Time) bool {
+	if u.LastLoginFailAt.Valid && now.Sub(u.LastLoginFailAt.Time) > p.Window {
+		u.LoginFailCount = 0
+	}
+	u.LoginFailCount++
+	u.LastLoginFailAt = sql.NullTime{Time: now, Valid: true}
+	if p.Threshold <= 0 || u.LoginFailCount < p.Threshold {
+		return false
+	}
+
+	u.LockCount++
+	u.Status = 2
+	u.LoginFailCount = 0
+	u.LockedAt = sql.NullTime{Time: now, Valid: true}
+	u.LockedUntil = sql.NullTime{Time: now.Add(p.LockDuration(u.LockCount)), Valid: true}
+	return true
+}
+
+// LockDuration 返回第 n 次锁定的时长：BaseDuration × 2^(n-1)，不超过 MaxDuration
+func (p LockoutPolicy) LockDuration(n int) time.Duration {
+	d := p.BaseDuration
+	for i := 1; i < n && d < p.MaxDuration; i++ {
+		d *= 2
+	}
+	if p.MaxDuration > 0 && d > p.MaxDuration {
+		d = p.MaxDuration
+	}
+	return d
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+)
+
+var testLockout = LockoutPolicy{
+	Threshold:    3,
+	Window:       15 * time.Minute,
+	BaseDuration: 5 * time.Minute,
+	MaxDuration:  time.Hour,
+	ResetAfter:   24 * time.Hour,
+}
+
+func TestLockoutPolicyLockDuration(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy LockoutPolicy
+		n      int
+		want   time.Duration
+	}{
+		{"首次锁定", testLockout, 1, 5 * time.Minute},
+		{"第二次翻倍", testLockout, 2, 10 * time.Minute},
+		{"第四次", testLockout, 4, 40 * time.Minute},
+		{"第五次达到上限", testLockout, 5, time.Hour},
+		{"远超上限", testLockout, 100, time.Hour},
+		{"上限不是基础时长的整数倍", LockoutPolicy{BaseDuration: 7 * time.Minute, MaxDuration: 30 * time.Minute}, 4, 30 * time.Minute},
+		{"未设置上限时不封顶", LockoutPolicy{BaseDuration: time.Minute}, 4, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.LockDuration(tt.n); got != tt.want {
+				t.Errorf("LockDuration(%d) = %v, 期望 %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockoutPolicyRegisterFailure(t *testing.T) {
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	failedAt := func(d time.Duration) sql.NullTime { return sql.NullTime{Time: now.Add(-d), Valid: true} }
+
+	tests := []struct {
+		name         string
+		user         entity.User
+		policy       LockoutPolicy
+		wantLocked   bool
+		wantFails    int
+		wantLocks    int
+		wantDuration time.Duration
+	}{
+		{"首次失败", entity.User{}, testLockout, false, 1, 0, 0},
+		{"窗口内累计", entity.User{LoginFailCount: 1, LastLoginFailAt: failedAt(time.Minute)}, testLockout, false, 2, 0, 0},
+		{"窗口外重新计数", entity.User{LoginFailCount: 2, LastLoginFailAt: failedAt(16 * time.Minute)}, testLockout, false, 1, 0, 0},
+		{"达到阈值锁定", entity.User{LoginFailCount: 2, LastLoginFailAt: failedAt(time.Minute)}, testLockout, true, 0, 1, 5 * time.Minute},
+		{"再次锁定时长翻倍", entity.User{LoginFailCount: 2, LockCount: 2, LastLoginFailAt: failedAt(time.Minute)}, testLockout, true, 0, 3, 20 * time.Minute},
+		{"锁定时长不超过上限", entity.User{LoginFailCount: 2, LockCount: 9, LastLoginFailAt: failedAt(time.Minute)}, testLockout, true, 0, 10, time.Hour},
+		{"阈值为 0 时不锁定", entity.User{LoginFailCount: 99, LastLoginFailAt: failedAt(time.Minute)}, LockoutPolicy{Window: time.Hour}, false, 100, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := tt.user
+			locked := tt.policy.RegisterFailure(&u, now)
+			if locked != tt.wantLocked {
+				t.Errorf("RegisterFailure = %v, 期望 %v", locked, tt.wantLocked)
+			}
+			if u.LoginFailCount != tt.wantFails || u.LockCount != tt.wantLocks {
+				t.Errorf("失败次数 = %d, 锁定次数 = %d, 期望 %d, %d", u.LoginFailCount, u.LockCount, tt.wantFails, tt.wantLocks)
+			}
+			if !u.LastLoginFailAt.Valid || !u.LastLoginFailAt.Time.Equal(now) {
+				t.Errorf("LastLoginFailAt = %v, 期望 %v", u.LastLoginFailAt, now)
+			}
+			if !tt.wantLocked {
+				if u.Status != tt.user.Status || u.LockedUntil.Valid {
+					t.Errorf("未锁定时状态 = %d, LockedUntil = %v", u.Status, u.LockedUntil)
+				}
+				return
+			}
+			if u.Status != 2 || !u.LockedAt.Time.Equal(now) {
+				t.Errorf("状态 = %d, LockedAt = %v, 期望锁定于 %v", u.Status, u.LockedAt, now)
+			}
+			if got := u.LockedUntil.Time.Sub(now); !u.LockedUntil.Valid || got != tt.wantDuration {
+				t.Errorf("锁定时长 = %v, 期望 %v", got, tt.wantDuration)
+			}
+		})
+	}
+}
+
+func TestLockoutPolicyRefresh(t *testing.T) {
+	// IsAccountNonLocked 以当前时间判断锁定是否到期，这里也使用当前时间
+	now := time.Now()
+	at := func(d time.Duration) sql.NullTime { return sql.NullTime{Time: now.Add(d), Valid: true} }
+
+	tests := []struct {
+		name       string
+		user       entity.User
+		wantStatus int
+		wantFails  int
+		wantLocks  int
+	}{
+		{"锁定到期自动解除", entity.User{Status: 2, LockCount: 1, LockedAt: at(-10 * time.Minute), LockedUntil: at(-time.Minute), LastLoginFailAt: at(-10 * time.Minute)}, 0, 0, 1},
+		{"锁定未到期保持", entity.User{Status: 2, LockCount: 1, LockedAt: at(-time.Minute), LockedUntil: at(time.Minute), LastLoginFailAt: at(-time.Minute)}, 2, 0, 1},
+		{"安静期内保留计数", entity.User{LoginFailCount: 2, LockCount: 3, LastLoginFailAt: at(-23 * time.Hour)}, 0, 2, 3},
+		{"超过安静期清零", entity.User{LoginFailCount: 2, LockCount: 3, LastLoginFailAt: at(-25 * time.Hour)}, 0, 0, 0},
+		{"封禁状态不受影响", entity.User{Status: 1, LockCount: 3, LastLoginFailAt: at(-time.Hour)}, 1, 0, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := tt.user
+			testLockout.Refresh(&u, now)
+			if u.Status != tt.wantStatus || u.LoginFailCount != tt.wantFails || u.LockCount != tt.wantLocks {
+				t.Errorf("状态 = %d, 失败次数 = %d, 锁定次数 = %d, 期望 %d, %d, %d",
+					u.Status, u.LoginFailCount, u.LockCount, tt.wantStatus, tt.wantFails, tt.wantLocks)
+			}
+			if u.Status == 0 && (u.LockedAt.Valid || u.LockedUntil.Valid) {
+				t.Errorf("解除锁定后 LockedAt = %v, LockedUntil = %v, 期望清空", u.LockedAt, u.LockedUntil)
+			}
+		})
+	}
+}
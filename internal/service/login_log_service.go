@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/internal/model/request"
+)
+
+// LoginLogService 负责查询登录记录
+type LoginLogService struct {
+	db *gorm.DB
+}
+
+// NewLoginLogService 创建并返回一个 LoginLogService 实例。
+func NewLoginLogService(db *gorm.DB) *LoginLogService {
+	return &LoginLogService{db: db}
+}
+
+// ListByUser 分页查询指定用户的登录记录，租户管理员只能查询本租户用户
+func (s *LoginLogService) ListByUser(ctx context.Context, userID int64, page request.PageRequest) ([]entity.LoginLog, int64, error) {
+	var user entity.User
+	if err := s.db.WithContext(ctx).Scopes(TenantScope(ctx, "tenant_id")).First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, fmt.Errorf("用户不存在")
+		}
+		return nil, 0, err
+	}
+	return s.list(ctx, user.ID, page)
+}
+
+// ListMine 分页查询当前登录用户自己的登录记录
+func (s *LoginLogService) ListMine(ctx context.Context, page request.PageRequest) ([]entity.LoginLog, int64, error) {
+	claims := currentClaims(ctx)
+	if claims == nil {
+		return nil, 0, fmt.Errorf("无法识别当前用户")
+	}
+	var user entity.User
+	if err := s.db.WithContext(ctx).First(&user, claims.UserId).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询用户失败: %v", err)
+	}
+	return s.list(ctx, user.ID, page)
+}
+
+// list 按时间倒序分页查询登录记录
+func (s *LoginLogService) list(ctx context.Context, userID int64, page request.PageRequest) ([]entity.LoginLog, int64, error) {
+	var logs []entity.LoginLog
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&entity.LoginLog{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("id DESC").
+		Limit(int(page.PageSize)).
+		Offset(int(page.GetOffset())).
+		Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/pkg/geoip"
+	"github.com/bryantaolong/system/pkg/notify"
+)
+
+const (
+	// riskHistoryLimit 判断新设备、新网段时参考的最近成功登录次数
+	riskHistoryLimit = 200
+	// impossibleTravelSpeed 超过该速度（千米/小时）的位移视为不可能
+	impossibleTravelSpeed = 900.0
+	// impossibleTravelMinDistance 距离过近时 GeoIP 误差较大，不做判断
+	impossibleTravelMinDistance = 500.0
+	// failureBurstKeyPrefix 批量失败告警的去重 key 前缀，同一 IP 在统计窗口内只告警一次
+	failureBurstKeyPrefix = "login:burst:"
+)
+
+// LoginRiskConfig 可疑登录检测参数
+type LoginRiskConfig struct {
+	FailureBurstThreshold int           // 同一 IP 在窗口内登录失败的不同账号数达到该值时告警
+	FailureBurstWindow    time.Duration // 统计窗口
+}
+
+// LoginRiskService 根据登录历史识别可疑登录：新设备、新 IP 网段、不可能的位移以及撞库式的批量失败，
+// 命中时写入安全审计事件并通知用户
+type LoginRiskService struct {
+	db       *gorm.DB
+	geo      *geoip.DB
+	notifier notify.Notifier
+	audit    *AuditService
+	rdb      *redis.Client
+	cfg      LoginRiskConfig
+}
+
+// NewLoginRiskService 创建并返回一个 LoginRiskService 实例，geo 为空时不做位置相关的检测
+func NewLoginRiskService(db *gorm.DB, geo *geoip.DB, notifier notify.Notifier, audit *AuditService, rdb *redis.Client, cfg LoginRiskConfig) *LoginRiskService {
+	if notifier == nil {
+		notifier = notify.LogNotifier{}
+	}
+	return &LoginRiskService{db: db, geo: geo, notifier: notifier, audit: audit, rdb: rdb, cfg: cfg}
+}
+
+// Enrich 补充登录记录的设备指纹与地理位置
+func (s *LoginRiskService) Enrich(attempt *entity.LoginLog) {
+	attempt.Device = deviceFingerprint(attempt.OS, attempt.Browser)
+	if loc, ok := s.geo.Lookup(attempt.IP); ok {
+		attempt.Location = loc.String()
+	}
+}
+
+// Assess 对成功的登录与历史记录比较并设置风险标记，须在本次记录写入前调用。
+// 首次登录没有可比较的历史，不做标记。
+func (s *LoginRiskService) Assess(ctx context.Context, attempt *entity.LoginLog) {
+	var history []entity.LoginLog
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ? AND result = ?", attempt.UserID, entity.LoginSuccess).
+		Order("id DESC").
+		Limit(riskHistoryLimit).
+		Find(&history).Error; err != nil {
+		log.Printf("查询登录历史失败: %v", err)
+		return
+	}
+	attempt.RiskFlags = s.assessHistory(history, attempt)
+}
+
+// assessHistory 将本次登录与按时间倒序排列的成功登录历史比较，返回逗号分隔的风险标记
+func (s *LoginRiskService) assessHistory(history []entity.LoginLog, attempt *entity.LoginLog) string {
+	if len(history) == 0 {
+		return ""
+	}
+
+	var flags []string
+	knownDevice, knownRange := false, false
+	hasDevice := false // 早期记录没有设备指纹，不参与新设备判断
+	ipRange := ipRangeOf(attempt.IP)
+	for _, h := range history {
+		if h.Device != "" {
+			hasDevice = true
+		}
+		if h.Device == attempt.Device {
+			knownDevice = true
+		}
+		if ipRange != "" && ipRangeOf(h.IP) == ipRange {
+			knownRange = true
+		}
+	}
+	if hasDevice && !knownDevice {
+		flags = append(flags, entity.RiskNewDevice)
+	}
+	if ipRange != "" && !knownRange {
+		flags = append(flags, entity.RiskNewIPRange)
+	}
+	if s.impossibleTravel(&history[0], attempt) {
+		flags = append(flags, entity.RiskImpossibleTravel)
+	}
+	return strings.Join(flags, ",")
+}
+
+// AfterAttempt 在登录记录提交后异步处理告警：成功登录带风险标记时通知用户，失败时检测批量失败
+func (s *LoginRiskService) AfterAttempt(attempt *entity.LoginLog) {
+	go func() {
+		ctx := context.Background()
+		if !attempt.IsSuccess() {
+			s.detectFailureBurst(ctx, attempt)
+			return
+		}
+		if attempt.RiskFlags == "" {
+			return
+		}
+		var user entity.User
+		if err := s.db.WithContext(ctx).First(&user, attempt.UserID).Error; err != nil {
+			log.Printf("查询用户失败: %v", err)
+			return
+		}
+		s.alertSuspiciousLogin(ctx, &user, attempt)
+	}()
+}
+
+// NotifyLockout 异步通知用户账号因连续登录失败被锁定
+func (s *LoginRiskService) NotifyLockout(user *entity.User) {
+	msg := notify.Message{
+		To:      user.Email,
+		Subject: "账号锁定提醒",
+		Body: fmt.Sprintf("您的账号 %s 因连续输入密码错误已被锁定，将于 %s 自动解锁。\n\n如非本人操作，请在解锁后立即修改密码或联系管理员。",
+			user.Username, user.LockedUntil.Time.Format("2006-01-02 15:04:05")),
+	}
+	go func() {
+		if err := s.notifier.Notify(context.Background(), msg); err != nil {
+			log.Printf("发送锁定提醒失败: %v", err)
+		}
+	}()
+}
+
+// impossibleTravel 判断与上次成功登录相比，两地距离在间隔时间内是否无法到达
+func (s *LoginRiskService) impossibleTravel(last, attempt *entity.LoginLog) bool {
+	if last.IP == attempt.IP {
+		return false
+	}
+	from, ok1 := s.geo.Lookup(last.IP)
+	to, ok2 := s.geo.Lookup(attempt.IP)
+	if !ok1 || !ok2 {
+		return false
+	}
+	distance := geoip.Distance(from, to)
+	if distance < impossibleTravelMinDistance {
+		return false
+	}
+	hours := attempt.CreatedAt.Sub(last.CreatedAt).Hours()
+	return hours <= 0 || distance/hours > impossibleTravelSpeed
+}
+
+// alertSuspiciousLogin 记录可疑登录事件并通知用户
+func (s *LoginRiskService) alertSuspiciousLogin(ctx context.Context, user *entity.User, attempt *entity.LoginLog) {
+	event := newSelfAuditEvent(nil, entity.AuditSuspiciousLogin, user, nil)
+	event.Reason = attempt.RiskFlags
+	s.fillFromAttempt(event, attempt)
+	if err := s.audit.Record(ctx, event); err != nil {
+		log.Printf("记录可疑登录事件失败: %v", err)
+	}
+	if err := s.notifier.Notify(ctx, suspiciousLoginMessage(user, attempt)); err != nil {
+		log.Printf("发送登录提醒失败: %v", err)
+	}
+}
+
+// suspiciousLoginMessage 生成可疑登录的用户提醒
+func suspiciousLoginMessage(user *entity.User, attempt *entity.LoginLog) notify.Message {
+	body := fmt.Sprintf("您的账号 %s 于 %s 登录，检测到异常：%s\n\nIP：%s\n位置：%s\n设备：%s / %s\n\n如非本人操作，请立即修改密码。",
+		user.Username,
+		attempt.CreatedAt.Format("2006-01-02 15:04:05"),
+		describeRiskFlags(attempt.RiskFlags),
+		attempt.IP, attempt.Location, attempt.OS, attempt.Browser)
+	return notify.Message{To: user.Email, Subject: "账号登录安全提醒", Body: body}
+}
+
+// detectFailureBurst 统计同一 IP 在窗口内登录失败的不同账号数，刚达到阈值时告警一次
+func (s *LoginRiskService) detectFailureBurst(ctx context.Context, attempt *entity.LoginLog) {
+	if s.cfg.FailureBurstThreshold <= 0 || attempt.IP == "" {
+		return
+	}
+	var accounts int64
+	if err := s.db.WithContext(ctx).
+		Model(&entity.LoginLog{}).
+		Where("ip = ? AND result <> ? AND created_at >= ?", attempt.IP, entity.LoginSuccess, time.Now().Add(-s.cfg.FailureBurstWindow)).
+		Distinct("username").
+		Count(&accounts).Error; err != nil {
+		log.Printf("统计登录失败次数失败: %v", err)
+		return
+	}
+	if !s.failureBurstReached(accounts) {
+		return
+	}
+	// 并发的失败登录可能同时越过阈值，通过 Redis 去重保证窗口内只告警一次
+	first, err := s.rdb.SetNX(ctx, failureBurstKeyPrefix+attempt.IP, accounts, s.cfg.FailureBurstWindow).Result()
+	if err != nil {
+		log.Printf("批量登录失败告警去重失败: %v", err)
+		return
+	}
+	if !first {
+		return
+	}
+
+	if err := s.db.WithContext(ctx).Model(attempt).Update("risk_flags", entity.RiskFailureBurst).Error; err != nil {
+		log.Printf("更新登录记录风险标记失败: %v", err)
+	}
+	event := newSelfAuditEvent(nil, entity.AuditFailureBurst, nil, nil)
+	event.Result = entity.AuditFailure
+	event.Reason = fmt.Sprintf("%s 内有 %d 个账号从该 IP 登录失败", s.cfg.FailureBurstWindow, accounts)
+	s.fillFromAttempt(event, attempt)
+	if err := s.audit.Record(ctx, event); err != nil {
+		log.Printf("记录批量登录失败事件失败: %v", err)
+	}
+}
+
+// failureBurstReached 判断窗口内登录失败的不同账号数是否达到告警阈值，阈值不大于 0 时不告警
+func (s *LoginRiskService) failureBurstReached(accounts int64) bool {
+	return s.cfg.FailureBurstThreshold > 0 && accounts >= int64(s.cfg.FailureBurstThreshold)
+}
+
+// fillFromAttempt 使用登录记录中的请求信息填充审计事件
+func (s *LoginRiskService) fillFromAttempt(event *entity.AuditEvent, attempt *entity.LoginLog) {
+	event.IP = attempt.IP
+	event.UserAgent = attempt.UserAgent
+	event.RequestID = attempt.RequestID
+}
+
+// deviceFingerprint 由操作系统与浏览器计算设备指纹，不包含版本号，避免浏览器升级被识别为新设备
+func deviceFingerprint(os, browser string) string {
+	sum := sha256.Sum256([]byte(os + "|" + browser))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ipRangeOf 返回 IP 所在网段：IPv4 取 /24，IPv6 取 /48，IP 不合法时返回空字符串
+func ipRangeOf(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.String()
+}
+
+// describeRiskFlags 将风险标记转换为可读描述
+func describeRiskFlags(flags string) string {
+	names := map[string]string{
+		entity.RiskNewDevice:        "新设备",
+		entity.RiskNewIPRange:       "新的网络",
+		entity.RiskImpossibleTravel: "登录地点与上次相距过远",
+	}
+	parts := strings.Split(flags, ",")
+	for i, f := range parts {
+		if name, ok := names[f]; ok {
+			parts[i] = name
+		}
+	}
+	return strings.Join(parts, "、")
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/pkg/geoip"
+	"github.com/bryantaolong/system/pkg/notify"
+)
+
+const testGeoCSV = `network,country,city,latitude,longitude
+1.0.1.0/24,CN,Fuzhou,26.0614,119.3061
+1.0.2.0/24,CN,Xiamen,24.4798,118.0894
+8.8.8.0/24,US,Mountain View,37.4056,-122.0775
+`
+
+// fakeNotifier 将通知写入 channel，便于等待异步发送
+type fakeNotifier struct {
+	sent chan notify.Message
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, msg notify.Message) error {
+	n.sent <- msg
+	return nil
+}
+
+func newTestRiskService(t *testing.T, cfg LoginRiskConfig) (*LoginRiskService, *fakeNotifier) {
+	t.Helper()
+	geo, err := geoip.Load(strings.NewReader(testGeoCSV))
+	if err != nil {
+		t.Fatalf("geoip.Load 返回错误: %v", err)
+	}
+	notifier := &fakeNotifier{sent: make(chan notify.Message, 1)}
+	return NewLoginRiskService(nil, geo, notifier, nil, nil, cfg), notifier
+}
+
+func TestLoginRiskAssessHistory(t *testing.T) {
+	s, _ := newTestRiskService(t, LoginRiskConfig{})
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	chrome := deviceFingerprint("Windows", "Chrome")
+	firefox := deviceFingerprint("Linux", "Firefox")
+	login := func(ip, device string, ago time.Duration) entity.LoginLog {
+		return entity.LoginLog{IP: ip, Device: device, Result: entity.LoginSuccess, CreatedAt: now.Add(-ago)}
+	}
+
+	tests := []struct {
+		name    string
+		history []entity.LoginLog
+		attempt entity.LoginLog
+		want    string
+	}{
+		{"首次登录不标记", nil, login("8.8.8.8", chrome, 0), ""},
+		{"熟悉的设备与网段", []entity.LoginLog{login("1.0.1.7", chrome, time.Hour)}, login("1.0.1.9", chrome, 0), ""},
+		{"新设备", []entity.LoginLog{login("1.0.1.7", chrome, time.Hour)}, login("1.0.1.9", firefox, 0), entity.RiskNewDevice},
+		{"历史记录没有设备指纹时不判断新设备", []entity.LoginLog{login("1.0.1.7", "", time.Hour)}, login("1.0.1.9", firefox, 0), ""},
+		{"同城附近的新网段", []entity.LoginLog{login("1.0.1.7", chrome, time.Hour)}, login("1.0.2.9", chrome, 0), entity.RiskNewIPRange},
+		{"较早用过的网段不算新网段", []entity.LoginLog{login("1.0.2.1", chrome, time.Hour), login("1.0.1.7", chrome, 48*time.Hour)}, login("1.0.1.9", chrome, 0), ""},
+		{
+			name:    "一小时内换到其他国家",
+			history: []entity.LoginLog{login("1.0.1.7", chrome, time.Hour)},
+			attempt: login("8.8.8.8", firefox, 0),
+			want:    entity.RiskNewDevice + "," + entity.RiskNewIPRange + "," + entity.RiskImpossibleTravel,
+		},
+		{"间隔足够长时跨国登录不算不可能位移", []entity.LoginLog{login("1.0.1.7", chrome, 24*time.Hour)}, login("8.8.8.8", chrome, 0), entity.RiskNewIPRange},
+		{"只和最近一次登录比较位移", []entity.LoginLog{login("8.8.8.1", chrome, time.Hour), login("1.0.1.7", chrome, 2*time.Hour)}, login("8.8.8.8", chrome, 0), ""},
+		{"未收录的 IP 不判断位移", []entity.LoginLog{login("1.0.1.7", chrome, time.Minute)}, login("9.9.9.9", chrome, 0), entity.RiskNewIPRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.assessHistory(tt.history, &tt.attempt); got != tt.want {
+				t.Errorf("assessHistory = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginRiskFailureBurstReached(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		accounts  int64
+		want      bool
+	}{
+		{"低于阈值", 5, 4, false},
+		{"恰好达到阈值", 5, 5, true},
+		{"超过阈值", 5, 6, true},
+		{"阈值为 0 时不告警", 0, 100, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestRiskService(t, LoginRiskConfig{FailureBurstThreshold: tt.threshold, FailureBurstWindow: time.Minute})
+			if got := s.failureBurstReached(tt.accounts); got != tt.want {
+				t.Errorf("failureBurstReached(%d) = %v, 期望 %v", tt.accounts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginRiskEnrich(t *testing.T) {
+	s, _ := newTestRiskService(t, LoginRiskConfig{})
+	attempt := entity.LoginLog{IP: "8.8.8.8", OS: "Windows", Browser: "Chrome"}
+	s.Enrich(&attempt)
+	if attempt.Location != "US Mountain View" {
+		t.Errorf("Location = %q, 期望 US Mountain View", attempt.Location)
+	}
+	if attempt.Device != deviceFingerprint("Windows", "Chrome") || attempt.Device == deviceFingerprint("Linux", "Chrome") {
+		t.Errorf("Device = %q", attempt.Device)
+	}
+}
+
+func TestSuspiciousLoginMessage(t *testing.T) {
+	user := &entity.User{Username: "alice", Email: "alice@example.com"}
+	attempt := &entity.LoginLog{
+		IP:        "8.8.8.8",
+		Location:  "US Mountain View",
+		OS:        "Linux",
+		Browser:   "Firefox",
+		RiskFlags: entity.RiskNewDevice + "," + entity.RiskImpossibleTravel,
+		CreatedAt: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC),
+	}
+	msg := suspiciousLoginMessage(user, attempt)
+	if msg.To != user.Email {
+		t.Errorf("收件人 = %s, 期望 %s", msg.To, user.Email)
+	}
+	for _, want := range []string{"alice", "2024-03-01 08:00:00", "新设备、登录地点与上次相距过远", "8.8.8.8", "US Mountain View", "Linux / Firefox"} {
+		if !strings.Contains(msg.Body, want) {
+			t.Errorf("提醒内容缺少 %q:\n%s", want, msg.Body)
+		}
+	}
+}
+
+func TestLoginRiskNotifyLockout(t *testing.T) {
+	s, notifier := newTestRiskService(t, LoginRiskConfig{})
+	lockedUntil := time.Date(2024, 3, 1, 8, 5, 0, 0, time.UTC)
+	s.NotifyLockout(&entity.User{Username: "alice", Email: "alice@example.com", LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true}})
+
+	select {
+	case msg := <-notifier.sent:
+		if msg.To != "alice@example.com" || !strings.Contains(msg.Body, "2024-03-01 08:05:00") {
+			t.Errorf("锁定提醒 = %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("未发送锁定提醒")
+	}
+}
+
+func TestIPRangeOf(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"1.0.1.7", "1.0.1.0/24"},
+		{"::ffff:1.0.1.7", "1.0.1.0/24"},
+		{"2001:db8:1234:5678::1", "2001:db8:1234::/48"},
+		{"not-an-ip", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := ipRangeOf(tt.ip); got != tt.want {
+			t.Errorf("ipRangeOf(%q) = %q, 期望 %q", tt.ip, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/internal/model/request"
+)
+
+// OrganizationService 负责组织（租户）的维护，仅供超级管理员使用
+type OrganizationService struct {
+	db *gorm.DB
+}
+
+// NewOrganizationService 创建并返回一个 OrganizationService 实例。
+func NewOrganizationService(db *gorm.DB) *OrganizationService {
+	return &OrganizationService{db: db}
+}
+
+// Create 创建组织
+func (s *OrganizationService) Create(ctx context.Context, req request.OrganizationCreateRequest) (*entity.Organization, error) {
+	var cnt int64
+	if err := s.db.WithContext(ctx).
+		Model(&entity.Organization{}).
+		Where("code = ?", req.Code).
+		Count(&cnt).Error; err != nil {
+		return nil, fmt.Errorf("查询组织失败: %w", err)
+	}
+	if cnt > 0 {
+		return nil, fmt.Errorf("组织编码已存在")
+	}
+
+	operator := currentOperator(ctx)
+	org := &entity.Organization{
+		Name:      req.Name,
+		Code:      req.Code,
+		CreatedAt: time.Now(),
+		UpdatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		CreatedBy: operator,
+		UpdatedBy: operator,
+	}
+	if err := s.db.WithContext(ctx).Create(org).Error; err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// List 分页查询组织
+func (s *OrganizationService) List(ctx context.Context, page request.PageRequest) ([]entity.Organization, int64, error) {
+	var orgs []entity.Organization
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&entity.Organization{}).Where("deleted = 0")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("id").
+		Limit(int(page.PageSize)).
+		Offset(int(page.GetOffset())).
+		Find(&orgs).Error; err != nil {
+		return nil, 0, err
+	}
+	return orgs, total, nil
+}
+
+// GetByID 根据ID获取组织
+func (s *OrganizationService) GetByID(ctx context.Context, orgID int64) (*entity.Organization, error) {
+	var org entity.Organization
+	if err := s.db.WithContext(ctx).Where("deleted = 0").First(&org, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("组织不存在")
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+// Update 更新组织名称或状态
+func (s *OrganizationService) Update(ctx context.Context, orgID int64, req request.OrganizationUpdateRequest) (*entity.Organization, error) {
+	org, err := s.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != "" {
+		org.Name = req.Name
+	}
+	if req.Status != nil {
+		org.Status = *req.Status
+	}
+	org.UpdatedBy = currentOperator(ctx)
+	org.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.db.WithContext(ctx).Save(org).Error; err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// AssignUser 将用户移入指定组织，同时清空其在原租户内的专属角色
+func (s *OrganizationService) AssignUser(ctx context.Context, orgID, userID int64) (*entity.User, error) {
+	if orgID != entity.DefaultTenantID {
+		if _, err := s.GetByID(ctx, orgID); err != nil {
+			return nil, err
+		}
+	}
+
+	var user entity.User
+	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("用户不存在")
+		}
+		return nil, err
+	}
+	if user.TenantID == orgID {
+		return &user, nil
+	}
+
+	// 仅保留全局共享角色，原租户的专属角色在新租户中无效
+	var shared []entity.UserRole
+	if err := s.db.WithContext(ctx).
+		Where("tenant_id = ? AND role_name IN ?", entity.DefaultTenantID, user.GetAuthorities()).
+		Find(&shared).Error; err != nil {
+		return nil, err
+	}
+	names := make([]string, len(shared))
+	for i, r := range shared {
+		names[i] = r.RoleName
+	}
+
+	user.TenantID = orgID
+	user.Roles = strings.Join(names, ",")
+	user.UpdatedBy = currentOperator(ctx)
+	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.db.WithContext(ctx).Save(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
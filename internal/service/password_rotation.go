@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/pkg/password"
+)
+
+// PasswordRotationPolicy 密码轮换策略：最长使用时间、过期前提醒以及禁止重复使用最近的密码
+type PasswordRotationPolicy struct {
+	MaxAge     time.Duration // 密码最长使用时间，0 表示永不过期
+	WarnBefore time.Duration // 过期前多久开始提醒
+	History    int           // 新密码不得与最近 History 个密码（含当前密码）相同，0 表示不检查
+}
+
+// ExpiresAt 返回密码过期时间，从最近一次修改密码算起，从未修改过时从注册时间算起
+func (p PasswordRotationPolicy) ExpiresAt(u *entity.User) (time.Time, bool) {
+	if p.MaxAge <= 0 {
+		return time.Time{}, false
+	}
+	changedAt := u.CreatedAt
+	if u.PasswordResetAt.Valid {
+		changedAt = u.PasswordResetAt.Time
+	}
+	return changedAt.Add(p.MaxAge), true
+}
+
+// ChangeReason 返回必须修改密码的原因，无需修改时返回空字符串
+func (p PasswordRotationPolicy) ChangeReason(u *entity.User, now time.Time) string {
+	if u.MustChangePassword {
+		return "密码已被管理员重置，请修改密码"
+	}
+	if expiresAt, ok := p.ExpiresAt(u); ok && !now.Before(expiresAt) {
+		return "密码已过期，请修改密码"
+	}
+	return ""
+}
+
+// ExpiryWarning 密码即将过期时返回提醒及过期时间
+func (p PasswordRotationPolicy) ExpiryWarning(u *entity.User, now time.Time) (string, *time.Time) {
+	expiresAt, ok := p.ExpiresAt(u)
+	if !ok || p.WarnBefore <= 0 || expiresAt.Sub(now) > p.WarnBefore {
+		return "", nil
+	}
+	days := int(expiresAt.Sub(now).Hours()/24) + 1
+	return fmt.Sprintf("密码将在 %d 天内过期，请及时修改", days), &expiresAt
+}
+
+// checkReuse 判断新密码是否与当前密码或最近使用过的密码相同
+func (p PasswordRotationPolicy) checkReuse(ctx context.Context, db *gorm.DB, hasher *password.Hasher, u *entity.User, newPassword string) error {
+	if p.History <= 0 {
+		return nil
+	}
+	hashes := []string{u.Password}
+	if p.History > 1 {
+		var history []entity.PasswordHistory
+		if err := db.WithContext(ctx).
+			Where("user_id = ?", u.ID).
+			Order("id DESC").
+			Limit(p.History - 1).
+			Find(&history).Error; err != nil {
+			return fmt.Errorf("查询密码历史失败: %w", err)
+		}
+		for _, h := range history {
+			hashes = append(hashes, h.Password)
+		}
+	}
+	for _, h := range hashes {
+		if ok, _ := hasher.Verify(newPassword, h); ok {
+			return fmt.Errorf("新密码不能与最近 %d 次使用过的密码相同", p.History)
+		}
+	}
+	return nil
+}
+
+// remember 在修改密码的事务中保存旧密码哈希，并只保留检查所需的条数
+func (p PasswordRotationPolicy) remember(tx *gorm.DB, userID int64, oldHash string) error {
+	if p.History <= 1 || oldHash == "" {
+		return nil
+	}
+	if err := tx.Create(&entity.PasswordHistory{UserID: userID, Password: oldHash, CreatedAt: time.Now()}).Error; err != nil {
+		return err
+	}
+	keep := tx.Model(&entity.PasswordHistory{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(p.History - 1)
+	return tx.Where("user_id = ? AND id NOT IN (?)", userID, keep).Delete(&entity.PasswordHistory{}).Error
+}
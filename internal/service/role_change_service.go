@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/internal/model/request"
+)
+
+// RoleChangeService 负责角色变更的四眼审批：
+// 涉及敏感角色的变更先生成待审批申请，由申请人和目标用户之外的管理员审批后生效。
+type RoleChangeService struct {
+	db             *gorm.DB
+	userService    *UserService
+	sensitiveRoles map[string]struct{}
+}
+
+// NewRoleChangeService 创建并返回一个 RoleChangeService 实例。
+func NewRoleChangeService(db *gorm.DB, userService *UserService, sensitiveRoles []string) *RoleChangeService {
+	roles := make(map[string]struct{}, len(sensitiveRoles))
+	for _, r := range sensitiveRoles {
+		roles[r] = struct{}{}
+	}
+	return &RoleChangeService{db: db, userService: userService, sensitiveRoles: roles}
+}
+
+// ChangeRole 修改用户角色：不涉及敏感角色时直接生效，否则生成待审批申请。
+// 直接生效时返回更新后的用户，进入审批时返回申请记录。
+func (s *RoleChangeService) ChangeRole(ctx context.Context, userID int64, req request.ChangeRoleRequest) (*entity.User, *entity.RoleChangeRequest, error) {
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newRoles, err := s.userService.resolveRoleNames(ctx, user.TenantID, req.RoleIds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkSuperAdminGrant(ctx, user.Roles, newRoles); err != nil {
+		return nil, nil, err
+	}
+
+	if !s.touchesSensitiveRole(user.Roles, newRoles) {
+		user, err := s.userService.ChangeRoleByIds(ctx, userID, req)
+		return user, nil, err
+	}
+
+	var pending int64
+	if err := s.db.WithContext(ctx).
+		Model(&entity.RoleChangeRequest{}).
+		Where("user_id = ? AND status = ?", userID, entity.RoleChangePending).
+		Count(&pending).Error; err != nil {
+		return nil, nil, err
+	}
+	if pending > 0 {
+		return nil, nil, fmt.Errorf("该用户已有待审批的角色变更申请")
+	}
+
+	requesterID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]string, len(req.RoleIds))
+	for i, id := range req.RoleIds {
+		ids[i] = strconv.FormatInt(id, 10)
+	}
+	changeReq := &entity.RoleChangeRequest{
+		TenantID:      user.TenantID,
+		UserID:        userID,
+		RoleIds:       strings.Join(ids, ","),
+		OldRoles:      user.Roles,
+		NewRoles:      newRoles,
+		Status:        entity.RoleChangePending,
+		RequestedBy:   currentOperator(ctx),
+		RequestedByID: requesterID,
+		CreatedAt:     time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(changeReq).Error; err != nil {
+		return nil, nil, err
+	}
+	return nil, changeReq, nil
+}
+
+// Approve 审批通过角色变更申请，并在同一事务中更新用户角色
+func (s *RoleChangeService) Approve(ctx context.Context, requestID int64, req request.ReviewRoleChangeRequest) (*entity.RoleChangeRequest, error) {
+	changeReq, user, err := s.loadForReview(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	roleIds, err := parseRoleIds(changeReq.RoleIds)
+	if err != nil {
+		return nil, err
+	}
+	// 审批时重新校验角色，防止申请后角色被删除
+	newRoles, err := s.userService.resolveRoleNames(ctx, user.TenantID, roleIds)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewer := currentOperator(ctx)
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	err = s.userService.audit.Transaction(ctx, func(tx *gorm.DB, record func(*entity.AuditEvent)) error {
+		// 申请提交后用户角色又被修改过时，按申请写入会覆盖这次修改，拒绝审批过期的申请
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(user, user.ID).Error; err != nil {
+			return err
+		}
+		if !sameRoles(user.Roles, changeReq.OldRoles) {
+			return fmt.Errorf("用户角色在申请提交后已被修改，申请已过期，请驳回后重新申请")
+		}
+		if err := s.markReviewed(tx, changeReq, entity.RoleChangeApproved, reviewer, req.Comment, now); err != nil {
+			return err
+		}
+		before := *user
+		user.Roles = newRoles
+		user.UpdatedBy = reviewer
+		user.UpdatedAt = now
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		event := newAuditEvent(ctx, entity.AuditUserRole, &before, user)
+		event.Reason = fmt.Sprintf("审批角色变更申请 #%d，申请人 %s", changeReq.ID, changeReq.RequestedBy)
+		record(event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 角色保存在 Token 中，删除会话使用户重新登录后才能以新角色访问
+	s.userService.dropSession(user.Username)
+	return changeReq, nil
+}
+
+// Reject 驳回角色变更申请
+func (s *RoleChangeService) Reject(ctx context.Context, requestID int64, req request.ReviewRoleChangeRequest) (*entity.RoleChangeRequest, error) {
+	changeReq, _, err := s.loadForReview(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewer := currentOperator(ctx)
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.markReviewed(s.db.WithContext(ctx), changeReq, entity.RoleChangeRejected, reviewer, req.Comment, now); err != nil {
+		return nil, err
+	}
+	return changeReq, nil
+}
+
+// ListRequests 分页查询角色变更申请，status 为空时返回全部
+func (s *RoleChangeService) ListRequests(ctx context.Context, status *int, page request.PageRequest) ([]entity.RoleChangeRequest, int64, error) {
+	var list []entity.RoleChangeRequest
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&entity.RoleChangeRequest{}).Scopes(TenantScope(ctx, "tenant_id"))
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("id DESC").
+		Limit(int(page.PageSize)).
+		Offset(int(page.GetOffset())).
+		Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// loadForReview 加载待审批申请及目标用户，并校验审批人资格
+func (s *RoleChangeService) loadForReview(ctx context.Context, requestID int64) (*entity.RoleChangeRequest, *entity.User, error) {
+	var changeReq entity.RoleChangeRequest
+	if err := s.db.WithContext(ctx).Scopes(TenantScope(ctx, "tenant_id")).First(&changeReq, requestID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("角色变更申请不存在")
+		}
+		return nil, nil, err
+	}
+	if changeReq.Status != entity.RoleChangePending {
+		return nil, nil, fmt.Errorf("该申请已处理")
+	}
+
+	user, err := s.userService.GetUserByID(ctx, changeReq.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 按用户 ID 判断审批人资格：用户名可以修改，改名后不能借此审批自己的申请
+	reviewerID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("无法识别当前审批人")
+	}
+	selfRequested := reviewerID == changeReq.RequestedByID
+	if changeReq.RequestedByID == 0 {
+		// 未记录申请人 ID 的早期申请只能按用户名判断
+		selfRequested = currentOperator(ctx) == changeReq.RequestedBy
+	}
+	if selfRequested {
+		return nil, nil, fmt.Errorf("不能审批自己提交的申请")
+	}
+	if reviewerID == changeReq.UserID {
+		return nil, nil, fmt.Errorf("不能审批针对自己的角色变更")
+	}
+	if err := checkSuperAdminGrant(ctx, changeReq.OldRoles, changeReq.NewRoles); err != nil {
+		return nil, nil, err
+	}
+	return &changeReq, user, nil
+}
+
+// markReviewed 以条件更新的方式标记申请已处理，避免并发重复审批
+func (s *RoleChangeService) markReviewed(tx *gorm.DB, changeReq *entity.RoleChangeRequest, status int, reviewer, comment string, now sql.NullTime) error {
+	result := tx.Model(&entity.RoleChangeRequest{}).
+		Where("id = ? AND status = ?", changeReq.ID, entity.RoleChangePending).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"reviewed_by":    reviewer,
+			"reviewed_at":    now,
+			"review_comment": comment,
+			"updated_at":     now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("该申请已处理")
+	}
+	changeReq.Status = status
+	changeReq.ReviewedBy = reviewer
+	changeReq.ReviewedAt = now
+	changeReq.ReviewComment = comment
+	changeReq.UpdatedAt = now
+	return nil
+}
+
+// touchesSensitiveRole 判断新旧角色之间是否有敏感角色被授予或移除
+func (s *RoleChangeService) touchesSensitiveRole(oldRoles, newRoles string) bool {
+	oldSet := splitRoles(oldRoles)
+	newSet := splitRoles(newRoles)
+	for role := range s.sensitiveRoles {
+		_, before := oldSet[role]
+		_, after := newSet[role]
+		if before != after {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSuperAdminGrant 只有超级管理员才能授予或移除超级管理员角色，防止租户管理员越权
+func checkSuperAdminGrant(ctx context.Context, oldRoles, newRoles string) error {
+	_, before := splitRoles(oldRoles)[RoleSuperAdmin]
+	_, after := splitRoles(newRoles)[RoleSuperAdmin]
+	if before != after && !isSuperAdmin(currentClaims(ctx)) {
+		return fmt.Errorf("只有超级管理员可以变更超级管理员角色")
+	}
+	return nil
+}
+
+// splitRoles 将逗号分隔的角色字符串转换为集合
+func splitRoles(roles string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, r := range strings.Split(roles, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			set[r] = struct{}{}
+		}
+	}
+	return set
+}
+
+// parseRoleIds 解析逗号分隔的角色 ID
+func parseRoleIds(ids string) ([]int64, error) {
+	list := make([]int64, 0)
+	for _, part := range strings.Split(ids, ",") {
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("角色 ID 不合法: %s", part)
+		}
+		list = append(list, id)
+	}
+	return list, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/pkg/jwt"
+)
+
+// 会话类型
+const (
+	SessionLogin         = "login"         // 用户本人登录
+	SessionImpersonation = "impersonation" // 管理员模拟该用户登录
+)
+
+// Session 当前用户的一个有效会话
+type Session struct {
+	Type         string     `json:"type"`
+	Current      bool       `json:"current"` // 是否为发起本次请求的会话
+	Restricted   bool       `json:"restricted,omitempty"`
+	IssuedAt     time.Time  `json:"issuedAt"`
+	AuthTime     *time.Time `json:"authTime,omitempty"`
+	ExpiresAt    time.Time  `json:"expiresAt"`
+	Impersonator *jwt.Actor `json:"impersonator,omitempty"`
+	Reason       string     `json:"reason,omitempty"` // 模拟登录的原因
+	IP           string     `json:"ip,omitempty"`     // 模拟登录发起时的 IP
+}
+
+// ListSessions 列出当前用户的有效会话：本人登录的会话（同一时间只有一个），
+// 以及管理员正在以该用户身份进行的模拟登录
+func (s *AuthService) ListSessions(ctx context.Context) ([]Session, error) {
+	claims := currentClaims(ctx)
+	if claims == nil {
+		return nil, fmt.Errorf("未登录")
+	}
+	current := extractTokenFromContext(ctx)
+	sessions := make([]Session, 0, 2)
+
+	token, err := s.redis.Get(context.Background(), claims.Username).Result()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("查询会话失败: %w", err)
+	}
+	if err == nil {
+		if sc, err := jwt.ParseToken(token); err == nil {
+			session := newSession(SessionLogin, sc, token == current)
+			// Token 过期时间随访问滑动，以 Redis 中的剩余时间为准
+			if ttl, err := s.redis.TTL(context.Background(), claims.Username).Result(); err == nil && ttl > 0 {
+				session.ExpiresAt = time.Now().Add(ttl).Truncate(time.Second)
+			}
+			sessions = append(sessions, session)
+		}
+	}
+
+	var logs []entity.ImpersonationLog
+	if err := s.db.WithContext(ctx).
+		Where("target_id = ? AND action = ? AND created_at >= ?",
+			claims.UserId, entity.ImpersonationStart, time.Now().Add(-jwt.ImpersonationExpiration)).
+		Order("id DESC").
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("查询模拟登录记录失败: %w", err)
+	}
+	for _, l := range logs {
+		token, err := s.redis.Get(context.Background(), jwt.ImpersonationKeyPrefix+l.TokenID).Result()
+		if err != nil {
+			// 已过期或已结束
+			continue
+		}
+		sc, err := jwt.ParseToken(token)
+		if err != nil {
+			continue
+		}
+		session := newSession(SessionImpersonation, sc, token == current)
+		session.Impersonator = sc.Act
+		session.Reason = l.Reason
+		session.IP = l.IP
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func newSession(typ string, claims *jwt.CustomClaims, current bool) Session {
+	session := Session{
+		Type:       typ,
+		Current:    current,
+		Restricted: claims.IsRestricted(),
+	}
+	if claims.IssuedAt != nil {
+		session.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		session.ExpiresAt = claims.ExpiresAt.Time
+	}
+	if claims.AuthTime > 0 {
+		t := time.Unix(claims.AuthTime, 0)
+		session.AuthTime = &t
+	}
+	return session
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/pkg/jwt"
+)
+
+const (
+	// RoleAdmin 租户管理员，只能管理本租户内的用户
+	RoleAdmin = "ROLE_ADMIN"
+	// RoleSuperAdmin 超级管理员，可跨租户管理
+	RoleSuperAdmin = "ROLE_SUPER_ADMIN"
+)
+
+// isSuperAdmin 判断 claims 是否具备超级管理员角色
+func isSuperAdmin(claims *jwt.CustomClaims) bool {
+	if claims == nil {
+		return false
+	}
+	for _, r := range claims.Roles {
+		if r == RoleSuperAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// noTenant 缺少登录信息的请求被限定到的租户，不存在属于它的数据，查询结果为空
+const noTenant int64 = -1
+
+type systemContextKey struct{}
+
+// SystemContext 标记命令行、后台任务等内部调用。这类调用没有登录信息，不受租户限制；
+// 其他缺少登录信息的调用一律按无权访问任何租户处理，避免传错 ctx 时静默放开租户隔离
+func SystemContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemContextKey{}, true)
+}
+
+// isSystemContext 判断是否为 SystemContext 标记的内部调用
+func isSystemContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	system, _ := ctx.Value(systemContextKey{}).(bool)
+	return system
+}
+
+// tenantFilter 返回当前请求需要限定的租户；无需限定（超级管理员或系统内部调用）时 ok 为 false。
+// 既没有登录信息也不是系统调用时限定到 noTenant
+func tenantFilter(ctx context.Context) (tenantID int64, ok bool) {
+	claims := currentClaims(ctx)
+	if claims == nil {
+		if isSystemContext(ctx) {
+			return 0, false
+		}
+		return noTenant, true
+	}
+	if isSuperAdmin(claims) {
+		return 0, false
+	}
+	return claims.TenantId, true
+}
+
+// TenantScope 返回按当前请求租户过滤的 GORM scope，column 为租户字段名
+func TenantScope(ctx context.Context, column string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if tenantID, ok := tenantFilter(ctx); ok {
+			return db.Where(column+" = ?", tenantID)
+		}
+		return db
+	}
+}
+
+// SharedTenantScope 与 TenantScope 类似，但同时包含默认租户下全局共享的数据（如角色）
+func SharedTenantScope(ctx context.Context, column string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		tenantID, ok := tenantFilter(ctx)
+		switch {
+		case !ok:
+			return db
+		case tenantID == noTenant:
+			return db.Where(column+" = ?", noTenant)
+		default:
+			return db.Where(column+" IN ?", []int64{entity.DefaultTenantID, tenantID})
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/internal/model/request"
+)
+
+// 批量操作
+const (
+	BulkBlock   = "block"
+	BulkUnblock = "unblock"
+	BulkDelete  = "delete"
+	BulkRestore = "restore"
+	BulkRole    = "role"
+	BulkLogout  = "logout"
+)
+
+// 单个用户的批量操作结果
+const (
+	BulkSucceeded = "succeeded"
+	BulkSkipped   = "skipped" // 用户已处于目标状态，无需修改
+	BulkFailed    = "failed"
+)
+
+const (
+	bulkBatchSize     = 100  // 每个事务处理的用户数
+	bulkMaxUsers      = 5000 // 单次最多操作的用户数
+	bulkPreviewSample = 20   // 预览时返回的用户数
+)
+
+// bulkActions 批量操作对应的策略操作和审计事件
+var bulkActions = map[string]struct{ policy, audit string }{
+	BulkBlock:   {ActionUserBlock, entity.AuditUserBlock},
+	BulkUnblock: {ActionUserUnblock, entity.AuditUserUnblock},
+	BulkDelete:  {ActionUserDelete, entity.AuditUserDelete},
+	BulkRestore: {ActionUserRestore, entity.AuditUserRestore},
+	BulkRole:    {ActionUserRole, entity.AuditUserRole},
+	BulkLogout:  {ActionUserLogout, entity.AuditUserLogout},
+}
+
+// BulkResult 单个用户的处理结果
+type BulkResult struct {
+	UserID   int64  `json:"userId"`
+	Username string `json:"username,omitempty"`
+	Result   string `json:"result"`
+	Message  string `json:"message,omitempty"`
+}
+
+// BulkSummary 批量操作结果
+type BulkSummary struct {
+	Action    string       `json:"action"`
+	Total     int          `json:"total"`
+	Succeeded int          `json:"succeeded"`
+	Skipped   int          `json:"skipped"`
+	Failed    int          `json:"failed"`
+	Results   []BulkResult `json:"results"`
+}
+
+// BulkPreview 执行前预览受影响的用户
+type BulkPreview struct {
+	Action string        `json:"action"`
+	Count  int64         `json:"count"`
+	Sample []entity.User `json:"sample"` // 按 ID 升序的前若干个用户
+}
+
+// UserBulkService 对按 ID 或搜索条件选出的一批用户执行封禁、删除、分配角色等操作，
+// 每个用户单独按策略鉴权，分批在事务中保存并逐个记录审计事件
+type UserBulkService struct {
+	db         *gorm.DB
+	users      *UserService
+	roleChange *RoleChangeService
+	policy     *PolicyService
+	audit      *AuditService
+	rdb        *redis.Client
+}
+
+// NewUserBulkService 创建并返回一个 UserBulkService 实例
+func NewUserBulkService(db *gorm.DB, users *UserService, roleChange *RoleChangeService, policy *PolicyService, audit *AuditService, rdb *redis.Client) *UserBulkService {
+	return &UserBulkService{db: db, users: users, roleChange: roleChange, policy: policy, audit: audit, rdb: rdb}
+}
+
+// Preview 返回将被操作的用户数量和前若干个用户，不做任何修改
+func (s *UserBulkService) Preview(ctx context.Context, action string, req request.BulkUserRequest) (*BulkPreview, error) {
+	if err := s.authorize(ctx, action); err != nil {
+		return nil, err
+	}
+	query, err := s.targets(ctx, action, req)
+	if err != nil {
+		return nil, err
+	}
+	preview := &BulkPreview{Action: action}
+	if err := query.Count(&preview.Count).Error; err != nil {
+		return nil, err
+	}
+	if err := query.Order("id").Limit(bulkPreviewSample).Find(&preview.Sample).Error; err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// Execute 执行批量操作。单个用户鉴权失败或无需修改时不影响其他用户；
+// 每批用户在一个事务中保存，数据库出错时整批回滚并标记为失败
+func (s *UserBulkService) Execute(ctx context.Context, action string, req request.BulkUserRequest) (*BulkSummary, error) {
+	if err := s.authorize(ctx, action); err != nil {
+		return nil, err
+	}
+	query, err := s.targets(ctx, action, req)
+	if err != nil {
+		return nil, err
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > bulkMaxUsers {
+		return nil, fmt.Errorf("匹配的用户超过 %d 个，请缩小范围", bulkMaxUsers)
+	}
+
+	var roles *bulkRoles
+	if action == BulkRole {
+		if len(req.RoleIds) == 0 {
+			return nil, fmt.Errorf("请指定要分配的角色")
+		}
+		roles = &bulkRoles{ids: req.RoleIds, names: make(map[int64]string)}
+	}
+	// 已在本次操作中恢复的用户名，同名的多个已删除用户只能恢复一个
+	restored := make(map[string]bool)
+
+	summary := &BulkSummary{Action: action, Results: []BulkResult{}}
+	found := make(map[int64]bool, count)
+	var batch []entity.User
+	err = query.FindInBatches(&batch, bulkBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			found[batch[i].ID] = true
+		}
+		return s.executeBatch(ctx, action, batch, roles, restored, summary)
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 明确指定但不存在（或不在本租户、不符合操作前提）的用户
+	for _, id := range req.UserIds {
+		if !found[id] {
+			found[id] = true
+			summary.add(BulkResult{UserID: id, Result: BulkFailed, Message: missingMessage(action)})
+		}
+	}
+	return summary, nil
+}
+
+// authorize 在查询目标用户之前检查当前用户能否列出用户并执行该操作。
+// 预览会返回匹配数量和用户详情，不能只依赖执行时对单个用户的鉴权
+func (s *UserBulkService) authorize(ctx context.Context, action string) error {
+	spec, ok := bulkActions[action]
+	if !ok {
+		return fmt.Errorf("不支持的批量操作: %s", action)
+	}
+	for _, a := range []string{ActionUserList, spec.policy} {
+		decision, err := s.policy.Authorize(ctx, a, nil)
+		if err != nil {
+			return err
+		}
+		if !decision.Allowed {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
+
+// targets 构造待操作用户的查询：恢复只针对已删除的用户，其他操作只针对未删除的用户
+func (s *UserBulkService) targets(ctx context.Context, action string, req request.BulkUserRequest) (*gorm.DB, error) {
+	if _, ok := bulkActions[action]; !ok {
+		return nil, fmt.Errorf("不支持的批量操作: %s", action)
+	}
+	if (len(req.UserIds) > 0) == (req.Filter != nil) {
+		return nil, fmt.Errorf("userIds 与 filter 必须且只能指定一个")
+	}
+
+	deleted := 0
+	if action == BulkRestore {
+		deleted = 1
+	}
+	query := s.db.WithContext(ctx).Model(&entity.User{}).
+		Scopes(s.users.tenantScope(ctx)).
+		Unscoped().
+		Where("deleted = ?", deleted)
+	if len(req.UserIds) > 0 {
+		return query.Where("id IN ?", req.UserIds), nil
+	}
+	filter := *req.Filter
+	filter.Deleted = nil
+	return s.users.buildSearchQuery(query, filter), nil
+}
+
+// executeBatch 处理一批用户：先逐个鉴权并计算修改，再在一个事务中保存修改和审计事件
+func (s *UserBulkService) executeBatch(ctx context.Context, action string, batch []entity.User, roles *bulkRoles, restored map[string]bool, summary *BulkSummary) error {
+	self, _ := currentUserID(ctx)
+	operator := currentOperator(ctx)
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+
+	type change struct {
+		before, after entity.User
+	}
+	var changes []change
+	for i := range batch {
+		user := batch[i]
+		result := BulkResult{UserID: user.ID, Username: user.Username, Result: BulkFailed}
+
+		decision, err := s.policy.Authorize(ctx, bulkActions[action].policy, &user)
+		switch {
+		case err != nil:
+			return err
+		case !decision.Allowed:
+			result.Message = "权限不足"
+		case user.ID == self && action != BulkUnblock && action != BulkRestore:
+			result.Message = "不能对自己执行该操作"
+		default:
+			after := user
+			skip, err := s.apply(ctx, action, &after, roles, restored)
+			switch {
+			case err != nil:
+				result.Message = err.Error()
+			case skip != "":
+				result.Result, result.Message = BulkSkipped, skip
+			default:
+				if action != BulkLogout {
+					after.UpdatedBy = operator
+					after.UpdatedAt = now
+				}
+				changes = append(changes, change{before: user, after: after})
+				continue
+			}
+		}
+		summary.add(result)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	err := s.audit.Transaction(ctx, func(tx *gorm.DB, record func(*entity.AuditEvent)) error {
+		for i := range changes {
+			c := &changes[i]
+			if action != BulkLogout {
+				if err := tx.Save(&c.after).Error; err != nil {
+					return err
+				}
+			}
+			record(newAuditEvent(ctx, bulkActions[action].audit, &c.before, &c.after))
+		}
+		return nil
+	})
+	for _, c := range changes {
+		result := BulkResult{UserID: c.after.ID, Username: c.after.Username, Result: BulkSucceeded}
+		if err != nil {
+			result.Result, result.Message = BulkFailed, "保存失败: "+err.Error()
+		}
+		summary.add(result)
+	}
+	// 封禁、删除和强制下线后删除会话；删除会释放用户名，不能让旧 Token 继续有效；
+	// 角色保存在 Token 中，分配角色后也要删除会话，使被撤销的角色立即失效
+	if err == nil && (action == BulkLogout || action == BulkBlock || action == BulkDelete || action == BulkRole) {
+		for _, c := range changes {
+			s.users.dropSession(c.after.Username)
+		}
+	}
+	return nil
+}
+
+// apply 在 user 上执行修改，无需修改时返回跳过原因
+func (s *UserBulkService) apply(ctx context.Context, action string, user *entity.User, roles *bulkRoles, restored map[string]bool) (skip string, err error) {
+	switch action {
+	case BulkBlock:
+		if user.Status == 1 {
+			return "已是封禁状态", nil
+		}
+		user.Status = 1
+	case BulkUnblock:
+		if user.Status != 1 {
+			return "未被封禁", nil
+		}
+		user.Status = 0
+	case BulkDelete:
+		user.Deleted = 1
+		user.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	case BulkRestore:
+		if restored[user.Username] {
+			return "", fmt.Errorf("用户名 %s 已被其他用户使用，无法恢复", user.Username)
+		}
+		if err := s.users.checkRestorable(ctx, user); err != nil {
+			return "", err
+		}
+		restored[user.Username] = true
+		user.Deleted = 0
+		user.DeletedAt = sql.NullTime{}
+	case BulkRole:
+		names, err := roles.resolve(ctx, s.users, user.TenantID)
+		if err != nil {
+			return "", err
+		}
+		if sameRoles(user.Roles, names) {
+			return "角色未变化", nil
+		}
+		if err := checkSuperAdminGrant(ctx, user.Roles, names); err != nil {
+			return "", err
+		}
+		if s.roleChange.touchesSensitiveRole(user.Roles, names) {
+			return "", fmt.Errorf("涉及敏感角色，需要单独提交审批")
+		}
+		user.Roles = names
+	case BulkLogout:
+		n, err := s.rdb.Exists(ctx, user.Username).Result()
+		if err != nil {
+			return "", fmt.Errorf("查询登录会话失败: %w", err)
+		}
+		if n == 0 {
+			return "当前没有登录会话", nil
+		}
+	}
+	return "", nil
+}
+
+func (summary *BulkSummary) add(r BulkResult) {
+	summary.Total++
+	switch r.Result {
+	case BulkSucceeded:
+		summary.Succeeded++
+	case BulkSkipped:
+		summary.Skipped++
+	default:
+		summary.Failed++
+	}
+	summary.Results = append(summary.Results, r)
+}
+
+func missingMessage(action string) string {
+	if action == BulkRestore {
+		return "用户不存在或未被删除"
+	}
+	return "用户不存在"
+}
+
+// bulkRoles 批量分配的角色，按租户缓存解析出的角色名
+type bulkRoles struct {
+	ids   []int64
+	names map[int64]string
+}
+
+func (r *bulkRoles) resolve(ctx context.Context, users *UserService, tenantID int64) (string, error) {
+	if names, ok := r.names[tenantID]; ok {
+		return names, nil
+	}
+	names, err := users.resolveRoleNames(ctx, tenantID, r.ids)
+	if err != nil {
+		return "", err
+	}
+	r.names[tenantID] = names
+	return names, nil
+}
+
+// sameRoles 判断两个逗号分隔的角色串是否包含相同的角色
+func sameRoles(a, b string) bool {
+	as, bs := splitRoles(a), splitRoles(b)
+	if len(as) != len(bs) {
+		return false
+	}
+	for role := range as {
+		if _, ok := bs[role]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/internal/model/request"
+)
+
+// userExportBatchSize 导出时每批读取的用户数
+const userExportBatchSize = 500
+
+// UserExportOptions 导出选项，Format 和 Columns 只用于记录审计事件
+type UserExportOptions struct {
+	Format  string
+	Columns []string
+	Mask    bool // 手机号、邮箱、登录 IP 脱敏
+}
+
+// ExportUsers 按搜索条件导出全部匹配的用户，按主键分批读取（WHERE id > 上一批最大 ID），
+// 每批回调一次，不受分页限制也不会因为数据变化而重复或遗漏。导出完成后记录审计事件
+func (s *UserService) ExportUsers(ctx context.Context, req request.UserSearchRequest, opts UserExportOptions, fn func(batch []entity.User) error) error {
+	query := s.db.WithContext(ctx).Model(&entity.User{}).Scopes(s.tenantScope(ctx))
+	query = s.buildSearchQuery(query, req)
+
+	rows := 0
+	var batch []entity.User
+	err := query.FindInBatches(&batch, userExportBatchSize, func(tx *gorm.DB, _ int) error {
+		if opts.Mask {
+			for i := range batch {
+				maskUser(&batch[i])
+			}
+		}
+		rows += len(batch)
+		return fn(batch)
+	}).Error
+
+	filter, _ := json.Marshal(req)
+	event := newAuditEvent(ctx, entity.AuditUserExport, nil, nil)
+	event.Reason = fmt.Sprintf("format=%s columns=%s masked=%t rows=%d filter=%s",
+		opts.Format, strings.Join(opts.Columns, ","), opts.Mask, rows, filter)
+	if err != nil {
+		failAuditEvent(event, event.Reason+" error="+err.Error())
+	}
+	if auditErr := s.audit.Record(ctx, event); auditErr != nil && err == nil {
+		err = auditErr
+	}
+	return err
+}
+
+// maskUser 对导出的敏感字段脱敏
+func maskUser(u *entity.User) {
+	u.Phone = maskPhone(u.Phone)
+	u.Email = maskEmail(u.Email)
+	u.LastLoginIP = maskIP(u.LastLoginIP)
+}
+
+// maskPhone 保留前 3 位和后 4 位，如 138****5678
+func maskPhone(phone string) string {
+	n := utf8.RuneCountInString(phone)
+	if n <= 7 {
+		return strings.Repeat("*", n)
+	}
+	r := []rune(phone)
+	return string(r[:3]) + strings.Repeat("*", n-7) + string(r[n-4:])
+}
+
+// maskEmail 只保留用户名首字符和域名，如 a***@example.com
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return maskPhone(email)
+	}
+	first, _ := utf8.DecodeRuneInString(email)
+	return string(first) + "***" + email[at:]
+}
+
+// maskIP IPv4 隐藏后两段，IPv6 只保留前 3 组
+func maskIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.*.*", v4[0], v4[1])
+	}
+	groups := strings.SplitN(parsed.String(), ":", 4)
+	if len(groups) < 4 {
+		return "::*"
+	}
+	return strings.Join(groups[:3], ":") + ":*"
+}
@@ -0,0 +1,570 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/internal/model/request"
+)
+
+// UserGroupService 负责用户组、组成员及组角色的维护。
+// 用户的有效角色 = 自身角色 ∪ 所在组及其所有祖先组的角色，在登录时计算；
+// 组成员、组角色或上级组变化时删除受影响用户的会话，重新登录后按新的角色签发 Token。
+type UserGroupService struct {
+	db             *gorm.DB
+	rdb            *redis.Client
+	sensitiveRoles map[string]struct{}
+}
+
+// UserGroupItem 用户所属的组，Direct 为 false 表示通过子组继承
+type UserGroupItem struct {
+	entity.UserGroup
+	Direct bool `json:"direct"`
+}
+
+// NewUserGroupService 创建并返回一个 UserGroupService 实例。
+// sensitiveRoles 中的角色只能通过审批授予个人，不允许挂到用户组上。
+func NewUserGroupService(db *gorm.DB, rdb *redis.Client, sensitiveRoles []string) *UserGroupService {
+	roles := make(map[string]struct{}, len(sensitiveRoles))
+	for _, r := range sensitiveRoles {
+		roles[r] = struct{}{}
+	}
+	roles[RoleSuperAdmin] = struct{}{}
+	return &UserGroupService{db: db, rdb: rdb, sensitiveRoles: roles}
+}
+
+// Create 创建用户组
+func (s *UserGroupService) Create(ctx context.Context, req request.UserGroupCreateRequest) (*entity.UserGroup, error) {
+	claims := currentClaims(ctx)
+	if claims == nil {
+		return nil, fmt.Errorf("无法识别当前操作人")
+	}
+	tenantID := claims.TenantId
+	if req.TenantId != nil {
+		if !isSuperAdmin(claims) && *req.TenantId != claims.TenantId {
+			return nil, fmt.Errorf("不能在其他租户下创建用户组")
+		}
+		tenantID = *req.TenantId
+	}
+
+	group := &entity.UserGroup{
+		TenantID:    tenantID,
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   sql.NullTime{Time: time.Now(), Valid: true},
+		CreatedBy:   claims.Username,
+		UpdatedBy:   claims.Username,
+	}
+	if req.ParentId != nil {
+		parent, err := s.GetByID(ctx, *req.ParentId)
+		if err != nil {
+			return nil, fmt.Errorf("上级组不存在")
+		}
+		if parent.TenantID != tenantID {
+			return nil, fmt.Errorf("上级组不属于同一租户")
+		}
+		group.ParentID = sql.NullInt64{Int64: parent.ID, Valid: true}
+	}
+
+	if err := s.db.WithContext(ctx).Create(group).Error; err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// List 分页查询当前租户的用户组
+func (s *UserGroupService) List(ctx context.Context, page request.PageRequest) ([]entity.UserGroup, int64, error) {
+	var groups []entity.UserGroup
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&entity.UserGroup{}).
+		Scopes(TenantScope(ctx, "tenant_id")).
+		Where("deleted = 0")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("id").
+		Limit(int(page.PageSize)).
+		Offset(int(page.GetOffset())).
+		Find(&groups).Error; err != nil {
+		return nil, 0, err
+	}
+	return groups, total, nil
+}
+
+// GetByID 根据ID获取用户组
+func (s *UserGroupService) GetByID(ctx context.Context, groupID int64) (*entity.UserGroup, error) {
+	var group entity.UserGroup
+	if err := s.db.WithContext(ctx).
+		Scopes(TenantScope(ctx, "tenant_id")).
+		Where("deleted = 0").
+		First(&group, groupID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("用户组不存在")
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// Update 更新用户组名称、描述或上级组
+func (s *UserGroupService) Update(ctx context.Context, groupID int64, req request.UserGroupUpdateRequest) (*entity.UserGroup, error) {
+	group, err := s.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != "" {
+		group.Name = req.Name
+	}
+	if req.Description != "" {
+		group.Description = req.Description
+	}
+	oldParent := group.ParentID
+	if req.ParentId != nil {
+		if *req.ParentId == 0 {
+			group.ParentID = sql.NullInt64{}
+		} else {
+			if err := s.checkParent(ctx, group, *req.ParentId); err != nil {
+				return nil, err
+			}
+			group.ParentID = sql.NullInt64{Int64: *req.ParentId, Valid: true}
+		}
+	}
+	group.UpdatedBy = currentOperator(ctx)
+	group.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.db.WithContext(ctx).Save(group).Error; err != nil {
+		return nil, err
+	}
+	// 上级组变化后，该组及子孙组成员继承的角色随之变化
+	if group.ParentID != oldParent {
+		s.dropGroupSessions(ctx, group)
+	}
+	return group, nil
+}
+
+// Delete 删除用户组，同时清除其成员与角色；存在子组时不允许删除
+func (s *UserGroupService) Delete(ctx context.Context, groupID int64) error {
+	group, err := s.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	var children int64
+	if err := s.db.WithContext(ctx).Model(&entity.UserGroup{}).
+		Where("parent_id = ? AND deleted = 0", group.ID).
+		Count(&children).Error; err != nil {
+		return err
+	}
+	if children > 0 {
+		return fmt.Errorf("请先删除或移走子组")
+	}
+
+	var members []int64
+	if err := s.db.WithContext(ctx).Model(&entity.UserGroupMember{}).
+		Where("group_id = ?", group.ID).
+		Pluck("user_id", &members).Error; err != nil {
+		return err
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", group.ID).Delete(&entity.UserGroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", group.ID).Delete(&entity.UserGroupRole{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(group).Updates(map[string]interface{}{
+			"deleted":    1,
+			"updated_by": currentOperator(ctx),
+			"updated_at": sql.NullTime{Time: time.Now(), Valid: true},
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.dropSessions(ctx, members)
+	return nil
+}
+
+// AddMembers 批量添加组成员，用户必须与用户组属于同一租户
+func (s *UserGroupService) AddMembers(ctx context.Context, groupID int64, userIDs []int64) error {
+	group, err := s.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	var cnt int64
+	if err := s.db.WithContext(ctx).Model(&entity.User{}).
+		Where("id IN ? AND tenant_id = ?", userIDs, group.TenantID).
+		Count(&cnt).Error; err != nil {
+		return err
+	}
+	if int(cnt) != len(uniqueIDs(userIDs)) {
+		return fmt.Errorf("部分用户不存在或不属于该用户组所在租户")
+	}
+
+	var existing []int64
+	if err := s.db.WithContext(ctx).Model(&entity.UserGroupMember{}).
+		Where("group_id = ? AND user_id IN ?", group.ID, userIDs).
+		Pluck("user_id", &existing).Error; err != nil {
+		return err
+	}
+	skip := make(map[int64]struct{}, len(existing))
+	for _, id := range existing {
+		skip[id] = struct{}{}
+	}
+
+	operator := currentOperator(ctx)
+	members := make([]entity.UserGroupMember, 0, len(userIDs))
+	for _, id := range uniqueIDs(userIDs) {
+		if _, ok := skip[id]; ok {
+			continue
+		}
+		members = append(members, entity.UserGroupMember{
+			GroupID:   group.ID,
+			UserID:    id,
+			CreatedAt: time.Now(),
+			CreatedBy: operator,
+		})
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	if err := s.db.WithContext(ctx).Create(&members).Error; err != nil {
+		return err
+	}
+	added := make([]int64, len(members))
+	for i, m := range members {
+		added[i] = m.UserID
+	}
+	s.dropSessions(ctx, added)
+	return nil
+}
+
+// RemoveMember 移除组成员
+func (s *UserGroupService) RemoveMember(ctx context.Context, groupID, userID int64) error {
+	group, err := s.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	result := s.db.WithContext(ctx).
+		Where("group_id = ? AND user_id = ?", group.ID, userID).
+		Delete(&entity.UserGroupMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		s.dropSessions(ctx, []int64{userID})
+	}
+	return nil
+}
+
+// ListMembers 分页查询组的直接成员
+func (s *UserGroupService) ListMembers(ctx context.Context, groupID int64, page request.PageRequest) ([]entity.User, int64, error) {
+	group, err := s.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var users []entity.User
+	var total int64
+	query := s.db.WithContext(ctx).Model(&entity.User{}).
+		Where("id IN (?)", s.db.Model(&entity.UserGroupMember{}).Select("user_id").Where("group_id = ?", group.ID))
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("id").
+		Limit(int(page.PageSize)).
+		Offset(int(page.GetOffset())).
+		Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// SetRoles 覆盖设置用户组的角色
+func (s *UserGroupService) SetRoles(ctx context.Context, groupID int64, roleIDs []int64) ([]entity.UserRole, error) {
+	group, err := s.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs = uniqueIDs(roleIDs)
+	var roles []entity.UserRole
+	if len(roleIDs) > 0 {
+		if err := s.db.WithContext(ctx).
+			Where("id IN ? AND tenant_id IN ?", roleIDs, []int64{entity.DefaultTenantID, group.TenantID}).
+			Find(&roles).Error; err != nil {
+			return nil, err
+		}
+		if len(roles) != len(roleIDs) {
+			return nil, fmt.Errorf("部分角色不存在或不属于该租户")
+		}
+		for _, r := range roles {
+			if _, ok := s.sensitiveRoles[r.RoleName]; ok {
+				return nil, fmt.Errorf("敏感角色 %s 不能通过用户组授予，请使用角色变更审批", r.RoleName)
+			}
+		}
+	}
+
+	operator := currentOperator(ctx)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", group.ID).Delete(&entity.UserGroupRole{}).Error; err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			return nil
+		}
+		groupRoles := make([]entity.UserGroupRole, len(roles))
+		for i, r := range roles {
+			groupRoles[i] = entity.UserGroupRole{GroupID: group.ID, RoleID: r.ID, CreatedAt: time.Now(), CreatedBy: operator}
+		}
+		return tx.Create(&groupRoles).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.dropGroupSessions(ctx, group)
+	return roles, nil
+}
+
+// ListRoles 查询用户组直接被授予的角色
+func (s *UserGroupService) ListRoles(ctx context.Context, groupID int64) ([]entity.UserRole, error) {
+	group, err := s.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	var roles []entity.UserRole
+	if err := s.db.WithContext(ctx).
+		Where("id IN (?)", s.db.Model(&entity.UserGroupRole{}).Select("role_id").Where("group_id = ?", group.ID)).
+		Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// UserGroups 查询用户所在的全部组（含通过子组继承的祖先组）及其有效角色
+func (s *UserGroupService) UserGroups(ctx context.Context, userID int64) ([]UserGroupItem, []string, error) {
+	var user entity.User
+	if err := s.db.WithContext(ctx).Scopes(TenantScope(ctx, "tenant_id")).First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("用户不存在")
+		}
+		return nil, nil, err
+	}
+
+	groups, direct, err := userGroupClosure(ctx, s.db, &user)
+	if err != nil {
+		return nil, nil, err
+	}
+	items := make([]UserGroupItem, 0, len(groups))
+	for _, g := range groups {
+		_, isDirect := direct[g.ID]
+		items = append(items, UserGroupItem{UserGroup: g, Direct: isDirect})
+	}
+
+	roles, err := effectiveRoles(ctx, s.db, &user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, roles, nil
+}
+
+// checkParent 校验上级组存在、属于同一租户且不会形成环
+func (s *UserGroupService) checkParent(ctx context.Context, group *entity.UserGroup, parentID int64) error {
+	if parentID == group.ID {
+		return fmt.Errorf("不能将用户组设为自己的上级")
+	}
+	parent, err := s.GetByID(ctx, parentID)
+	if err != nil {
+		return fmt.Errorf("上级组不存在")
+	}
+	if parent.TenantID != group.TenantID {
+		return fmt.Errorf("上级组不属于同一租户")
+	}
+
+	parents, err := tenantGroupParents(ctx, s.db, group.TenantID)
+	if err != nil {
+		return err
+	}
+	for cur, ok := parentID, true; ok; cur, ok = parents[cur] {
+		if cur == group.ID {
+			return fmt.Errorf("上级组设置会形成循环嵌套")
+		}
+	}
+	return nil
+}
+
+// dropGroupSessions 删除该组及其全部子孙组直接成员的会话
+func (s *UserGroupService) dropGroupSessions(ctx context.Context, group *entity.UserGroup) {
+	parents, err := tenantGroupParents(ctx, s.db, group.TenantID)
+	if err != nil {
+		log.Printf("查询用户组层级失败: %v", err)
+		return
+	}
+	groupIDs := []int64{group.ID}
+	for id := range parents {
+		// visited 检查兼顾防御脏数据中的环
+		seen := make(map[int64]struct{})
+		for cur, ok := parents[id], true; ok; cur, ok = parents[cur] {
+			if _, loop := seen[cur]; loop {
+				break
+			}
+			seen[cur] = struct{}{}
+			if cur == group.ID {
+				groupIDs = append(groupIDs, id)
+				break
+			}
+		}
+	}
+
+	var userIDs []int64
+	if err := s.db.WithContext(ctx).Model(&entity.UserGroupMember{}).
+		Where("group_id IN ?", groupIDs).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		log.Printf("查询用户组成员失败: %v", err)
+		return
+	}
+	s.dropSessions(ctx, userIDs)
+}
+
+// dropSessions 删除用户的登录会话，失败只记录日志
+func (s *UserGroupService) dropSessions(ctx context.Context, userIDs []int64) {
+	if len(userIDs) == 0 {
+		return
+	}
+	var usernames []string
+	if err := s.db.WithContext(ctx).Model(&entity.User{}).
+		Where("id IN ?", userIDs).
+		Pluck("username", &usernames).Error; err != nil {
+		log.Printf("查询用户失败: %v", err)
+		return
+	}
+	if len(usernames) == 0 {
+		return
+	}
+	if err := s.rdb.Del(context.Background(), usernames...).Err(); err != nil {
+		log.Printf("删除用户会话失败: %v", err)
+	}
+}
+
+// tenantGroupParents 加载租户内全部用户组的上级关系 groupID -> parentID
+func tenantGroupParents(ctx context.Context, db *gorm.DB, tenantID int64) (map[int64]int64, error) {
+	var groups []entity.UserGroup
+	if err := db.WithContext(ctx).
+		Select("id", "parent_id").
+		Where("tenant_id = ? AND deleted = 0", tenantID).
+		Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	parents := make(map[int64]int64, len(groups))
+	for _, g := range groups {
+		if g.ParentID.Valid {
+			parents[g.ID] = g.ParentID.Int64
+		}
+	}
+	return parents, nil
+}
+
+// userGroupClosure 返回用户直接所在的组及其全部祖先组，direct 为直接所在组的集合
+func userGroupClosure(ctx context.Context, db *gorm.DB, user *entity.User) ([]entity.UserGroup, map[int64]struct{}, error) {
+	var directIDs []int64
+	if err := db.WithContext(ctx).Model(&entity.UserGroupMember{}).
+		Where("user_id = ?", user.ID).
+		Pluck("group_id", &directIDs).Error; err != nil {
+		return nil, nil, err
+	}
+	direct := make(map[int64]struct{}, len(directIDs))
+	for _, id := range directIDs {
+		direct[id] = struct{}{}
+	}
+	if len(directIDs) == 0 {
+		return nil, direct, nil
+	}
+
+	parents, err := tenantGroupParents(ctx, db, user.TenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	all := make(map[int64]struct{})
+	for _, id := range directIDs {
+		// visited 检查兼顾防御脏数据中的环
+		for cur, ok := id, true; ok; cur, ok = parents[cur] {
+			if _, seen := all[cur]; seen {
+				break
+			}
+			all[cur] = struct{}{}
+		}
+	}
+	ids := make([]int64, 0, len(all))
+	for id := range all {
+		ids = append(ids, id)
+	}
+
+	var groups []entity.UserGroup
+	if err := db.WithContext(ctx).
+		Where("id IN ? AND tenant_id = ? AND deleted = 0", ids, user.TenantID).
+		Order("id").
+		Find(&groups).Error; err != nil {
+		return nil, nil, err
+	}
+	return groups, direct, nil
+}
+
+// effectiveRoles 计算用户的有效角色：自身角色与所在组（含祖先组）角色的并集
+func effectiveRoles(ctx context.Context, db *gorm.DB, user *entity.User) ([]string, error) {
+	set := make(map[string]struct{})
+	for _, r := range user.GetAuthorities() {
+		set[r] = struct{}{}
+	}
+
+	groups, _, err := userGroupClosure(ctx, db, user)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) > 0 {
+		groupIDs := make([]int64, len(groups))
+		for i, g := range groups {
+			groupIDs[i] = g.ID
+		}
+		var names []string
+		if err := db.WithContext(ctx).Model(&entity.UserRole{}).
+			Where("id IN (?)", db.Model(&entity.UserGroupRole{}).Select("role_id").Where("group_id IN ?", groupIDs)).
+			Pluck("role_name", &names).Error; err != nil {
+			return nil, err
+		}
+		for _, n := range names {
+			set[n] = struct{}{}
+		}
+	}
+
+	roles := make([]string, 0, len(set))
+	for r := range set {
+		roles = append(roles, r)
+	}
+	sort.Strings(roles)
+	return roles, nil
+}
+
+// uniqueIDs 去除重复的 ID，保持原有顺序
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]struct{}, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,548 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/go-redis/redis/v8"
+
+	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/pkg/blob"
+	"github.com/bryantaolong/system/pkg/xlsx"
+)
+
+// 导入任务状态
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// 导入文件的列，表头不区分大小写，也可以使用中文列名；扩展属性列以 attr. 开头
+const (
+	importUsername = "username"
+	importPassword = "password"
+	importPhone    = "phone"
+	importEmail    = "email"
+	importOrgCode  = "orgcode"
+	importRealName = "realname"
+	importGender   = "gender"
+	importBirthday = "birthday"
+	importAvatar   = "avatar"
+
+	importAttrPrefix = "attr."
+	// 错误报告附加的列，再次导入修正后的报告时忽略
+	importRowColumn   = "row"
+	importErrorColumn = "error"
+)
+
+var importColumnAliases = map[string]string{
+	"用户名":  importUsername,
+	"密码":   importPassword,
+	"手机号":  importPhone,
+	"电话":   importPhone,
+	"邮箱":   importEmail,
+	"组织编码": importOrgCode,
+	"姓名":   importRealName,
+	"性别":   importGender,
+	"生日":   importBirthday,
+	"头像":   importAvatar,
+}
+
+var importGenders = map[string]int{"未知": 0, "男": 1, "女": 2}
+
+// importProgressEvery 每处理多少行保存一次任务进度
+const importProgressEvery = 50
+
+// ImportOptions 导入配置
+type ImportOptions struct {
+	MaxSize      int64         // 上传文件最大字节数
+	MaxRows      int           // 单个文件最多导入的行数
+	JobTTL       time.Duration // 任务状态及错误报告的保留时间
+	ReportExpiry time.Duration // 错误报告下载地址有效期
+}
+
+// ImportTable 解析后的导入文件
+type ImportTable struct {
+	Header  []string   // 原始表头
+	Rows    [][]string // 数据行，不含表头
+	columns []string   // 每列对应的字段，被忽略的列为空
+}
+
+// ImportRowError 单行导入失败的原因，Row 为文件中的行号（表头为第 1 行）
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ImportSummary 导入结果
+type ImportSummary struct {
+	Total     int              `json:"total"`
+	Processed int              `json:"processed"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Errors    []ImportRowError `json:"-"`
+}
+
+// ImportJob 后台导入任务，保存在 Redis 中
+type ImportJob struct {
+	ID        string `json:"id"`
+	TenantID  int64  `json:"tenantId"`
+	Status    string `json:"status"`
+	DryRun    bool   `json:"dryRun"`
+	CreatedBy string `json:"createdBy"`
+	ImportSummary
+	Error      string     `json:"error,omitempty"`     // 任务整体失败的原因
+	HasReport  bool       `json:"hasReport"`           // 是否生成了错误报告
+	ReportURL  string     `json:"reportUrl,omitempty"` // 错误报告的签名下载地址
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// UserImportService 从 CSV/XLSX 文件批量导入用户，校验规则与注册相同
+type UserImportService struct {
+	auth     *AuthService
+	profiles *UserProfileService
+	rdb      *redis.Client
+	store    blob.Store
+	opts     ImportOptions
+}
+
+// NewUserImportService 创建并返回一个 UserImportService 实例
+func NewUserImportService(auth *AuthService, profiles *UserProfileService, rdb *redis.Client, store blob.Store, opts ImportOptions) *UserImportService {
+	return &UserImportService{auth: auth, profiles: profiles, rdb: rdb, store: store, opts: opts}
+}
+
+// MaxSize 返回导入文件的最大字节数
+func (s *UserImportService) MaxSize() int64 {
+	return s.opts.MaxSize
+}
+
+// ParseImportFile 按内容识别并解析 CSV（UTF-8，可带 BOM）或 XLSX（第一个工作表）文件，检查表头和行数
+func (s *UserImportService) ParseImportFile(data []byte) (*ImportTable, error) {
+	var rows [][]string
+	if xlsx.IsXLSX(data) {
+		var err error
+		if rows, err = xlsx.ReadRows(data); err != nil {
+			return nil, err
+		}
+	} else {
+		data = bytes.TrimPrefix(data, []byte("\ufeff"))
+		if !utf8.Valid(data) {
+			return nil, fmt.Errorf("CSV 文件必须使用 UTF-8 编码")
+		}
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+		var err error
+		if rows, err = r.ReadAll(); err != nil {
+			return nil, fmt.Errorf("CSV 格式错误: %w", err)
+		}
+	}
+
+	// 去除空行
+	table := &ImportTable{}
+	for i, row := range rows {
+		if isBlankRow(row) {
+			continue
+		}
+		if table.Header == nil {
+			table.Header = row
+			continue
+		}
+		// 补齐到表头长度，并记下原始行号
+		row = append(row, make([]string, max(0, len(table.Header)-len(row)))...)
+		table.Rows = append(table.Rows, append([]string{strconv.Itoa(i + 1)}, row...))
+	}
+	if table.Header == nil {
+		return nil, fmt.Errorf("文件为空")
+	}
+	if err := table.mapColumns(); err != nil {
+		return nil, err
+	}
+	if len(table.Rows) == 0 {
+		return nil, fmt.Errorf("文件中没有数据行")
+	}
+	if s.opts.MaxRows > 0 && len(table.Rows) > s.opts.MaxRows {
+		return nil, fmt.Errorf("单次最多导入 %d 行", s.opts.MaxRows)
+	}
+	return table, nil
+}
+
+// mapColumns 将表头映射到字段，用户名、密码列必须存在，忽略表头为空的列；
+// 不认识的列直接报错，避免数据被静默丢弃
+func (t *ImportTable) mapColumns() error {
+	t.columns = make([]string, len(t.Header))
+	seen := make(map[string]bool, len(t.Header))
+	var unknown []string
+	for i, name := range t.Header {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if alias, ok := importColumnAliases[name]; ok {
+			key = alias
+		}
+		switch {
+		case key == "" || key == importRowColumn || key == importErrorColumn:
+			continue
+		case strings.HasPrefix(key, importAttrPrefix):
+			attr := name[len(importAttrPrefix):]
+			if !attributeNamePattern.MatchString(attr) {
+				unknown = append(unknown, name)
+				continue
+			}
+			key = importAttrPrefix + attr
+		case key == importUsername, key == importPassword, key == importPhone, key == importEmail,
+			key == importOrgCode, key == importRealName, key == importGender, key == importBirthday, key == importAvatar:
+		default:
+			unknown = append(unknown, name)
+			continue
+		}
+		if seen[key] {
+			return fmt.Errorf("列 %s 重复", name)
+		}
+		seen[key] = true
+		t.columns[i] = key
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("无法识别的列: %s", strings.Join(unknown, ", "))
+	}
+	if !seen[importUsername] || !seen[importPassword] {
+		return fmt.Errorf("缺少用户名（username）或密码（password）列")
+	}
+	return nil
+}
+
+// Start 创建后台导入任务并立即返回，进度通过 GetJob 查询
+func (s *UserImportService) Start(ctx context.Context, table *ImportTable, dryRun bool) (*ImportJob, error) {
+	id, err := newImportJobID()
+	if err != nil {
+		return nil, err
+	}
+	job := &ImportJob{
+		ID:            id,
+		Status:        ImportPending,
+		DryRun:        dryRun,
+		CreatedBy:     currentOperator(ctx),
+		ImportSummary: ImportSummary{Total: len(table.Rows)},
+		CreatedAt:     time.Now(),
+	}
+	if claims := currentClaims(ctx); claims != nil {
+		job.TenantID = claims.TenantId
+	}
+	if err := s.saveJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// 请求结束后 gin.Context 会被复用，后台任务使用其副本，保留登录信息用于租户限定和审计
+	if c, ok := ctx.(*gin.Context); ok {
+		ctx = c.Copy()
+	}
+	started := *job
+	go s.runJob(ctx, job, table)
+	return &started, nil
+}
+
+// GetJob 查询导入任务，只能查看本租户的任务；存在错误报告时附带签名下载地址
+func (s *UserImportService) GetJob(ctx context.Context, id string) (*ImportJob, error) {
+	data, err := s.rdb.Get(ctx, importJobKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("导入任务不存在或已过期")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询导入任务失败: %w", err)
+	}
+	var job ImportJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("导入任务数据错误: %w", err)
+	}
+	if tenantID, ok := tenantFilter(ctx); ok && tenantID != job.TenantID {
+		return nil, fmt.Errorf("导入任务不存在或已过期")
+	}
+	if job.HasReport {
+		if job.ReportURL, err = s.store.SignedURL(ctx, importReportKey(job.ID), s.opts.ReportExpiry); err != nil {
+			return nil, err
+		}
+	}
+	return &job, nil
+}
+
+// Run 逐行导入，单行失败不影响其他行；progress 在处理过程中被周期性调用
+func (s *UserImportService) Run(ctx context.Context, table *ImportTable, dryRun bool, progress func(ImportSummary)) ImportSummary {
+	summary := ImportSummary{Total: len(table.Rows)}
+	usernames := make(map[string]int, len(table.Rows))
+	for _, row := range table.Rows {
+		line, _ := strconv.Atoi(row[0])
+		if err := s.importRow(ctx, table, row[1:], usernames, line, dryRun); err != nil {
+			summary.Failed++
+			summary.Errors = append(summary.Errors, ImportRowError{Row: line, Message: err.Error()})
+		} else {
+			summary.Succeeded++
+		}
+		summary.Processed++
+		if progress != nil && summary.Processed%importProgressEvery == 0 {
+			progress(summary)
+		}
+	}
+	return summary
+}
+
+// WriteReport 写出错误报告：CSV 格式，每个失败行一条，包含行号、原始各列和失败原因。
+// 报告中不保留密码，补填密码并修正后可以直接再次导入
+func (s *UserImportService) WriteReport(w io.Writer, table *ImportTable, summary ImportSummary) error {
+	// UTF-8 BOM，保证 Excel 正确识别中文
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	header := []string{importRowColumn}
+	for i, name := range table.Header {
+		if table.columns[i] != "" {
+			header = append(header, name)
+		}
+	}
+	if err := cw.Write(append(header, importErrorColumn)); err != nil {
+		return err
+	}
+
+	rows := make(map[string][]string, len(table.Rows))
+	for _, row := range table.Rows {
+		rows[row[0]] = row
+	}
+	for _, e := range summary.Errors {
+		row := rows[strconv.Itoa(e.Row)]
+		record := []string{row[0]}
+		for i, v := range row[1:] {
+			if i >= len(table.columns) || table.columns[i] == "" {
+				continue
+			}
+			if table.columns[i] == importPassword {
+				v = ""
+			}
+			record = append(record, v)
+		}
+		if err := cw.Write(append(record, e.Message)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// runJob 在后台执行导入任务，结束后写入错误报告
+func (s *UserImportService) runJob(ctx context.Context, job *ImportJob, table *ImportTable) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ 导入任务 %s 异常: %v", job.ID, r)
+			s.finishJob(ctx, job, fmt.Errorf("导入任务异常终止"))
+		}
+	}()
+
+	job.Status = ImportRunning
+	s.saveJobQuietly(ctx, job)
+	summary := s.Run(ctx, table, job.DryRun, func(progress ImportSummary) {
+		job.ImportSummary = progress
+		s.saveJobQuietly(ctx, job)
+	})
+	job.ImportSummary = summary
+
+	var err error
+	if summary.Failed > 0 {
+		var buf bytes.Buffer
+		if err = s.WriteReport(&buf, table, summary); err == nil {
+			err = s.store.Put(ctx, importReportKey(job.ID), buf.Bytes(), "text/csv; charset=utf-8")
+		}
+		if err != nil {
+			err = fmt.Errorf("保存错误报告失败: %w", err)
+		} else {
+			job.HasReport = true
+		}
+	}
+	s.finishJob(ctx, job, err)
+}
+
+func (s *UserImportService) finishJob(ctx context.Context, job *ImportJob, err error) {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = ImportCompleted
+	if err != nil {
+		job.Status = ImportFailed
+		job.Error = err.Error()
+	}
+	s.saveJobQuietly(ctx, job)
+}
+
+// importRow 校验并导入一行，错误信息直接写入错误报告
+func (s *UserImportService) importRow(ctx context.Context, table *ImportTable, row []string, usernames map[string]int, line int, dryRun bool) error {
+	var req request.RegisterRequest
+	var profile request.UserProfileRequest
+	for i, key := range table.columns {
+		v := strings.TrimSpace(row[i])
+		if key == "" || v == "" {
+			continue
+		}
+		switch key {
+		case importUsername:
+			req.Username = v
+		case importPassword:
+			req.Password = row[i] // 密码保留首尾空格
+		case importPhone:
+			req.Phone = v
+		case importEmail:
+			req.Email = v
+		case importOrgCode:
+			req.OrgCode = v
+		case importRealName:
+			profile.RealName = v
+		case importGender:
+			gender, err := parseImportGender(v)
+			if err != nil {
+				return err
+			}
+			profile.Gender = &gender
+		case importBirthday:
+			profile.Birthday = parseImportDate(v)
+		case importAvatar:
+			profile.Avatar = v
+		default:
+			if req.Attributes == nil {
+				req.Attributes = make(map[string]interface{})
+			}
+			req.Attributes[strings.TrimPrefix(key, importAttrPrefix)] = parseImportValue(v)
+		}
+	}
+
+	if err := validateImport(&req, request.RegisterRequestValidationMessages); err != nil {
+		return err
+	}
+	if err := validateImport(&profile, request.UserProfileRequestValidationMessages); err != nil {
+		return err
+	}
+	if _, err := parseBirthday(profile.Birthday); err != nil {
+		return err
+	}
+	if first, ok := usernames[req.Username]; ok {
+		return fmt.Errorf("用户名与第 %d 行重复", first)
+	}
+	usernames[req.Username] = line
+
+	user, err := s.auth.ImportUser(ctx, req, dryRun)
+	if err != nil {
+		return err
+	}
+	if dryRun || profile == (request.UserProfileRequest{}) {
+		return nil
+	}
+	if _, err := s.profiles.SaveProfile(ctx, user.ID, profile); err != nil {
+		return fmt.Errorf("用户已创建，保存资料失败: %w", err)
+	}
+	return nil
+}
+
+// validateImport 使用与请求绑定相同的校验器校验，错误转换为请求定义的中文提示
+func validateImport(obj interface{}, messages map[string]string) error {
+	err := binding.Validator.ValidateStruct(obj)
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	msgs := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		if msg, ok := messages[fe.Field()+"."+fe.Tag()]; ok {
+			msgs = append(msgs, msg)
+		} else {
+			msgs = append(msgs, fe.Error())
+		}
+	}
+	return errors.New(strings.Join(msgs, "；"))
+}
+
+// parseImportGender 性别可以填写 0/1/2 或 未知/男/女
+func parseImportGender(v string) (int, error) {
+	if g, ok := importGenders[v]; ok {
+		return g, nil
+	}
+	g, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("性别只能是0（未知）、1（男）或2（女）")
+	}
+	return g, nil
+}
+
+// parseImportDate 日期为 yyyy-MM-dd 或 yyyy/MM/dd，XLSX 中的日期单元格为 Excel 序列号
+func parseImportDate(v string) string {
+	if serial, err := strconv.ParseFloat(v, 64); err == nil && serial > 0 && serial < 100000 {
+		return xlsx.DateFromSerial(serial).Format(birthdayLayout)
+	}
+	if t, err := time.Parse("2006/1/2", v); err == nil {
+		return t.Format(birthdayLayout)
+	}
+	return v
+}
+
+// parseImportValue 扩展属性值为合法 JSON（数字、true/false、数组、对象、带引号的字符串）时按 JSON 解析，
+// 否则作为字符串
+func parseImportValue(v string) interface{} {
+	var out interface{}
+	if err := json.Unmarshal([]byte(v), &out); err == nil && out != nil {
+		return out
+	}
+	return v
+}
+
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *UserImportService) saveJob(ctx context.Context, job *ImportJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if err := s.rdb.Set(ctx, importJobKey(job.ID), data, s.opts.JobTTL).Err(); err != nil {
+		return fmt.Errorf("保存导入任务失败: %w", err)
+	}
+	return nil
+}
+
+// saveJobQuietly 后台任务中保存进度，失败只记录日志
+func (s *UserImportService) saveJobQuietly(ctx context.Context, job *ImportJob) {
+	if err := s.saveJob(ctx, job); err != nil {
+		log.Printf("⚠️ 导入任务 %s: %v", job.ID, err)
+	}
+}
+
+func newImportJobID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func importJobKey(id string) string {
+	return "user:import:" + id
+}
+
+func importReportKey(id string) string {
+	return "imports/" + id + "/errors.csv"
+}
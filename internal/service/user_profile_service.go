@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+	"github.com/bryantaolong/system/internal/model/request"
+	"github.com/bryantaolong/system/pkg/blob"
+	"github.com/bryantaolong/system/pkg/imaging"
+)
+
+// birthdayLayout 生日格式
+const birthdayLayout = "2006-01-02"
+
+// minBirthday 允许的最早生日
+var minBirthday = time.Date(1900, 1, 1, 0, 0, 0, 0, time.Local)
+
+// UserDetail 用户信息及按需附带的关联数据
+type UserDetail struct {
+	*entity.User
+	Profile *entity.UserProfile `json:"profile,omitempty"`
+}
+
+// AvatarOptions 头像上传配置
+type AvatarOptions struct {
+	MaxSize   int64         // 上传文件最大字节数
+	MaxPixels int           // 原图最大像素数，0 表示使用 imaging.DefaultMaxPixels
+	Sizes     []int         // 生成的正方形缩略图边长
+	Quality   int           // 缩略图 JPEG 质量
+	URLExpiry time.Duration // 签名访问地址有效期
+}
+
+// UserProfileService 用户资料（姓名、性别、生日、头像）的查询与保存，上传的头像保存在对象存储中
+type UserProfileService struct {
+	db     *gorm.DB
+	users  *UserService
+	audit  *AuditService
+	store  blob.Store
+	avatar AvatarOptions
+}
+
+// NewUserProfileService 创建并返回一个 UserProfileService 实例
+func NewUserProfileService(db *gorm.DB, users *UserService, audit *AuditService, store blob.Store, avatar AvatarOptions) *UserProfileService {
+	return &UserProfileService{db: db, users: users, audit: audit, store: store, avatar: avatar}
+}
+
+// AvatarMaxSize 返回头像上传的最大字节数
+func (s *UserProfileService) AvatarMaxSize() int64 {
+	return s.avatar.MaxSize
+}
+
+// GetProfile 获取用户资料，尚未填写时返回空资料
+func (s *UserProfileService) GetProfile(ctx context.Context, userID int64) (*entity.UserProfile, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	profile, _, err := s.find(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.signAvatar(ctx, profile)
+	return profile, nil
+}
+
+// GetOwnProfile 获取当前登录用户的资料
+func (s *UserProfileService) GetOwnProfile(ctx context.Context) (*entity.UserProfile, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProfile(ctx, userID)
+}
+
+// WithProfile 在已加载的用户信息上附带资料
+func (s *UserProfileService) WithProfile(ctx context.Context, user *entity.User) (*UserDetail, error) {
+	profile, _, err := s.find(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.signAvatar(ctx, profile)
+	return &UserDetail{User: user, Profile: profile}, nil
+}
+
+// SaveProfile 保存用户资料，不存在时创建；请求中为空的字段保持不变
+func (s *UserProfileService) SaveProfile(ctx context.Context, userID int64, req request.UserProfileRequest) (*entity.UserProfile, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	birthday, err := parseBirthday(req.Birthday)
+	if err != nil {
+		return nil, err
+	}
+	if req.Gender != nil && !entity.ValidGender(*req.Gender) {
+		return nil, fmt.Errorf("性别只能是0（未知）、1（男）或2（女）")
+	}
+
+	profile, exists, err := s.find(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	before := *profile
+	if req.RealName != "" {
+		profile.RealName = req.RealName
+	}
+	if req.Gender != nil {
+		profile.Gender = *req.Gender
+	}
+	if birthday.Valid {
+		profile.Birthday = birthday
+	}
+	if req.Avatar != "" {
+		profile.Avatar = req.Avatar
+	}
+
+	if err := s.persist(ctx, user, &before, profile, exists); err != nil {
+		return nil, err
+	}
+	s.signAvatar(ctx, profile)
+	return profile, nil
+}
+
+// SaveOwnProfile 当前登录用户保存自己的资料
+func (s *UserProfileService) SaveOwnProfile(ctx context.Context, req request.UserProfileRequest) (*entity.UserProfile, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.SaveProfile(ctx, userID, req)
+}
+
+// UploadAvatar 上传头像：按内容识别图片格式，生成各尺寸的正方形 JPEG 缩略图（同时去除 EXIF 等元数据）
+// 写入对象存储，保存成功后删除旧头像
+func (s *UserProfileService) UploadAvatar(ctx context.Context, userID int64, data []byte) (*entity.UserProfile, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if s.avatar.MaxSize > 0 && int64(len(data)) > s.avatar.MaxSize {
+		return nil, fmt.Errorf("头像文件不能超过 %d KB", s.avatar.MaxSize/1024)
+	}
+	img, err := imaging.Decode(data, s.avatar.MaxPixels)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newAvatarKey(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, size := range s.avatar.Sizes {
+		thumb, err := imaging.EncodeJPEG(imaging.Thumbnail(img, size), s.avatar.Quality)
+		if err != nil {
+			s.deleteAvatar(ctx, key)
+			return nil, fmt.Errorf("生成头像失败: %w", err)
+		}
+		if err := s.store.Put(ctx, avatarObject(key, size), thumb, imaging.TypeJPEG); err != nil {
+			s.deleteAvatar(ctx, key)
+			return nil, fmt.Errorf("保存头像失败: %w", err)
+		}
+	}
+
+	profile, exists, err := s.find(ctx, user.ID)
+	if err != nil {
+		s.deleteAvatar(ctx, key)
+		return nil, err
+	}
+	before := *profile
+	profile.AvatarKey = key
+	if err := s.persist(ctx, user, &before, profile, exists); err != nil {
+		s.deleteAvatar(ctx, key)
+		return nil, err
+	}
+	s.deleteAvatar(ctx, before.AvatarKey)
+	s.signAvatar(ctx, profile)
+	return profile, nil
+}
+
+// UploadOwnAvatar 当前登录用户上传自己的头像
+func (s *UserProfileService) UploadOwnAvatar(ctx context.Context, data []byte) (*entity.UserProfile, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.UploadAvatar(ctx, userID, data)
+}
+
+// RemoveAvatar 删除上传的头像，外部头像地址不受影响
+func (s *UserProfileService) RemoveAvatar(ctx context.Context, userID int64) (*entity.UserProfile, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	profile, exists, err := s.find(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if profile.AvatarKey == "" {
+		return profile, nil
+	}
+	before := *profile
+	profile.AvatarKey = ""
+	if err := s.persist(ctx, user, &before, profile, exists); err != nil {
+		return nil, err
+	}
+	s.deleteAvatar(ctx, before.AvatarKey)
+	return profile, nil
+}
+
+// RemoveOwnAvatar 当前登录用户删除自己上传的头像
+func (s *UserProfileService) RemoveOwnAvatar(ctx context.Context) (*entity.UserProfile, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.RemoveAvatar(ctx, userID)
+}
+
+// persist 保存资料并写入审计事件，exists 为 false 时新建；已存在且没有变化时不写入
+func (s *UserProfileService) persist(ctx context.Context, user *entity.User, before, profile *entity.UserProfile, exists bool) error {
+	changes := diffFields(before, profile)
+	if exists && len(changes) == 0 {
+		return nil
+	}
+	event := newAuditEvent(ctx, entity.AuditUserProfile, nil, nil)
+	setAuditTarget(event, user, nil)
+	if data, err := json.Marshal(changes); err == nil {
+		event.Changes = string(data)
+	}
+
+	operator := currentOperator(ctx)
+	return s.audit.Transaction(ctx, func(tx *gorm.DB, record func(*entity.AuditEvent)) error {
+		profile.UpdatedBy = operator
+		if !exists {
+			profile.CreatedBy = operator
+			if err := tx.Create(profile).Error; err != nil {
+				return err
+			}
+		} else {
+			// 乐观锁：读取之后被他人修改过则放弃本次保存
+			profile.Version = before.Version + 1
+			res := tx.Model(profile).
+				Where("version = ?", before.Version).
+				Select("real_name", "gender", "birthday", "avatar", "avatar_key", "deleted", "version", "updated_at", "updated_by").
+				Updates(profile)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return fmt.Errorf("资料已被修改，请刷新后重试")
+			}
+		}
+		record(event)
+		return nil
+	})
+}
+
+// signAvatar 为上传的头像生成各尺寸的签名访问地址
+func (s *UserProfileService) signAvatar(ctx context.Context, profile *entity.UserProfile) {
+	if profile.AvatarKey == "" {
+		return
+	}
+	urls := make(map[string]string, len(s.avatar.Sizes))
+	for _, size := range s.avatar.Sizes {
+		u, err := s.store.SignedURL(ctx, avatarObject(profile.AvatarKey, size), s.avatar.URLExpiry)
+		if err != nil {
+			log.Printf("生成头像访问地址失败: %v", err)
+			continue
+		}
+		urls[strconv.Itoa(size)] = u
+	}
+	profile.AvatarURLs = urls
+}
+
+// deleteAvatar 删除头像的全部缩略图，失败只记录日志
+func (s *UserProfileService) deleteAvatar(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	for _, size := range s.avatar.Sizes {
+		if err := s.store.Delete(ctx, avatarObject(key, size)); err != nil {
+			log.Printf("删除头像失败: %v", err)
+		}
+	}
+}
+
+// newAvatarKey 为每次上传生成新的 key 前缀，旧地址不会指向新头像，也便于 CDN 缓存
+func newAvatarKey(userID int64) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("avatars/%d/%s", userID, hex.EncodeToString(b)), nil
+}
+
+func avatarObject(key string, size int) string {
+	return fmt.Sprintf("%s/%d.jpg", key, size)
+}
+
+// find 查询用户资料，exists 表示表中已有记录；已软删除的资料视为空资料，保存时复用原记录
+func (s *UserProfileService) find(ctx context.Context, userID int64) (profile *entity.UserProfile, exists bool, err error) {
+	var p entity.UserProfile
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).First(&p).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &entity.UserProfile{UserID: userID}, false, nil
+		}
+		return nil, false, fmt.Errorf("查询用户资料失败: %w", err)
+	}
+	if p.Deleted != 0 {
+		p = entity.UserProfile{UserID: userID, Version: p.Version, CreatedAt: p.CreatedAt, CreatedBy: p.CreatedBy}
+	}
+	return &p, true, nil
+}
+
+// parseBirthday 解析并校验生日，不能早于 1900 年，也不能晚于今天
+func parseBirthday(s string) (sql.NullTime, error) {
+	if s == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.ParseInLocation(birthdayLayout, s, time.Local)
+	if err != nil {
+		return sql.NullTime{}, fmt.Errorf("生日格式应为yyyy-MM-dd")
+	}
+	if t.Before(minBirthday) || t.After(time.Now()) {
+		return sql.NullTime{}, fmt.Errorf("生日不在有效范围内")
+	}
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
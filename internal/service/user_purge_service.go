@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/bryantaolong/system/internal/model/entity"
+)
+
+const (
+	userPurgeBatchSize = 100
+	userPurgeLockKey   = "user:purge:lock"
+)
+
+// UserPurgeOptions 已删除用户的保留策略
+type UserPurgeOptions struct {
+	Retention time.Duration // 删除后保留的时长，超过后彻底清除；为 0 时不清除
+	Interval  time.Duration // 检查间隔
+}
+
+// UserPurgeService 定期彻底清除超过保留期的已删除用户及其关联数据
+type UserPurgeService struct {
+	db       *gorm.DB
+	audit    *AuditService
+	profiles *UserProfileService
+	rdb      *redis.Client
+	opts     UserPurgeOptions
+}
+
+// NewUserPurgeService 创建并返回一个 UserPurgeService 实例
+func NewUserPurgeService(db *gorm.DB, audit *AuditService, profiles *UserProfileService, rdb *redis.Client, opts UserPurgeOptions) *UserPurgeService {
+	return &UserPurgeService{db: db, audit: audit, profiles: profiles, rdb: rdb, opts: opts}
+}
+
+// Start 在后台每隔 Interval 清除一次超过保留期的用户，ctx 结束时停止。
+// 多个实例通过 Redis 锁保证同一周期内只有一个实例执行
+func (s *UserPurgeService) Start(ctx context.Context) {
+	if s.opts.Retention <= 0 || s.opts.Interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.opts.Interval)
+		defer ticker.Stop()
+		for {
+			s.runOnce(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *UserPurgeService) runOnce(ctx context.Context) {
+	ok, err := s.rdb.SetNX(ctx, userPurgeLockKey, time.Now().Unix(), s.opts.Interval).Result()
+	if err != nil {
+		log.Printf("获取用户清除锁失败: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	n, err := s.Purge(ctx, time.Now().Add(-s.opts.Retention))
+	if err != nil {
+		log.Printf("清除已删除用户失败（已清除 %d 个）: %v", n, err)
+		return
+	}
+	if n > 0 {
+		log.Printf("已彻底清除 %d 个超过保留期的已删除用户", n)
+	}
+}
+
+// Purge 彻底删除在 before 之前删除的用户，以及其资料、头像、用户组成员关系、历史密码、
+// 角色变更申请和登录日志。审计事件和模拟登录记录作为审计留存，不随用户删除。
+// 每批用户在一个事务中删除，并为每个用户记录一条审计事件；返回已清除的用户数
+func (s *UserPurgeService) Purge(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+	for {
+		var users []entity.User
+		// 早于 deleted_at 字段删除的用户没有删除时间，以最后更新时间代替
+		if err := s.db.WithContext(ctx).Unscoped().
+			Where("deleted = 1 AND COALESCE(deleted_at, updated_at, created_at) < ?", before).
+			Order("id").Limit(userPurgeBatchSize).Find(&users).Error; err != nil {
+			return purged, err
+		}
+		if len(users) == 0 {
+			return purged, nil
+		}
+
+		ids := make([]int64, len(users))
+		for i := range users {
+			ids[i] = users[i].ID
+		}
+		var avatars []string
+		if err := s.db.WithContext(ctx).Model(&entity.UserProfile{}).
+			Where("user_id IN ? AND avatar_key <> ''", ids).
+			Pluck("avatar_key", &avatars).Error; err != nil {
+			return purged, err
+		}
+
+		err := s.audit.Transaction(ctx, func(tx *gorm.DB, record func(*entity.AuditEvent)) error {
+			for _, model := range []interface{}{
+				&entity.UserProfile{},
+				&entity.UserGroupMember{},
+				&entity.PasswordHistory{},
+				&entity.RoleChangeRequest{},
+				&entity.LoginLog{},
+			} {
+				if err := tx.Where("user_id IN ?", ids).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+			result := tx.Where("id IN ? AND deleted = 1", ids).Delete(&entity.User{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected != int64(len(ids)) {
+				return fmt.Errorf("清除期间用户被恢复，稍后重试")
+			}
+			for i := range users {
+				event := newAuditEvent(ctx, entity.AuditUserPurge, &users[i], nil)
+				event.ActorName = "system"
+				event.Reason = fmt.Sprintf("deletedAt=%s retention=%s",
+					deletedTime(&users[i]).Format(time.RFC3339), s.opts.Retention)
+				record(event)
+			}
+			return nil
+		})
+		if err != nil {
+			return purged, err
+		}
+		for _, key := range avatars {
+			s.profiles.deleteAvatar(ctx, key)
+		}
+		purged += len(users)
+	}
+}
+
+// PurgeAt 返回已删除用户将被彻底清除的时间，不清除时返回零值
+func (s *UserPurgeService) PurgeAt(user *entity.User) time.Time {
+	if s.opts.Retention <= 0 || user.Deleted == 0 {
+		return time.Time{}
+	}
+	return deletedTime(user).Add(s.opts.Retention)
+}
+
+// deletedTime 返回用户的删除时间，没有记录时以最后更新时间代替
+func deletedTime(user *entity.User) time.Time {
+	switch {
+	case user.DeletedAt.Valid:
+		return user.DeletedAt.Time
+	case user.UpdatedAt.Valid:
+		return user.UpdatedAt.Time
+	default:
+		return user.CreatedAt
+	}
+}
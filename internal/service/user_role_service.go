@@ -19,7 +19,7 @@ func NewUserRoleService(db *gorm.DB) *UserRoleService {
 // ListAll 等价于 Java 的 listAll()
 func (s *UserRoleService) ListAll(ctx context.Context) ([]entity.UserRole, error) {
 	var roles []entity.UserRole
-	if err := s.db.WithContext(ctx).Find(&roles).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(SharedTenantScope(ctx, "tenant_id")).Find(&roles).Error; err != nil {
 		return nil, err
 	}
 
@@ -27,6 +27,7 @@ func (s *UserRoleService) ListAll(ctx context.Context) ([]entity.UserRole, error
 	for _, r := range roles {
 		userRoles = append(userRoles, entity.UserRole{
 			ID:       r.ID,
+			TenantID: r.TenantID,
 			RoleName: r.RoleName,
 		})
 	}
@@ -37,6 +38,7 @@ func (s *UserRoleService) ListAll(ctx context.Context) ([]entity.UserRole, error
 func (s *UserRoleService) FindByIds(ctx context.Context, ids []int64) ([]entity.UserRole, error) {
 	var roles []entity.UserRole
 	if err := s.db.WithContext(ctx).
+		Scopes(SharedTenantScope(ctx, "tenant_id")).
 		Where("id IN ?", ids).
 		Find(&roles).Error; err != nil {
 		return nil, err
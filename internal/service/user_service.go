@@ -3,8 +3,11 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"strconv"
 	"strings"
 	_ "strings"
 	"time"
@@ -13,17 +16,19 @@ import (
 
 	"github.com/bryantaolong/system/internal/model/entity"
 	"github.com/bryantaolong/system/internal/model/request"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/bryantaolong/system/pkg/jwt"
 	"gorm.io/gorm"
 )
 
 type UserService struct {
 	db          *gorm.DB
 	authService *AuthService
+	audit       *AuditService
+	attributes  *AttributeSchemaService
 }
 
-func NewUserService(db *gorm.DB, authService *AuthService) *UserService {
-	return &UserService{db: db, authService: authService}
+func NewUserService(db *gorm.DB, authService *AuthService, audit *AuditService, attributes *AttributeSchemaService) *UserService {
+	return &UserService{db: db, authService: authService, audit: audit, attributes: attributes}
 }
 
 // GetAllUsers 获取所有用户（分页）
@@ -32,10 +37,10 @@ func (s *UserService) GetAllUsers(ctx context.Context, page request.PageRequest)
 	var total int64
 
 	offset := page.GetOffset()
-	if err := s.db.WithContext(ctx).Model(&entity.User{}).Count(&total).Error; err != nil {
+	if err := s.db.WithContext(ctx).Model(&entity.User{}).Scopes(s.tenantScope(ctx)).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-	if err := s.db.WithContext(ctx).Limit(int(page.PageSize)).Offset(int(offset)).Find(&users).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(s.tenantScope(ctx)).Limit(int(page.PageSize)).Offset(int(offset)).Find(&users).Error; err != nil {
 		return nil, 0, err
 	}
 	return users, total, nil
@@ -44,7 +49,7 @@ func (s *UserService) GetAllUsers(ctx context.Context, page request.PageRequest)
 // GetUserByID 根据ID获取用户
 func (s *UserService) GetUserByID(ctx context.Context, userID int64) (*entity.User, error) {
 	var user entity.User
-	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(s.tenantScope(ctx)).First(&user, userID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, fmt.Errorf("用户不存在")
 		}
@@ -56,7 +61,7 @@ func (s *UserService) GetUserByID(ctx context.Context, userID int64) (*entity.Us
 // GetUserByUsername 根据用户名获取用户
 func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
 	var user entity.User
-	if err := s.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(s.tenantScope(ctx)).Where("username = ?", username).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -70,7 +75,7 @@ func (s *UserService) SearchUsers(ctx context.Context, req request.UserSearchReq
 	var users []entity.User
 	var total int64
 
-	query := s.db.WithContext(ctx).Model(&entity.User{})
+	query := s.db.WithContext(ctx).Model(&entity.User{}).Scopes(s.tenantScope(ctx))
 	query = s.buildSearchQuery(query, req)
 
 	if err := query.Count(&total).Error; err != nil {
@@ -94,6 +99,9 @@ func (s *UserService) buildSearchQuery(query *gorm.DB, req request.UserSearchReq
 	if req.Email != "" {
 		query = query.Where("email LIKE ?", "%"+req.Email+"%")
 	}
+	if req.TenantId != nil {
+		query = query.Where("tenant_id = ?", *req.TenantId)
+	}
 	if req.Roles != "" {
 		query = query.Where("roles LIKE ?", "%"+req.Roles+"%")
 	}
@@ -104,7 +112,8 @@ func (s *UserService) buildSearchQuery(query *gorm.DB, req request.UserSearchReq
 		query = query.Where("login_fail_count = ?", *req.LoginFailCount)
 	}
 	if req.Deleted != nil && *req.Deleted >= 0 {
-		query = query.Where("deleted = ?", *req.Deleted)
+		// 默认查询已排除删除的用户，显式指定删除标记时不再追加默认条件
+		query = query.Unscoped().Where("deleted = ?", *req.Deleted)
 	}
 	if !req.CreateTimeStart.IsZero() && !req.CreateTimeEnd.IsZero() {
 		query = query.Where("created_at BETWEEN ? AND ?", req.CreateTimeStart, req.CreateTimeEnd)
@@ -112,6 +121,11 @@ func (s *UserService) buildSearchQuery(query *gorm.DB, req request.UserSearchReq
 	if !req.UpdateTimeStart.IsZero() && !req.UpdateTimeEnd.IsZero() {
 		query = query.Where("updated_at BETWEEN ? AND ?", req.UpdateTimeStart, req.UpdateTimeEnd)
 	}
+	if len(req.Attributes) > 0 {
+		// JSONB 包含查询，可以使用 attributes 上的 GIN 索引
+		data, _ := json.Marshal(req.Attributes)
+		query = query.Where("attributes @> ?::jsonb", string(data))
+	}
 	return query
 }
 
@@ -121,10 +135,17 @@ func (s *UserService) UpdateUser(ctx context.Context, userID int64, req request.
 	if err != nil {
 		return nil, err
 	}
+	before := *user
 
 	if req.Username != "" && req.Username != user.Username {
-		existing, _ := s.GetUserByUsername(ctx, req.Username)
-		if existing != nil && existing.ID != userID {
+		// 用户名全局唯一，不受租户范围限制
+		var cnt int64
+		if err := s.db.WithContext(ctx).Model(&entity.User{}).
+			Where("username = ? AND id <> ?", req.Username, userID).
+			Count(&cnt).Error; err != nil {
+			return nil, err
+		}
+		if cnt > 0 {
 			return nil, fmt.Errorf("用户名已存在")
 		}
 		user.Username = req.Username
@@ -135,15 +156,26 @@ func (s *UserService) UpdateUser(ctx context.Context, userID int64, req request.
 	if req.Email != "" {
 		user.Email = req.Email
 	}
+	if len(req.Attributes) > 0 {
+		attrs := mergeAttributes(user.Attributes, req.Attributes)
+		if err := s.attributes.Validate(ctx, user.TenantID, attrs); err != nil {
+			return nil, err
+		}
+		user.Attributes = attrs
+	}
 
 	token := extractTokenFromContext(ctx)
 	operator, _ := s.authService.GetCurrentUsername(token)
 	user.UpdatedBy = operator
 	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	if err := s.db.WithContext(ctx).Save(user).Error; err != nil {
+	if err := s.audit.SaveUser(ctx, user, newAuditEvent(ctx, entity.AuditUserUpdate, &before, user)); err != nil {
 		return nil, err
 	}
+	// 会话按用户名保存，改名后旧会话无法再被封禁、删除等操作找到，需要立即删除
+	if before.Username != user.Username {
+		s.dropSession(before.Username)
+	}
 	return user, nil
 }
 
@@ -154,123 +186,183 @@ func (s *UserService) ChangeRoleByIds(ctx context.Context, userID int64, req req
 	if err != nil {
 		return nil, err
 	}
+	before := *user
+
+	// 2. 查询角色并拼接 roleName
+	roleNames, err := s.resolveRoleNames(ctx, user.TenantID, req.RoleIds)
+	if err != nil {
+		return nil, err
+	}
+	user.Roles = roleNames
 
-	// 2. 查询对应的 UserRole
+	// 3. 更新审计字段
+	token := extractTokenFromContext(ctx)
+	operator, _ := s.authService.GetCurrentUsername(token)
+	user.UpdatedBy = operator
+	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	// 4. 事务保存并记录审计事件
+	if err := s.audit.SaveUser(ctx, user, newAuditEvent(ctx, entity.AuditUserRole, &before, user)); err != nil {
+		return nil, err
+	}
+	// 角色保存在 Token 中，删除会话使被撤销的角色立即失效
+	s.dropSession(user.Username)
+	return user, nil
+}
+
+// resolveRoleNames 校验角色 ID 均存在且对目标租户可用，并返回以英文逗号分隔的角色名
+func (s *UserService) resolveRoleNames(ctx context.Context, tenantID int64, roleIds []int64) (string, error) {
 	var roles []entity.UserRole
 	if err := s.db.WithContext(ctx).
-		Where("id IN ?", req.RoleIds).
+		Where("id IN ? AND tenant_id IN ?", roleIds, []int64{entity.DefaultTenantID, tenantID}).
 		Find(&roles).Error; err != nil {
-		return nil, err
+		return "", err
 	}
 
-	// 3. 校验所有 id 都存在
-	if len(roles) != len(req.RoleIds) {
+	// 校验所有 id 都存在
+	if len(roles) != len(roleIds) {
 		exist := make(map[int64]struct{}, len(roles))
 		for _, r := range roles {
 			exist[r.ID] = struct{}{}
 		}
 		missing := make([]int64, 0)
-		for _, id := range req.RoleIds {
+		for _, id := range roleIds {
 			if _, ok := exist[id]; !ok {
 				missing = append(missing, id)
 			}
 		}
-		return nil, fmt.Errorf("角色不存在：%v", missing)
+		return "", fmt.Errorf("角色不存在：%v", missing)
 	}
 
-	// 4. 拼接 roleName -> 以英文逗号分隔
 	names := make([]string, len(roles))
 	for i, r := range roles {
 		names[i] = r.RoleName
 	}
-	user.Roles = strings.Join(names, ",")
-
-	// 5. 更新审计字段
-	token := extractTokenFromContext(ctx)
-	operator, _ := s.authService.GetCurrentUsername(token)
-	user.UpdatedBy = operator
-	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
-
-	// 6. 事务保存
-	tx := s.db.WithContext(ctx).Begin()
-	if err := tx.Save(user).Error; err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
-	}
-	return user, nil
+	return strings.Join(names, ","), nil
 }
 
-// ChangePassword 修改密码
+// ChangePassword 修改密码，成功后不再要求下次登录修改密码
 func (s *UserService) ChangePassword(ctx context.Context, userID int64, req request.ChangePasswordRequest) (*entity.User, error) {
 	user, err := s.GetUserByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	before := *user
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
+	if ok, _ := user.CheckPassword(s.authService.hasher, req.OldPassword); !ok {
 		return nil, fmt.Errorf("旧密码不正确")
 	}
+	if err := s.authService.CheckPassword(ctx, req.NewPassword, user); err != nil {
+		return nil, err
+	}
+	if err := s.authService.rotation.checkReuse(ctx, s.db, s.authService.hasher, user, req.NewPassword); err != nil {
+		return nil, err
+	}
 
-	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	hashed, err := s.authService.hasher.Hash(req.NewPassword)
 	if err != nil {
 		return nil, fmt.Errorf("密码加密失败")
 	}
 
-	user.Password = string(hashed)
+	user.Password = hashed
 	user.PasswordResetAt = sql.NullTime{Time: time.Now(), Valid: true}
+	user.MustChangePassword = false
 	token := extractTokenFromContext(ctx)
 	operator, _ := s.authService.GetCurrentUsername(token)
 	user.UpdatedBy = operator
 	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	if err := s.db.WithContext(ctx).Save(user).Error; err != nil {
+	if err := s.savePassword(ctx, user, before.Password, newAuditEvent(ctx, entity.AuditUserPassword, &before, user)); err != nil {
 		return nil, err
 	}
 	return user, nil
 }
 
-// ChangePasswordForcefully 管理员强制修改密码
+// ChangeOwnPassword 当前登录用户修改自己的密码并重新签发 Token，
+// 密码过期或被重置时登录返回的受限 Token 只能调用该接口
+func (s *UserService) ChangeOwnPassword(ctx context.Context, req request.ChangePasswordRequest) (*entity.User, string, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	user, err := s.ChangePassword(ctx, userID, req)
+	if err != nil {
+		return nil, "", err
+	}
+	token, err := s.authService.issueToken(ctx, user)
+	if err != nil {
+		return nil, "", err
+	}
+	return user, token, nil
+}
+
+// ChangePasswordForcefully 管理员强制修改密码，用户下次登录时必须修改密码
 func (s *UserService) ChangePasswordForcefully(ctx context.Context, userID int64, newPassword string) (*entity.User, error) {
 	user, err := s.GetUserByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	before := *user
+	if err := s.authService.CheckPassword(ctx, newPassword, user); err != nil {
+		return nil, err
+	}
+	if err := s.authService.rotation.checkReuse(ctx, s.db, s.authService.hasher, user, newPassword); err != nil {
+		return nil, err
+	}
 
-	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	hashed, err := s.authService.hasher.Hash(newPassword)
 	if err != nil {
 		return nil, fmt.Errorf("密码加密失败")
 	}
 
-	user.Password = string(hashed)
+	user.Password = hashed
 	user.PasswordResetAt = sql.NullTime{Time: time.Now(), Valid: true}
+	user.MustChangePassword = true
 	token := extractTokenFromContext(ctx)
 	operator, _ := s.authService.GetCurrentUsername(token)
 	user.UpdatedBy = operator
 	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	if err := s.db.WithContext(ctx).Save(user).Error; err != nil {
+	if err := s.savePassword(ctx, user, before.Password, newAuditEvent(ctx, entity.AuditUserPasswordForce, &before, user)); err != nil {
 		return nil, err
 	}
+	// 使已有会话失效，用户重新登录后只能先修改密码
+	if err := s.authService.redis.Del(context.Background(), user.Username).Err(); err != nil {
+		log.Printf("清除用户会话失败: %v", err)
+	}
 	return user, nil
 }
 
+// savePassword 保存新密码并在同一事务中记录旧密码哈希与审计事件
+func (s *UserService) savePassword(ctx context.Context, user *entity.User, oldHash string, event *entity.AuditEvent) error {
+	return s.audit.Transaction(ctx, func(tx *gorm.DB, record func(*entity.AuditEvent)) error {
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		if err := s.authService.rotation.remember(tx, user.ID, oldHash); err != nil {
+			return err
+		}
+		record(event)
+		return nil
+	})
+}
+
 // BlockUser 封禁用户
 func (s *UserService) BlockUser(ctx context.Context, userID int64) (*entity.User, error) {
 	user, err := s.GetUserByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	before := *user
 	user.Status = 1
 	token := extractTokenFromContext(ctx)
 	operator, _ := s.authService.GetCurrentUsername(token)
 	user.UpdatedBy = operator
 	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
-	if err := s.db.WithContext(ctx).Save(user).Error; err != nil {
+	if err := s.audit.SaveUser(ctx, user, newAuditEvent(ctx, entity.AuditUserBlock, &before, user)); err != nil {
 		return nil, err
 	}
+	s.dropSession(user.Username)
 	return user, nil
 }
 
@@ -280,12 +372,32 @@ func (s *UserService) UnblockUser(ctx context.Context, userID int64) (*entity.Us
 	if err != nil {
 		return nil, err
 	}
+	before := *user
 	user.Status = 0
 	token := extractTokenFromContext(ctx)
 	operator, _ := s.authService.GetCurrentUsername(token)
 	user.UpdatedBy = operator
 	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
-	if err := s.db.WithContext(ctx).Save(user).Error; err != nil {
+	if err := s.audit.SaveUser(ctx, user, newAuditEvent(ctx, entity.AuditUserUnblock, &before, user)); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UnlockUser 手动解除因登录失败导致的锁定，同时清零锁定次数
+func (s *UserService) UnlockUser(ctx context.Context, userID int64) (*entity.User, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	before := *user
+	user.Unlock()
+	user.LockCount = 0
+	token := extractTokenFromContext(ctx)
+	operator, _ := s.authService.GetCurrentUsername(token)
+	user.UpdatedBy = operator
+	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.audit.SaveUser(ctx, user, newAuditEvent(ctx, entity.AuditUserUnlock, &before, user)); err != nil {
 		return nil, err
 	}
 	return user, nil
@@ -297,17 +409,158 @@ func (s *UserService) DeleteUser(ctx context.Context, userID int64) (*entity.Use
 	if err != nil {
 		return nil, err
 	}
+	before := *user
 	user.Deleted = 1
 	token := extractTokenFromContext(ctx)
 	operator, _ := s.authService.GetCurrentUsername(token)
 	user.UpdatedBy = operator
 	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
-	if err := s.db.WithContext(ctx).Save(user).Error; err != nil {
+	user.DeletedAt = user.UpdatedAt
+	if err := s.audit.SaveUser(ctx, user, newAuditEvent(ctx, entity.AuditUserDelete, &before, user)); err != nil {
+		return nil, err
+	}
+	// 删除后用户名会被释放，必须同时删除会话，避免同名新用户或原用户继续使用旧 Token
+	s.dropSession(user.Username)
+	return user, nil
+}
+
+// ListDeletedUsers 回收站：分页查询已删除的用户，按删除时间倒序
+func (s *UserService) ListDeletedUsers(ctx context.Context, req request.UserSearchRequest, page request.PageRequest) ([]entity.User, int64, error) {
+	var users []entity.User
+	var total int64
+
+	req.Deleted = nil
+	query := s.db.WithContext(ctx).Model(&entity.User{}).Scopes(s.tenantScope(ctx))
+	query = s.buildSearchQuery(query, req).Unscoped().Where("deleted = 1")
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := page.GetOffset()
+	if err := query.Order("deleted_at DESC NULLS LAST, id DESC").
+		Limit(int(page.PageSize)).Offset(int(offset)).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// GetDeletedUserByID 根据ID获取已删除的用户
+func (s *UserService) GetDeletedUserByID(ctx context.Context, userID int64) (*entity.User, error) {
+	var user entity.User
+	if err := s.db.WithContext(ctx).Unscoped().Scopes(s.tenantScope(ctx)).
+		Where("deleted = 1").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("用户不存在或未被删除")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RestoreUser 恢复已删除的用户
+func (s *UserService) RestoreUser(ctx context.Context, userID int64) (*entity.User, error) {
+	user, err := s.GetDeletedUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkRestorable(ctx, user); err != nil {
+		return nil, err
+	}
+	before := *user
+	user.Deleted = 0
+	user.DeletedAt = sql.NullTime{}
+	token := extractTokenFromContext(ctx)
+	operator, _ := s.authService.GetCurrentUsername(token)
+	user.UpdatedBy = operator
+	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.audit.SaveUser(ctx, user, newAuditEvent(ctx, entity.AuditUserRestore, &before, user)); err != nil {
 		return nil, err
 	}
 	return user, nil
 }
 
+// checkRestorable 删除后用户名即被释放，恢复前确认用户名没有被其他用户占用
+func (s *UserService) checkRestorable(ctx context.Context, user *entity.User) error {
+	var cnt int64
+	if err := s.db.WithContext(ctx).Model(&entity.User{}).
+		Where("username = ? AND id <> ?", user.Username, user.ID).
+		Count(&cnt).Error; err != nil {
+		return err
+	}
+	if cnt > 0 {
+		return fmt.Errorf("用户名 %s 已被其他用户使用，无法恢复", user.Username)
+	}
+	return nil
+}
+
+// UpdateOwnProfile 当前登录用户修改自己的手机号、邮箱
+func (s *UserService) UpdateOwnProfile(ctx context.Context, req request.ProfileUpdateRequest) (*entity.User, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.UpdateUser(ctx, userID, request.UserUpdateRequest{Phone: req.Phone, Email: req.Email})
+}
+
+// DeleteOwnAccount 当前登录用户注销自己的账号，当前会话随之失效
+func (s *UserService) DeleteOwnAccount(ctx context.Context) (*entity.User, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.DeleteUser(ctx, userID)
+}
+
+// dropSession 删除用户的登录会话，之后该用户的 Token 校验失败
+func (s *UserService) dropSession(username string) {
+	if err := s.authService.redis.Del(context.Background(), username).Err(); err != nil {
+		log.Printf("删除用户会话失败: %v", err)
+	}
+}
+
+// tenantScope 将查询限定在当前操作人所属租户内，超级管理员不受限制
+func (s *UserService) tenantScope(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return TenantScope(ctx, "tenant_id")
+}
+
+// currentClaims 获取当前请求的 JWT claims，优先使用中间件已解析的结果
+func currentClaims(ctx context.Context) *jwt.CustomClaims {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		if v, exists := ginCtx.Get(jwt.ContextKey); exists {
+			if claims, ok := v.(*jwt.CustomClaims); ok {
+				return claims
+			}
+		}
+	}
+	claims, err := jwt.ParseToken(extractTokenFromContext(ctx))
+	if err != nil {
+		return nil
+	}
+	return claims
+}
+
+// currentUserID 获取当前登录用户的 ID
+func currentUserID(ctx context.Context) (int64, error) {
+	claims := currentClaims(ctx)
+	if claims == nil {
+		return 0, fmt.Errorf("未登录")
+	}
+	userID, err := strconv.ParseInt(claims.UserId, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Token无效")
+	}
+	return userID, nil
+}
+
+// currentOperator 获取当前操作人用户名，无法识别时返回空字符串
+func currentOperator(ctx context.Context) string {
+	if claims := currentClaims(ctx); claims != nil {
+		return claims.Username
+	}
+	return ""
+}
+
 // extractTokenFromContext 从 gin.Context 中提取 token
 func extractTokenFromContext(ctx context.Context) string {
 	if ginCtx, ok := ctx.(*gin.Context); ok {
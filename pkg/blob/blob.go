@@ -0,0 +1,49 @@
+// Package blob 提供可替换的对象存储：本地文件系统（LocalStore）与 S3 兼容服务（S3Store），
+// 对象通过带签名和有效期的 URL 访问。
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("对象不存在")
+
+// Store 对象存储。对象均为头像等小文件，写入时整体放在内存中
+type Store interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get 读取对象，不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除对象，不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// SignedURL 返回在 ttl 内有效的访问地址
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// ValidateKey 检查对象 key：由 / 分隔的若干段，每段只能包含字母、数字和 . _ -，不能是 . 或 ..
+func ValidateKey(key string) error {
+	if key == "" || len(key) > 512 {
+		return fmt.Errorf("对象 key 不合法: %q", key)
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return fmt.Errorf("对象 key 不合法: %q", key)
+		}
+		for _, r := range seg {
+			if !isKeyChar(r) {
+				return fmt.Errorf("对象 key 不合法: %q", key)
+			}
+		}
+	}
+	return nil
+}
+
+func isKeyChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-'
+}
@@ -0,0 +1,129 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStore 将对象保存在本地目录中，签名 URL 指向应用自身的下载接口，由 Verify 校验
+type LocalStore struct {
+	Root    string // 存储目录
+	BaseURL string // 下载接口地址，如 /api/blob，签名 URL 为 {BaseURL}/{key}?expires=..&signature=..
+	secret  []byte
+}
+
+// NewLocalStore 创建并返回一个 LocalStore 实例，secret 用于签名 URL
+func NewLocalStore(root, baseURL, secret string) (*LocalStore, error) {
+	if secret == "" {
+		return nil, errors.New("本地存储签名密钥不能为空")
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("创建存储目录失败: %w", err)
+	}
+	return &LocalStore{Root: root, BaseURL: strings.TrimRight(baseURL, "/"), secret: []byte(secret)}, nil
+}
+
+// Put 实现 Store，先写入临时文件再重命名，读取方不会看到写了一半的文件
+func (s *LocalStore) Put(_ context.Context, key string, data []byte, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get 实现 Store
+func (s *LocalStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete 实现 Store
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL 实现 Store
+func (s *LocalStore) SignedURL(_ context.Context, key string, ttl time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("signature", s.sign(key, expires))
+	return s.BaseURL + "/" + key + "?" + q.Encode(), nil
+}
+
+// Verify 校验 SignedURL 生成的签名及有效期
+func (s *LocalStore) Verify(key, expires, signature string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errors.New("链接无效")
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return errors.New("链接签名无效")
+	}
+	if time.Now().Unix() > exp {
+		return errors.New("链接已过期")
+	}
+	return nil
+}
+
+func (s *LocalStore) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path 返回对象在磁盘上的路径，key 经过校验，不会越出存储目录
+func (s *LocalStore) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(key)), nil
+}
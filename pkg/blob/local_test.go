@@ -0,0 +1,106 @@
+package blob
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStore(t *testing.T) *LocalStore {
+	t.Helper()
+	s, err := NewLocalStore(filepath.Join(t.TempDir(), "blobs"), "/api/blob/", "secret")
+	if err != nil {
+		t.Fatalf("NewLocalStore 返回错误: %v", err)
+	}
+	return s
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	s := newTestLocalStore(t)
+	testStoreRoundTrip(t, s)
+
+	if err := s.Put(context.Background(), "avatars/7/256.webp", []byte("x"), ""); err != nil {
+		t.Fatalf("写入多级 key 返回错误: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(s.Root, "avatars", "7", "256.webp")); err != nil {
+		t.Errorf("对象未写入存储目录: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(s.Root, "avatars", "7"))
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".upload-") {
+			t.Errorf("残留临时文件 %s", e.Name())
+		}
+	}
+}
+
+func TestLocalStoreSignedURL(t *testing.T) {
+	s := newTestLocalStore(t)
+	raw, err := s.SignedURL(context.Background(), "avatars/1.png", time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL 返回错误: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/api/blob/avatars/1.png" {
+		t.Errorf("SignedURL 路径 = %s", u.Path)
+	}
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+	expired, _ := s.SignedURL(context.Background(), "avatars/1.png", -time.Minute)
+	eu, _ := url.Parse(expired)
+
+	tests := []struct {
+		name      string
+		key       string
+		expires   string
+		signature string
+		wantErr   bool
+	}{
+		{"有效签名", "avatars/1.png", expires, signature, false},
+		{"key 被篡改", "avatars/2.png", expires, signature, true},
+		{"有效期被篡改", "avatars/1.png", expires + "0", signature, true},
+		{"签名被篡改", "avatars/1.png", expires, strings.Repeat("0", len(signature)), true},
+		{"有效期不是数字", "avatars/1.png", "soon", signature, true},
+		{"已过期", "avatars/1.png", eu.Query().Get("expires"), eu.Query().Get("signature"), true},
+		{"非法 key", "../1.png", expires, signature, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Verify(tt.key, tt.expires, tt.signature)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify 错误 = %v, 期望错误 %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{"1.png", true},
+		{"avatars/7/256.webp", true},
+		{"a_b-c.D", true},
+		{"", false},
+		{"/abs.png", false},
+		{"trailing/", false},
+		{"a//b", false},
+		{"./a", false},
+		{"a/../b", false},
+		{"a b", false},
+		{"a\\b", false},
+		{"头像.png", false},
+		{strings.Repeat("a", 513), false},
+	}
+	for _, tt := range tests {
+		if err := ValidateKey(tt.key); (err == nil) != tt.valid {
+			t.Errorf("ValidateKey(%q) 错误 = %v, 期望合法 %v", tt.key, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Package breach 检查密码是否出现在泄露密码库中，数据格式与 Have I Been Pwned 的 Pwned Passwords 一致：
+// 以密码 SHA-1 的十六进制大写形式为键，前 5 位作为分桶前缀（k-匿名），桶内每行为“后 35 位:出现次数”。
+// 支持本地索引文件（Index）与范围查询接口（RangeClient）两种来源。
+package breach
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PrefixLen 范围查询使用的哈希前缀长度（十六进制字符数）
+const PrefixLen = 5
+
+// Checker 返回密码在泄露库中出现的次数，未出现时返回 0
+type Checker interface {
+	Count(ctx context.Context, password string) (int, error)
+}
+
+// Hash 返回密码 SHA-1 的十六进制大写形式
+func Hash(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// scanRange 读取范围格式的内容（每行 后缀:次数），对每一行调用 fn，fn 返回 false 时停止
+func scanRange(r io.Reader, fn func(suffix string, count int) bool) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		suffix, countStr, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("格式错误: %q", line)
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(countStr))
+		if err != nil {
+			return fmt.Errorf("次数格式错误: %q", line)
+		}
+		if !fn(strings.ToUpper(strings.TrimSpace(suffix)), count) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,58 @@
+package breach
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RangeClient 通过范围查询接口检查密码，只发送 SHA-1 的前 5 位，
+// 接口与 https://api.pwnedpasswords.com/range/{prefix} 兼容，也可以指向内网的替代服务
+type RangeClient struct {
+	BaseURL string // 如 https://api.pwnedpasswords.com，请求 {BaseURL}/range/{prefix}
+	Client  *http.Client
+}
+
+// NewRangeClient 创建并返回一个 RangeClient 实例，timeout 为单次请求超时
+func NewRangeClient(baseURL string, timeout time.Duration) *RangeClient {
+	return &RangeClient{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Count 实现 Checker
+func (c *RangeClient) Count(ctx context.Context, password string) (int, error) {
+	hash := Hash(password)
+	prefix, suffix := hash[:PrefixLen], hash[PrefixLen:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/range/"+prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+	// 填充响应，避免根据响应长度推测前缀
+	req.Header.Set("Add-Padding", "true")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("查询泄露密码接口失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("查询泄露密码接口失败: HTTP %d", resp.StatusCode)
+	}
+
+	count := 0
+	err = scanRange(resp.Body, func(s string, n int) bool {
+		if s == suffix {
+			count = n
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("解析泄露密码接口响应失败: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,263 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 索引文件格式：
+//
+//	magic(8) | fanout((2^20+1) × uint32) | entries
+//
+// fanout[i] 为前缀（SHA-1 前 20 位）小于 i 的条目数，entries 按哈希升序排列，
+// 每条为 SHA-1 第 3-12 字节（共 10 字节，与前缀合计约 100 位，误判概率可忽略）加 uint32 次数，
+// 查询时只读取一个桶，不需要把索引加载到内存。
+const (
+	indexMagic   = "PWNIDX01"
+	fanoutSize   = 1<<20 + 1
+	headerSize   = len(indexMagic) + fanoutSize*4
+	entryHashLen = 10
+	entrySize    = entryHashLen + 4
+)
+
+// Index 本地泄露密码索引
+type Index struct {
+	f       *os.File
+	entries int64
+}
+
+// OpenIndex 打开由 Writer 生成的索引文件
+func OpenIndex(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开泄露密码索引失败: %w", err)
+	}
+	magic := make([]byte, len(indexMagic))
+	if _, err := f.ReadAt(magic, 0); err != nil || string(magic) != indexMagic {
+		f.Close()
+		return nil, fmt.Errorf("泄露密码索引格式错误: %s", path)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	x := &Index{f: f, entries: (info.Size() - int64(headerSize)) / entrySize}
+	total, err := x.fanout(fanoutSize - 1)
+	if err != nil || int64(total) != x.entries {
+		f.Close()
+		return nil, fmt.Errorf("泄露密码索引不完整: %s", path)
+	}
+	return x, nil
+}
+
+// Len 返回索引中的哈希数
+func (x *Index) Len() int64 {
+	return x.entries
+}
+
+// Count 实现 Checker
+func (x *Index) Count(_ context.Context, password string) (int, error) {
+	sum, _ := hex.DecodeString(Hash(password))
+	prefix := prefixOf(sum)
+	lo, err := x.fanout(prefix)
+	if err != nil {
+		return 0, err
+	}
+	hi, err := x.fanout(prefix + 1)
+	if err != nil {
+		return 0, err
+	}
+	if hi <= lo {
+		return 0, nil
+	}
+
+	bucket := make([]byte, int(hi-lo)*entrySize)
+	if _, err := x.f.ReadAt(bucket, int64(headerSize)+int64(lo)*entrySize); err != nil {
+		return 0, fmt.Errorf("读取泄露密码索引失败: %w", err)
+	}
+	key := sum[2 : 2+entryHashLen]
+	n := len(bucket) / entrySize
+	i := sort.Search(n, func(i int) bool {
+		return bytes.Compare(bucket[i*entrySize:i*entrySize+entryHashLen], key) >= 0
+	})
+	if i < n && bytes.Equal(bucket[i*entrySize:i*entrySize+entryHashLen], key) {
+		return int(binary.BigEndian.Uint32(bucket[i*entrySize+entryHashLen:])), nil
+	}
+	return 0, nil
+}
+
+// Close 关闭索引文件
+func (x *Index) Close() error {
+	return x.f.Close()
+}
+
+func (x *Index) fanout(i uint32) (uint32, error) {
+	var buf [4]byte
+	if _, err := x.f.ReadAt(buf[:], int64(len(indexMagic))+int64(i)*4); err != nil {
+		return 0, fmt.Errorf("读取泄露密码索引失败: %w", err)
+	}
+	return binary.BigEndian.Uint32(buf[:]), nil
+}
+
+// prefixOf 返回 SHA-1 的前 20 位
+func prefixOf(sum []byte) uint32 {
+	return uint32(sum[0])<<12 | uint32(sum[1])<<4 | uint32(sum[2])>>4
+}
+
+// Writer 按哈希升序逐条写入索引
+type Writer struct {
+	f      *os.File
+	w      *bufio.Writer
+	counts []uint32
+	last   []byte
+	n      int64
+}
+
+// CreateIndex 创建索引文件，写入完成后必须调用 Close
+func CreateIndex(path string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("创建泄露密码索引失败: %w", err)
+	}
+	w := &Writer{f: f, w: bufio.NewWriterSize(f, 1<<20), counts: make([]uint32, fanoutSize)}
+	// 先写入占位的 fanout，Close 时回填
+	if _, err := w.w.Write(make([]byte, headerSize)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Add 写入一个 SHA-1 哈希（40 位十六进制）及出现次数，哈希必须严格升序
+func (w *Writer) Add(hash string, count int) error {
+	sum, err := hex.DecodeString(hash)
+	if err != nil || len(sum) != 20 {
+		return fmt.Errorf("SHA-1 哈希格式错误: %q", hash)
+	}
+	if w.last != nil && bytes.Compare(sum, w.last) <= 0 {
+		return fmt.Errorf("哈希未按升序排列: %s", hash)
+	}
+	w.last = sum
+	if count < 0 {
+		count = 0
+	}
+	if uint64(count) > uint64(^uint32(0)) {
+		count = int(^uint32(0))
+	}
+
+	var entry [entrySize]byte
+	copy(entry[:], sum[2:2+entryHashLen])
+	binary.BigEndian.PutUint32(entry[entryHashLen:], uint32(count))
+	if _, err := w.w.Write(entry[:]); err != nil {
+		return err
+	}
+	w.counts[prefixOf(sum)]++
+	w.n++
+	return nil
+}
+
+// Len 返回已写入的哈希数
+func (w *Writer) Len() int64 {
+	return w.n
+}
+
+// Close 回填 fanout 并关闭文件
+func (w *Writer) Close() error {
+	if err := w.w.Flush(); err != nil {
+		w.f.Close()
+		return err
+	}
+	if w.n > int64(^uint32(0)) {
+		w.f.Close()
+		return errors.New("哈希数超过索引上限")
+	}
+	fanout := make([]byte, fanoutSize*4)
+	var total uint32
+	for i := 0; i < fanoutSize; i++ {
+		binary.BigEndian.PutUint32(fanout[i*4:], total)
+		total += w.counts[i]
+	}
+	if _, err := w.f.WriteAt([]byte(indexMagic), 0); err != nil {
+		w.f.Close()
+		return err
+	}
+	if _, err := w.f.WriteAt(fanout, int64(len(indexMagic))); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// ImportHashes 从 HASH:次数 格式（如 pwned-passwords-sha1-ordered-by-hash）的文件导入，文件须按哈希排序
+func (w *Writer) ImportHashes(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var addErr error
+	err = scanRange(f, func(hash string, count int) bool {
+		addErr = w.Add(hash, count)
+		return addErr == nil
+	})
+	if addErr != nil {
+		return addErr
+	}
+	return err
+}
+
+// ImportRangeDir 从按前缀分桶的目录导入：每个文件以 5 位十六进制前缀命名（可带扩展名），
+// 内容与范围查询接口的响应相同（后缀:次数）
+func (w *Writer) ImportRangeDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, e := range entries {
+		prefix := strings.ToUpper(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))
+		if e.IsDir() || len(prefix) != PrefixLen {
+			continue
+		}
+		if _, err := hex.DecodeString(prefix + "0"); err != nil {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Slice(names, func(i, j int) bool { return strings.ToUpper(names[i]) < strings.ToUpper(names[j]) })
+
+	for _, name := range names {
+		prefix := strings.ToUpper(strings.TrimSuffix(name, filepath.Ext(name)))
+		if err := w.importBucket(filepath.Join(dir, name), prefix); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (w *Writer) importBucket(path, prefix string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var addErr error
+	err = scanRange(f, func(suffix string, count int) bool {
+		addErr = w.Add(prefix+suffix, count)
+		return addErr == nil
+	})
+	if addErr != nil {
+		return addErr
+	}
+	return err
+}
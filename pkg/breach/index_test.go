@@ -0,0 +1,278 @@
+package breach
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// breached 测试用的泄露密码及次数
+var breached = map[string]int{
+	"password":  3730471,
+	"123456":    37359195,
+	"qwerty":    10556095,
+	"letmein":   633816,
+	"dragon":    1,
+	"trustno1":  0,
+	"iloveyou!": 4294967295,
+}
+
+// sortedHashes 返回 breached 的 HASH:次数 行，按哈希升序排列
+func sortedHashes() []string {
+	lines := make([]string, 0, len(breached))
+	for pw, n := range breached {
+		lines = append(lines, fmt.Sprintf("%s:%d", Hash(pw), n))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// assertCounts 校验索引对每个密码返回的次数
+func assertCounts(t *testing.T, checker Checker) {
+	t.Helper()
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"password", 3730471},
+		{"123456", 37359195},
+		{"qwerty", 10556095},
+		{"letmein", 633816},
+		{"dragon", 1},
+		{"trustno1", 0},
+		{"iloveyou!", 4294967295},
+		{"Password", 0},
+		{"correct horse battery staple", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		got, err := checker.Count(context.Background(), tt.password)
+		if err != nil {
+			t.Fatalf("Count(%q) 返回错误: %v", tt.password, err)
+		}
+		if got != tt.want {
+			t.Errorf("Count(%q) = %d, 期望 %d", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestIndexRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name  string
+		build func(w *Writer) error
+	}{
+		{"逐条写入", func(w *Writer) error {
+			for _, line := range sortedHashes() {
+				hash, count, _ := strings.Cut(line, ":")
+				var n int
+				fmt.Sscan(count, &n)
+				if err := w.Add(hash, n); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"从有序哈希文件导入", func(w *Writer) error {
+			path := filepath.Join(dir, "ordered.txt")
+			writeTestFile(t, path, strings.Join(sortedHashes(), "\r\n")+"\r\n")
+			return w.ImportHashes(path)
+		}},
+		{"从分桶目录导入", func(w *Writer) error {
+			buckets := filepath.Join(dir, "range")
+			if err := os.MkdirAll(buckets, 0o755); err != nil {
+				return err
+			}
+			files := map[string][]string{}
+			for _, line := range sortedHashes() {
+				prefix := line[:PrefixLen]
+				files[prefix] = append(files[prefix], strings.ToLower(line[PrefixLen:]))
+			}
+			for prefix, lines := range files {
+				writeTestFile(t, filepath.Join(buckets, strings.ToLower(prefix)+".txt"), strings.Join(lines, "\n"))
+			}
+			// 不符合命名规则的文件会被忽略
+			writeTestFile(t, filepath.Join(buckets, "README.md"), "not a bucket")
+			return w.ImportRangeDir(buckets)
+		}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("index-%d.bin", i))
+			w, err := CreateIndex(path)
+			if err != nil {
+				t.Fatalf("CreateIndex 返回错误: %v", err)
+			}
+			if err := tt.build(w); err != nil {
+				t.Fatalf("写入索引失败: %v", err)
+			}
+			if w.Len() != int64(len(breached)) {
+				t.Errorf("Writer.Len() = %d, 期望 %d", w.Len(), len(breached))
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Writer.Close 返回错误: %v", err)
+			}
+
+			x, err := OpenIndex(path)
+			if err != nil {
+				t.Fatalf("OpenIndex 返回错误: %v", err)
+			}
+			defer x.Close()
+			if x.Len() != int64(len(breached)) {
+				t.Errorf("Index.Len() = %d, 期望 %d", x.Len(), len(breached))
+			}
+			assertCounts(t, x)
+		})
+	}
+}
+
+// 同一前缀桶内有多个哈希时按桶内二分查找
+func TestIndexSharedBucket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.bin")
+	target := Hash("password")
+	w, err := CreateIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes := []string{
+		target[:PrefixLen] + strings.Repeat("0", 35),
+		target,
+		target[:PrefixLen] + strings.Repeat("F", 35),
+	}
+	for i, h := range hashes {
+		if err := w.Add(h, i+1); err != nil {
+			t.Fatalf("Add(%s) 返回错误: %v", h, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := OpenIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Close()
+	if got, _ := x.Count(context.Background(), "password"); got != 2 {
+		t.Errorf("Count = %d, 期望 2", got)
+	}
+}
+
+func TestWriterAddErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		hashes []string
+	}{
+		{"非十六进制", []string{strings.Repeat("Z", 40)}},
+		{"长度不是 40", []string{"5BAA61E4C9B93F3F0682250B6CF8331B7EE68F"}},
+		{"未按升序", []string{Hash("123456"), Hash("password")}},
+		{"重复哈希", []string{Hash("password"), Hash("password")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := CreateIndex(filepath.Join(t.TempDir(), "index.bin"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Close()
+			var addErr error
+			for _, h := range tt.hashes {
+				if addErr = w.Add(h, 1); addErr != nil {
+					break
+				}
+			}
+			if addErr == nil {
+				t.Error("期望返回错误")
+			}
+		})
+	}
+}
+
+func TestOpenIndexErrors(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.bin")
+	w, err := CreateIndex(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add(Hash("password"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"空文件", nil},
+		{"magic 不正确", append([]byte("NOTANIDX"), data[len(indexMagic):]...)},
+		{"条目被截断", data[:len(data)-entrySize]},
+		{"只有头部", data[:len(indexMagic)+100]},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("broken-%d.bin", i))
+			if err := os.WriteFile(path, tt.content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if x, err := OpenIndex(path); err == nil {
+				x.Close()
+				t.Error("期望返回错误")
+			}
+		})
+	}
+	if _, err := OpenIndex(filepath.Join(dir, "missing.bin")); err == nil {
+		t.Error("文件不存在时期望返回错误")
+	}
+}
+
+func TestRangeClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.TrimPrefix(r.URL.Path, "/range/")
+		if len(prefix) != PrefixLen || r.Header.Get("Add-Padding") != "true" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		var lines []string
+		for _, line := range sortedHashes() {
+			if strings.HasPrefix(line, prefix) {
+				lines = append(lines, line[PrefixLen:])
+			}
+		}
+		// 填充条目次数为 0
+		lines = append(lines, strings.Repeat("0", 35)+":0")
+		fmt.Fprint(w, strings.Join(lines, "\r\n"))
+	}))
+	defer srv.Close()
+
+	assertCounts(t, NewRangeClient(srv.URL+"/", time.Second))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	if _, err := NewRangeClient(failing.URL, time.Second).Count(context.Background(), "password"); err == nil {
+		t.Error("接口返回非 200 时期望返回错误")
+	}
+}
@@ -0,0 +1,137 @@
+// Package geoip 基于本地 CSV 文件的 IP 地理位置查询。
+//
+// 文件每行一个网段，格式为 network,country,city,latitude,longitude，例如：
+//
+//	1.0.1.0/24,CN,Fuzhou,26.0614,119.3061
+//
+// 首行为表头时自动跳过，支持 IPv4 与 IPv6，网段之间不能重叠（与 GeoLite2 等 CSV 数据一致）。
+package geoip
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusKm 地球平均半径
+const earthRadiusKm = 6371.0
+
+// Location 地理位置
+type Location struct {
+	Country   string  `json:"country"`
+	City      string  `json:"city"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// String 返回“国家 城市”形式的描述
+func (l Location) String() string {
+	return strings.TrimSpace(l.Country + " " + l.City)
+}
+
+type entry struct {
+	prefix netip.Prefix
+	loc    Location
+}
+
+// DB 内存中的 IP 地理位置库，加载后只读，可并发查询
+type DB struct {
+	entries []entry
+}
+
+// Open 加载 GeoIP CSV 文件
+func Open(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Load 从 reader 加载 GeoIP CSV 数据
+func Load(r io.Reader) (*DB, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 5
+	reader.TrimLeadingSpace = true
+
+	db := &DB{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		prefix, err := netip.ParsePrefix(record[0])
+		if err != nil {
+			if line == 1 {
+				continue // 表头
+			}
+			return nil, fmt.Errorf("第 %d 行网段不合法: %s", line, record[0])
+		}
+		lat, err1 := strconv.ParseFloat(record[3], 64)
+		lon, err2 := strconv.ParseFloat(record[4], 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("第 %d 行经纬度不合法", line)
+		}
+		db.entries = append(db.entries, entry{
+			prefix: prefix.Masked(),
+			loc:    Location{Country: record[1], City: record[2], Latitude: lat, Longitude: lon},
+		})
+	}
+
+	sort.Slice(db.entries, func(i, j int) bool {
+		return db.entries[i].prefix.Addr().Less(db.entries[j].prefix.Addr())
+	})
+	return db, nil
+}
+
+// Lookup 查询 IP 所在位置，未收录或 IP 不合法时 ok 为 false
+func (db *DB) Lookup(ip string) (Location, bool) {
+	if db == nil {
+		return Location{}, false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return Location{}, false
+	}
+	addr = addr.Unmap()
+
+	// 网段互不重叠，找到起始地址不大于 addr 的最后一个网段即可
+	i := sort.Search(len(db.entries), func(i int) bool {
+		return addr.Less(db.entries[i].prefix.Addr())
+	}) - 1
+	if i >= 0 && db.entries[i].prefix.Contains(addr) {
+		return db.entries[i].loc, true
+	}
+	return Location{}, false
+}
+
+// Len 返回已加载的网段数
+func (db *DB) Len() int {
+	if db == nil {
+		return 0
+	}
+	return len(db.entries)
+}
+
+// Distance 计算两地之间的大圆距离（千米）
+func Distance(a, b Location) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
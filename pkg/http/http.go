@@ -1,40 +1,84 @@
 package http
 
 import (
+	"fmt"
+	"net"
 	"net/http"
 	"strings"
 )
 
-// GetClientIP 获取客户端IP地址
-func GetClientIP(r *http.Request) string {
-	// 尝试从各种可能的Header中获取IP
-	ip := r.Header.Get("X-Forwarded-For")
-	if ip == "" || strings.EqualFold(ip, "unknown") {
-		ip = r.Header.Get("Proxy-Client-IP")
+// trustedProxies 受信任的反向代理地址段，只有直连地址属于其中时才读取 X-Forwarded-For
+var trustedProxies []*net.IPNet
+
+// SetTrustedProxies 设置受信任的反向代理（IP 或 CIDR）。未设置时 GetClientIP 只使用直连地址，
+// 客户端自行填写的转发头不会生效
+func SetTrustedProxies(proxies []string) error {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return fmt.Errorf("受信任代理地址不合法: %s", p)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			return fmt.Errorf("受信任代理地址不合法: %s", p)
+		}
+		nets = append(nets, n)
 	}
-	if ip == "" || strings.EqualFold(ip, "unknown") {
-		ip = r.Header.Get("WL-Proxy-Client-IP")
+	trustedProxies = nets
+	return nil
+}
+
+// isTrustedProxy 判断地址是否属于受信任的反向代理
+func isTrustedProxy(ip net.IP) bool {
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
 	}
-	if ip == "" || strings.EqualFold(ip, "unknown") {
-		ip = r.Header.Get("HTTP_CLIENT_IP")
+	return false
+}
+
+// GetClientIP 获取客户端IP地址。直连地址是受信任代理时，从 X-Forwarded-For 末尾向前跳过受信任代理，
+// 第一个不受信任的地址即客户端地址；否则使用直连地址，防止客户端伪造转发头冒充其他 IP
+func GetClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
 	}
-	if ip == "" || strings.EqualFold(ip, "unknown") {
-		ip = r.Header.Get("HTTP_X_FORWARDED_FOR")
+	remote := net.ParseIP(host)
+	if remote == nil {
+		return host
 	}
-	if ip == "" || strings.EqualFold(ip, "unknown") {
-		ip = r.RemoteAddr
-		// 去除端口号
-		if strings.Contains(ip, ":") {
-			ip = strings.Split(ip, ":")[0]
-		}
+	if !isTrustedProxy(remote) {
+		return remote.String()
 	}
 
-	// 对于通过多个代理的情况，第一个IP为客户端真实IP
-	if strings.Contains(ip, ",") {
-		ip = strings.TrimSpace(strings.Split(ip, ",")[0])
+	client := remote
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		client = ip
+		if !isTrustedProxy(ip) {
+			break
+		}
 	}
-
-	return ip
+	return client.String()
 }
 
 // GetClientOS 获取客户端操作系统
@@ -90,3 +134,11 @@ func GetClientBrowser(r *http.Request) string {
 		return "Unknown"
 	}
 }
+
+// RequestIDHeader 请求ID所在的Header
+const RequestIDHeader = "X-Request-ID"
+
+// GetRequestID 获取请求ID
+func GetRequestID(r *http.Request) string {
+	return r.Header.Get(RequestIDHeader)
+}
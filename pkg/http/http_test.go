@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetClientIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted []string
+		remote  string
+		xff     []string
+		want    string
+	}{
+		{"未配置代理时忽略转发头", nil, "203.0.113.7:5000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"直连地址不受信任时忽略转发头", []string{"10.0.0.0/8"}, "203.0.113.7:5000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"受信任代理转发", []string{"10.0.0.0/8"}, "10.0.0.2:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"跳过客户端伪造的前缀", []string{"10.0.0.0/8"}, "10.0.0.2:5000", []string{"1.1.1.1, 198.51.100.1"}, "198.51.100.1"},
+		{"跳过多级受信任代理", []string{"10.0.0.0/8", "192.0.2.10"}, "10.0.0.2:5000", []string{"1.1.1.1, 198.51.100.1", "192.0.2.10, 10.1.2.3"}, "198.51.100.1"},
+		{"转发头全部为受信任代理", []string{"10.0.0.0/8"}, "10.0.0.2:5000", []string{"10.0.0.9"}, "10.0.0.9"},
+		{"转发头缺失", []string{"10.0.0.0/8"}, "10.0.0.2:5000", nil, "10.0.0.2"},
+		{"转发头格式错误时停止", []string{"10.0.0.0/8"}, "10.0.0.2:5000", []string{"198.51.100.1, unknown"}, "10.0.0.2"},
+		{"IPv6 直连地址", nil, "[2001:db8::1]:5000", nil, "2001:db8::1"},
+		{"IPv6 受信任代理", []string{"2001:db8::/32"}, "[2001:db8::1]:5000", []string{"2001:db8:ffff::1, 198.51.100.1"}, "198.51.100.1"},
+		{"直连地址没有端口", nil, "203.0.113.7", nil, "203.0.113.7"},
+	}
+	t.Cleanup(func() { SetTrustedProxies(nil) })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetTrustedProxies(tt.trusted); err != nil {
+				t.Fatalf("SetTrustedProxies 返回错误: %v", err)
+			}
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := GetClientIP(r); got != tt.want {
+				t.Errorf("GetClientIP = %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTrustedProxiesErrors(t *testing.T) {
+	t.Cleanup(func() { SetTrustedProxies(nil) })
+	for _, p := range []string{"proxy.local", "10.0.0.0/33", "300.1.1.1"} {
+		if err := SetTrustedProxies([]string{p}); err == nil {
+			t.Errorf("SetTrustedProxies(%q) 期望返回错误", p)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Package imaging 处理用户上传的图片：按内容识别格式、限制尺寸、按 EXIF 方向摆正、
+// 裁剪缩放为正方形缩略图并重新编码。重新编码只保留像素，EXIF 等元数据随之去除。
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"net/http"
+)
+
+// 支持的图片类型
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeGIF  = "image/gif"
+)
+
+// DefaultMaxPixels 默认允许的最大像素数，防止小文件解码出超大图片耗尽内存
+const DefaultMaxPixels = 40_000_000
+
+// ErrUnsupported 不支持的图片格式
+var ErrUnsupported = errors.New("仅支持 JPEG、PNG、GIF 格式的图片")
+
+// Sniff 根据文件内容（而非文件名或请求头）识别图片类型，不支持时返回 ErrUnsupported
+func Sniff(data []byte) (string, error) {
+	switch t := http.DetectContentType(data); t {
+	case TypeJPEG, TypePNG, TypeGIF:
+		return t, nil
+	default:
+		return "", ErrUnsupported
+	}
+}
+
+// Decode 识别并解码图片，像素数超过 maxPixels（<=0 时使用 DefaultMaxPixels）时拒绝；
+// JPEG 按 EXIF Orientation 旋转为正常方向。GIF 只取第一帧
+func Decode(data []byte, maxPixels int) (image.Image, error) {
+	contentType, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+	if maxPixels <= 0 {
+		maxPixels = DefaultMaxPixels
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("图片格式错误: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, errors.New("图片格式错误: 尺寸为空")
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("图片尺寸过大: %dx%d", cfg.Width, cfg.Height)
+	}
+
+	var img image.Image
+	switch contentType {
+	case TypeJPEG:
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	case TypePNG:
+		img, err = png.Decode(bytes.NewReader(data))
+	case TypeGIF:
+		img, err = gif.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("图片格式错误: %w", err)
+	}
+	if contentType == TypeJPEG {
+		img = orient(img, jpegOrientation(data))
+	}
+	return img, nil
+}
+
+// Thumbnail 从图片中央裁剪出最大的正方形并缩放为 size×size
+func Thumbnail(src image.Image, size int) *image.RGBA {
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2))
+	return resize(src, crop, size, size)
+}
+
+// EncodeJPEG 将图片编码为 JPEG，透明部分填充为白色
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resize 将 src 的 rect 区域缩放为 w×h。分两次（先水平后垂直）做三角形滤波，
+// 缩小时滤波半径随缩放比例放大，相当于对覆盖的源像素求加权平均，避免锯齿
+func resize(src image.Image, rect image.Rectangle, w, h int) *image.RGBA {
+	rgba := toRGBA(src, rect)
+	sw, sh := rect.Dx(), rect.Dy()
+
+	// 水平方向：sw×sh -> w×sh
+	tmp := make([]float64, w*sh*4)
+	for x, ws := range filterWeights(sw, w) {
+		for y := 0; y < sh; y++ {
+			var r, g, b, a float64
+			row := rgba.Pix[y*rgba.Stride:]
+			for _, wt := range ws {
+				p := row[wt.index*4:]
+				r += float64(p[0]) * wt.weight
+				g += float64(p[1]) * wt.weight
+				b += float64(p[2]) * wt.weight
+				a += float64(p[3]) * wt.weight
+			}
+			i := (y*w + x) * 4
+			tmp[i], tmp[i+1], tmp[i+2], tmp[i+3] = r, g, b, a
+		}
+	}
+
+	// 垂直方向：w×sh -> w×h
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y, ws := range filterWeights(sh, h) {
+		for x := 0; x < w; x++ {
+			var c [4]float64
+			for _, wt := range ws {
+				i := (wt.index*w + x) * 4
+				for k := 0; k < 4; k++ {
+					c[k] += tmp[i+k] * wt.weight
+				}
+			}
+			p := dst.Pix[y*dst.Stride+x*4:]
+			for k := 0; k < 4; k++ {
+				p[k] = clamp8(c[k])
+			}
+		}
+	}
+	return dst
+}
+
+type weight struct {
+	index  int
+	weight float64
+}
+
+// filterWeights 计算一维缩放时每个目标像素对应的源像素及权重（已归一化）
+func filterWeights(srcLen, dstLen int) [][]weight {
+	scale := float64(srcLen) / float64(dstLen)
+	radius := math.Max(scale, 1)
+	out := make([][]weight, dstLen)
+	for i := range out {
+		center := (float64(i)+0.5)*scale - 0.5
+		lo := int(math.Floor(center - radius))
+		hi := int(math.Ceil(center + radius))
+		var ws []weight
+		var sum float64
+		for j := lo; j <= hi; j++ {
+			wt := 1 - math.Abs(float64(j)-center)/radius
+			if wt <= 0 {
+				continue
+			}
+			idx := min(max(j, 0), srcLen-1)
+			ws = append(ws, weight{index: idx, weight: wt})
+			sum += wt
+		}
+		for k := range ws {
+			ws[k].weight /= sum
+		}
+		out[i] = ws
+	}
+	return out
+}
+
+// toRGBA 将 src 的 rect 区域复制为以 (0,0) 为原点的 RGBA（预乘 alpha）
+func toRGBA(src image.Image, rect image.Rectangle) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, rect.Min, draw.Src)
+	return dst
+}
+
+func clamp8(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
@@ -0,0 +1,115 @@
+// Package jsonschema 实现 JSON Schema（draft 2020-12）的一个子集，用于校验用户扩展属性。
+//
+// 支持的关键字：type（单个类型）、properties、required、additionalProperties（布尔值）、
+// enum、minLength、maxLength、pattern、format（email、date、date-time）、minimum、maximum、
+// items、minItems、maxItems，以及仅作说明的 $schema、$id、title、description。
+// 出现其他关键字时 Compile 返回错误，避免管理员以为生效的约束被静默忽略。
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 支持的类型
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
+// Schema 编译后的 schema
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Compile 解析并检查 schema
+func Compile(data []byte) (*Schema, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var s Schema
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("schema 格式错误: %s", strings.TrimPrefix(err.Error(), "json: "))
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("schema 格式错误: 包含多余内容")
+	}
+	if err := s.compile(""); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// compile 递归检查关键字取值并预编译正则
+func (s *Schema) compile(path string) error {
+	where := path
+	if where == "" {
+		where = "/"
+	}
+	switch s.Type {
+	case "", TypeObject, TypeArray, TypeString, TypeInteger, TypeNumber, TypeBoolean:
+	default:
+		return fmt.Errorf("schema %s: 不支持的类型 %q", where, s.Type)
+	}
+	switch s.Format {
+	case "", "email", "date", "date-time":
+	default:
+		return fmt.Errorf("schema %s: 不支持的格式 %q", where, s.Format)
+	}
+	for _, n := range []*int{s.MinLength, s.MaxLength, s.MinItems, s.MaxItems} {
+		if n != nil && *n < 0 {
+			return fmt.Errorf("schema %s: 长度限制不能为负数", where)
+		}
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("schema %s: 正则表达式错误: %v", where, err)
+		}
+		s.pattern = re
+	}
+	for _, name := range s.Required {
+		if _, ok := s.Properties[name]; !ok && s.AdditionalProperties != nil && !*s.AdditionalProperties {
+			return fmt.Errorf("schema %s: 必填属性 %q 未定义", where, name)
+		}
+	}
+	for name, p := range s.Properties {
+		if p == nil {
+			return fmt.Errorf("schema %s/%s: 属性定义不能为空", path, name)
+		}
+		if err := p.compile(path + "/" + name); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		if err := s.Items.compile(path + "/items"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Error 一处不满足 schema 的位置，Path 为 JSON Pointer（如 /department）
+type Error struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError 校验失败，Errors 列出全部不满足的位置
+type ValidationError struct {
+	Errors []Error
+}
+
+// Error 实现 error，多处错误以分号连接
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, v := range e.Errors {
+		msgs[i] = v.Path + " " + v.Message
+	}
+	return "扩展属性不符合要求：" + strings.Join(msgs, "；")
+}
+
+// Validate 校验 encoding/json 解码得到的值（map[string]interface{}、[]interface{}、float64 等），
+// 满足时返回 nil，否则返回 *ValidationError
+func (s *Schema) Validate(v interface{}) error {
+	var errs []Error
+	s.validate("", normalize(v), &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func (s *Schema) validate(path string, v interface{}, errs *[]Error) {
+	add := func(format string, args ...interface{}) {
+		p := path
+		if p == "" {
+			p = "/"
+		}
+		*errs = append(*errs, Error{Path: p, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != "" && !hasType(v, s.Type) {
+		add("应为%s", typeName(s.Type))
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(v, s.Enum) {
+		add("取值不在允许范围内")
+	}
+
+	switch val := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(val)
+		if s.MinLength != nil && n < *s.MinLength {
+			add("长度不能少于 %d", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			add("长度不能超过 %d", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(val) {
+			add("格式不正确")
+		}
+		if s.Format != "" && !validFormat(s.Format, val) {
+			add("不是有效的 %s", s.Format)
+		}
+	case float64:
+		if s.Minimum != nil && val < *s.Minimum {
+			add("不能小于 %s", formatNumber(*s.Minimum))
+		}
+		if s.Maximum != nil && val > *s.Maximum {
+			add("不能大于 %s", formatNumber(*s.Maximum))
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(val) < *s.MinItems {
+			add("至少包含 %d 项", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(val) > *s.MaxItems {
+			add("最多包含 %d 项", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range val {
+				s.Items.validate(path+"/"+strconv.Itoa(i), item, errs)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := val[name]; !ok {
+				*errs = append(*errs, Error{Path: path + "/" + escape(name), Message: "不能为空"})
+			}
+		}
+		names := make([]string, 0, len(val))
+		for name := range val {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if p, ok := s.Properties[name]; ok {
+				p.validate(path+"/"+escape(name), val[name], errs)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				*errs = append(*errs, Error{Path: path + "/" + escape(name), Message: "未定义的属性"})
+			}
+		}
+	}
+}
+
+// normalize 将 json.Number 等转换为 encoding/json 默认解码的类型
+func normalize(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		f, _ := val.Float64()
+		return f
+	case int:
+		return float64(val)
+	case int64:
+		return float64(val)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = normalize(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = normalize(item)
+		}
+		return out
+	}
+	return v
+}
+
+func hasType(v interface{}, typ string) bool {
+	switch typ {
+	case TypeObject:
+		_, ok := v.(map[string]interface{})
+		return ok
+	case TypeArray:
+		_, ok := v.([]interface{})
+		return ok
+	case TypeString:
+		_, ok := v.(string)
+		return ok
+	case TypeNumber:
+		_, ok := v.(float64)
+		return ok
+	case TypeInteger:
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f) && !math.IsInf(f, 0)
+	case TypeBoolean:
+		_, ok := v.(bool)
+		return ok
+	}
+	return false
+}
+
+func typeName(typ string) string {
+	switch typ {
+	case TypeObject:
+		return "对象"
+	case TypeArray:
+		return "数组"
+	case TypeString:
+		return "字符串"
+	case TypeInteger:
+		return "整数"
+	case TypeNumber:
+		return "数字"
+	case TypeBoolean:
+		return "布尔值"
+	}
+	return typ
+}
+
+func inEnum(v interface{}, enum []interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(v, normalize(e)) {
+			return true
+		}
+	}
+	return false
+}
+
+func validFormat(format, s string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	}
+	return true
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// escape 按 JSON Pointer 规则转义属性名
+func escape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
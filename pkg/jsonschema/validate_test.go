@@ -0,0 +1,143 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const attributesSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "员工扩展属性",
+	"type": "object",
+	"required": ["employeeNo", "department"],
+	"additionalProperties": false,
+	"properties": {
+		"employeeNo": {"type": "string", "pattern": "^E[0-9]{4}$"},
+		"department": {"type": "string", "enum": ["研发", "运营", "财务"]},
+		"nickname":   {"type": "string", "minLength": 2, "maxLength": 4},
+		"email":      {"type": "string", "format": "email"},
+		"hiredOn":    {"type": "string", "format": "date"},
+		"updatedAt":  {"type": "string", "format": "date-time"},
+		"level":      {"type": "integer", "minimum": 1, "maximum": 10},
+		"ratio":      {"type": "number", "minimum": 0, "maximum": 1},
+		"remote":     {"type": "boolean"},
+		"tags":       {"type": "array", "minItems": 1, "maxItems": 2, "items": {"type": "string", "minLength": 1}},
+		"a/b~c":      {"type": "string"},
+		"address":    {
+			"type": "object",
+			"required": ["city"],
+			"properties": {"city": {"type": "string"}}
+		}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	schema, err := Compile([]byte(attributesSchema))
+	if err != nil {
+		t.Fatalf("Compile 返回错误: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  []Error
+	}{
+		{"全部合法", `{"employeeNo":"E0001","department":"研发","nickname":"小明","email":"a@example.com",
+			"hiredOn":"2024-02-29","updatedAt":"2024-03-01T08:00:00+08:00","level":3,"ratio":0.5,"remote":true,
+			"tags":["go"],"a/b~c":"x","address":{"city":"上海","zip":"200000"}}`, nil},
+		{"整数允许写成小数形式", `{"employeeNo":"E0001","department":"研发","level":3.0}`, nil},
+		{"缺少必填属性", `{"department":"研发"}`, []Error{{"/employeeNo", "不能为空"}}},
+		{"未定义的属性", `{"employeeNo":"E0001","department":"研发","extra":1}`, []Error{{"/extra", "未定义的属性"}}},
+		{"正则不匹配", `{"employeeNo":"e0001","department":"研发"}`, []Error{{"/employeeNo", "格式不正确"}}},
+		{"枚举不匹配", `{"employeeNo":"E0001","department":"市场"}`, []Error{{"/department", "取值不在允许范围内"}}},
+		{"长度按字符计算", `{"employeeNo":"E0001","department":"研发","nickname":"小明同学呀"}`, []Error{{"/nickname", "长度不能超过 4"}}},
+		{"长度不足", `{"employeeNo":"E0001","department":"研发","nickname":"明"}`, []Error{{"/nickname", "长度不能少于 2"}}},
+		{"邮箱格式", `{"employeeNo":"E0001","department":"研发","email":"张三 <a@example.com>"}`, []Error{{"/email", "不是有效的 email"}}},
+		{"日期格式", `{"employeeNo":"E0001","department":"研发","hiredOn":"2023-02-29"}`, []Error{{"/hiredOn", "不是有效的 date"}}},
+		{"日期时间格式", `{"employeeNo":"E0001","department":"研发","updatedAt":"2024-03-01 08:00:00"}`, []Error{{"/updatedAt", "不是有效的 date-time"}}},
+		{"整数类型", `{"employeeNo":"E0001","department":"研发","level":2.5}`, []Error{{"/level", "应为整数"}}},
+		{"超出最大值", `{"employeeNo":"E0001","department":"研发","level":11}`, []Error{{"/level", "不能大于 10"}}},
+		{"小于最小值", `{"employeeNo":"E0001","department":"研发","ratio":-0.1}`, []Error{{"/ratio", "不能小于 0"}}},
+		{"布尔类型", `{"employeeNo":"E0001","department":"研发","remote":"yes"}`, []Error{{"/remote", "应为布尔值"}}},
+		{"数组项数", `{"employeeNo":"E0001","department":"研发","tags":[]}`, []Error{{"/tags", "至少包含 1 项"}}},
+		{"数组元素", `{"employeeNo":"E0001","department":"研发","tags":["go",""]}`, []Error{{"/tags/1", "长度不能少于 1"}}},
+		{"数组过长", `{"employeeNo":"E0001","department":"研发","tags":["a","b","c"]}`, []Error{{"/tags", "最多包含 2 项"}}},
+		{"嵌套对象", `{"employeeNo":"E0001","department":"研发","address":{}}`, []Error{{"/address/city", "不能为空"}}},
+		{"属性名按 JSON Pointer 转义", `{"employeeNo":"E0001","department":"研发","a/b~c":1}`, []Error{{"/a~1b~0c", "应为字符串"}}},
+		{"根类型错误", `[]`, []Error{{"/", "应为对象"}}},
+		{"多处错误全部返回", `{"employeeNo":1,"level":0}`, []Error{
+			{"/department", "不能为空"}, {"/employeeNo", "应为字符串"}, {"/level", "不能小于 1"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			if err := json.Unmarshal([]byte(tt.input), &v); err != nil {
+				t.Fatal(err)
+			}
+			err := schema.Validate(v)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate 返回错误: %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate 错误 = %v, 期望 *ValidationError", err)
+			}
+			if !reflect.DeepEqual(verr.Errors, tt.want) {
+				t.Errorf("Validate 错误 = %v, 期望 %v", verr.Errors, tt.want)
+			}
+		})
+	}
+}
+
+// 非 encoding/json 默认类型的值先统一转换后再校验
+func TestValidateNormalizesNumbers(t *testing.T) {
+	schema, err := Compile([]byte(`{"type":"object","properties":{"n":{"type":"integer","enum":[1,2]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(strings.NewReader(`{"n":2}`))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []interface{}{v, map[string]interface{}{"n": 1}, map[string]interface{}{"n": int64(2)}} {
+		if err := schema.Validate(input); err != nil {
+			t.Errorf("Validate(%v) 返回错误: %v", input, err)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"非 JSON", `{"type":`},
+		{"不支持的关键字", `{"type":"object","oneOf":[]}`},
+		{"不支持的类型", `{"type":"null"}`},
+		{"类型数组", `{"type":["string","null"]}`},
+		{"不支持的格式", `{"type":"string","format":"uuid"}`},
+		{"长度为负数", `{"type":"string","minLength":-1}`},
+		{"正则错误", `{"type":"string","pattern":"("}`},
+		{"必填属性未定义", `{"type":"object","additionalProperties":false,"required":["a"],"properties":{}}`},
+		{"属性定义为空", `{"type":"object","properties":{"a":null}}`},
+		{"嵌套属性错误", `{"type":"object","properties":{"a":{"type":"date"}}}`},
+		{"数组元素错误", `{"type":"array","items":{"maxItems":-1}}`},
+		{"多余内容", `{"type":"object"} {}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile([]byte(tt.schema)); err == nil {
+				t.Error("期望返回错误")
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
@@ -19,23 +21,73 @@ const (
 	UserIdKey   = "sub"                                          // 用户ID在claims中的key
 	UsernameKey = "username"                                     // 用户名在claims中的key
 	RolesKey    = "roles"                                        // 角色在claims中的key
+	TenantIdKey = "tid"                                          // 租户ID在claims中的key
 	RolePrefix  = "ROLE_"                                        // 角色前缀
+
+	AMRPassword = "pwd" // 认证方式：密码（RFC 8176）
+
+	ImpersonationExpiration = 15 * time.Minute // 模拟登录 Token 有效期
+	ImpersonationKeyPrefix  = "impersonation:" // 模拟登录 Token 在 Redis 中的 key 前缀
+
+	ScopePasswordChange      = "password_change" // 受限 Token：只能用于修改密码
+	PasswordChangeExpiration = 15 * time.Minute  // 修改密码 Token 有效期
 )
 
+// Actor 模拟登录时的真实操作人（RFC 8693 act 声明）
+type Actor struct {
+	UserId   string `json:"sub"`
+	Username string `json:"username"`
+}
+
 // CustomClaims 自定义Claims结构
 type CustomClaims struct {
 	UserId   string   `json:"sub"`
 	Username string   `json:"username"`
 	Roles    []string `json:"roles"`
+	TenantId int64    `json:"tid"`
+	Act      *Actor   `json:"act,omitempty"`       // 不为空表示当前 Token 为模拟登录
+	AuthTime int64    `json:"auth_time,omitempty"` // 最近一次完成身份认证的时间（Unix 秒）
+	AMR      []string `json:"amr,omitempty"`       // 最近一次认证使用的方式
+	Scope    string   `json:"scope,omitempty"`     // 不为空表示受限 Token，只能访问对应的接口
 	jwt.RegisteredClaims
 }
 
+// AuthAge 返回距最近一次身份认证经过的时间，未记录认证时间时返回 false
+func (c *CustomClaims) AuthAge() (time.Duration, bool) {
+	if c.AuthTime == 0 {
+		return 0, false
+	}
+	return time.Since(time.Unix(c.AuthTime, 0)), true
+}
+
+// IsRestricted 判断是否为受限 Token
+func (c *CustomClaims) IsRestricted() bool {
+	return c.Scope != ""
+}
+
+// IsImpersonation 判断是否为模拟登录 Token
+func (c *CustomClaims) IsImpersonation() bool {
+	return c.Act != nil
+}
+
+// SessionKey 返回 Token 在 Redis 中的存储 key：
+// 普通登录按用户名存储，模拟登录按 Token ID 单独存储，互不覆盖
+func SessionKey(claims *CustomClaims) string {
+	if claims.IsImpersonation() {
+		return ImpersonationKeyPrefix + claims.ID
+	}
+	return claims.Username
+}
+
 // GenerateToken 生成JWT Token
-func GenerateToken(userId, username string, roles []string) (string, error) {
+func GenerateToken(userId, username string, tenantId int64, roles []string) (string, error) {
 	claims := CustomClaims{
 		UserId:   userId,
 		Username: username,
 		Roles:    roles,
+		TenantId: tenantId,
+		AuthTime: time.Now().Unix(),
+		AMR:      []string{AMRPassword},
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(Expiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -46,6 +98,72 @@ func GenerateToken(userId, username string, roles []string) (string, error) {
 	return token.SignedString([]byte(SecretKey))
 }
 
+// ReissueToken 基于已有 claims 重新签发 Token，保留原认证时间与认证方式
+func ReissueToken(old *CustomClaims) (string, error) {
+	claims := CustomClaims{
+		UserId:   old.UserId,
+		Username: old.Username,
+		Roles:    old.Roles,
+		TenantId: old.TenantId,
+		AuthTime: old.AuthTime,
+		AMR:      old.AMR,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(Expiration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(SecretKey))
+}
+
+// GeneratePasswordChangeToken 生成只能用于修改密码的受限 Token，不携带角色
+func GeneratePasswordChangeToken(userId, username string, tenantId int64) (string, error) {
+	claims := CustomClaims{
+		UserId:   userId,
+		Username: username,
+		TenantId: tenantId,
+		AuthTime: time.Now().Unix(),
+		AMR:      []string{AMRPassword},
+		Scope:    ScopePasswordChange,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(PasswordChangeExpiration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(SecretKey))
+}
+
+// GenerateImpersonationToken 生成模拟登录 Token：主体为被模拟用户，act 声明记录真实操作人
+func GenerateImpersonationToken(userId, username string, tenantId int64, roles []string, actor Actor) (string, *CustomClaims, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	claims := &CustomClaims{
+		UserId:   userId,
+		Username: username,
+		Roles:    roles,
+		TenantId: tenantId,
+		Act:      &actor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(jti),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ImpersonationExpiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(SecretKey))
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
 // ParseToken 解析Token
 func ParseToken(tokenString string) (*CustomClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
@@ -125,6 +243,21 @@ func GetCurrentUserRoles(c *gin.Context) ([]string, error) {
 	return customClaims.Roles, nil
 }
 
+// GetCurrentTenantId 获取当前用户所属租户ID
+func GetCurrentTenantId(c *gin.Context) (int64, error) {
+	claims, exists := c.Get(ContextKey)
+	if !exists {
+		return 0, errors.New("claims not found in context")
+	}
+
+	customClaims, ok := claims.(*CustomClaims)
+	if !ok {
+		return 0, errors.New("invalid claims type")
+	}
+
+	return customClaims.TenantId, nil
+}
+
 // ValidateToken 验证Token有效性
 func ValidateToken(tokenString string) bool {
 	_, err := ParseToken(tokenString)
@@ -0,0 +1,67 @@
+// Package notify 提供可替换的用户通知渠道，用于安全提醒等场景。
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"strings"
+)
+
+// Message 通知内容
+type Message struct {
+	To      string // 收件地址，如邮箱
+	Subject string
+	Body    string
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// LogNotifier 仅将通知写入日志，用于未配置外部渠道的环境
+type LogNotifier struct{}
+
+// Notify 实现 Notifier
+func (LogNotifier) Notify(_ context.Context, msg Message) error {
+	log.Printf("[通知] to=%s subject=%s body=%s", msg.To, msg.Subject, strings.ReplaceAll(msg.Body, "\n", " | "))
+	return nil
+}
+
+// SMTPNotifier 通过 SMTP 发送邮件通知
+type SMTPNotifier struct {
+	Addr     string // host:port
+	From     string
+	Username string
+	Password string
+}
+
+// Notify 实现 Notifier，收件地址为空时忽略
+func (n SMTPNotifier) Notify(_ context.Context, msg Message) error {
+	if msg.To == "" {
+		return nil
+	}
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return fmt.Errorf("邮件头包含非法字符")
+	}
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		host := n.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", n.Username, n.Password, host)
+	}
+
+	body := "From: " + n.From + "\r\n" +
+		"To: " + msg.To + "\r\n" +
+		"Subject: " + mime.QEncoding.Encode("UTF-8", msg.Subject) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + strings.ReplaceAll(msg.Body, "\n", "\r\n")
+	return smtp.SendMail(n.Addr, auth, n.From, []string{msg.To}, []byte(body))
+}
@@ -0,0 +1,124 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2idParams argon2id 参数，默认值取 OWASP 推荐的 m=19MiB、t=2、p=1
+type Argon2idParams struct {
+	Memory      uint32 // 内存（KiB）
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+func (p Argon2idParams) withDefaults() Argon2idParams {
+	if p.Memory == 0 {
+		p.Memory = 19 * 1024
+	}
+	if p.Iterations == 0 {
+		p.Iterations = 2
+	}
+	if p.Parallelism == 0 {
+		p.Parallelism = 1
+	}
+	if p.SaltLength == 0 {
+		p.SaltLength = 16
+	}
+	if p.KeyLength == 0 {
+		p.KeyLength = 32
+	}
+	return p
+}
+
+// hashArgon2id 生成 PHC 格式的 argon2id 哈希：$argon2id$v=19$m=...,t=...,p=...[,keyid=...]$salt$hash
+func hashArgon2id(password string, p Argon2idParams, keyID string) (string, error) {
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("生成盐失败: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", p.Memory, p.Iterations, p.Parallelism)
+	if keyID != "" {
+		params += ",keyid=" + keyID
+	}
+	return fmt.Sprintf("$%s$v=%d$%s$%s$%s", AlgorithmArgon2id, argon2.Version, params,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// argon2idHash 解析后的 argon2id 哈希
+type argon2idHash struct {
+	params Argon2idParams // 盐与哈希长度也记录在参数中，以便判断是否需要升级
+	keyID  string         // 使用 pepper 时的密钥标识
+	salt   []byte
+	key    []byte
+}
+
+// verify 使用哈希中记录的参数重新计算并比较
+func (h *argon2idHash) verify(password string) bool {
+	p := h.params
+	got := argon2.IDKey([]byte(password), h.salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return subtle.ConstantTimeCompare(got, h.key) == 1
+}
+
+// parseArgon2id 解析 PHC 格式的 argon2id 哈希
+func parseArgon2id(encoded string) (*argon2idHash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return nil, fmt.Errorf("argon2id 哈希格式错误")
+	}
+	if parts[2] != "v="+strconv.Itoa(argon2.Version) {
+		return nil, fmt.Errorf("不支持的 argon2 版本: %s", parts[2])
+	}
+
+	h := &argon2idHash{}
+	for _, kv := range strings.Split(parts[3], ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("argon2id 参数格式错误")
+		}
+		if k == "keyid" {
+			h.keyID = v
+			continue
+		}
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("argon2id 参数格式错误")
+		}
+		switch k {
+		case "m":
+			h.params.Memory = uint32(n)
+		case "t":
+			h.params.Iterations = uint32(n)
+		case "p":
+			if n > 255 {
+				return nil, fmt.Errorf("argon2id 参数格式错误")
+			}
+			h.params.Parallelism = uint8(n)
+		}
+	}
+	if h.params.Memory == 0 || h.params.Iterations == 0 || h.params.Parallelism == 0 {
+		return nil, fmt.Errorf("argon2id 参数不完整")
+	}
+
+	var err error
+	if h.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("argon2id 盐格式错误")
+	}
+	if h.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(h.key) == 0 {
+		return nil, fmt.Errorf("argon2id 哈希格式错误")
+	}
+	h.params.SaltLength = uint32(len(h.salt))
+	h.params.KeyLength = uint32(len(h.key))
+	return h, nil
+}
@@ -0,0 +1,35 @@
+package password
+
+import (
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	defaultBcryptCost = bcrypt.DefaultCost
+	minBcryptCost     = bcrypt.MinCost
+	maxBcryptCost     = bcrypt.MaxCost
+)
+
+// hashBcrypt 生成 bcrypt 哈希（$2a$ 开头的模块化格式）
+func hashBcrypt(password string, cost int) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func verifyBcrypt(password, encoded string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)) == nil
+}
+
+func bcryptCost(encoded string) (int, error) {
+	return bcrypt.Cost([]byte(encoded))
+}
+
+// isBcrypt 判断是否为 bcrypt 哈希
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
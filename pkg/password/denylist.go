@@ -0,0 +1,91 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// commonPasswords 内置常见密码列表，按使用频率排序，每行一个
+//
+//go:embed common.txt
+var commonPasswords string
+
+var (
+	builtinOnce sync.Once
+	builtin     *DenyList
+)
+
+// DenyList 常见密码黑名单，同时作为强度评估的字典，排名越靠前越容易被猜中
+type DenyList struct {
+	ranks map[string]int
+}
+
+// Builtin 返回内置黑名单
+func Builtin() *DenyList {
+	builtinOnce.Do(func() {
+		builtin = &DenyList{ranks: make(map[string]int)}
+		_ = builtin.read(strings.NewReader(commonPasswords))
+	})
+	return builtin
+}
+
+// LoadDenyList 在内置黑名单之后追加文件中的密码（每行一个，# 开头为注释），path 为空时只使用内置列表
+func LoadDenyList(path string) (*DenyList, error) {
+	list := &DenyList{ranks: make(map[string]int, len(Builtin().ranks))}
+	for w, r := range Builtin().ranks {
+		list.ranks[w] = r
+	}
+	if path == "" {
+		return list, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开密码黑名单失败: %w", err)
+	}
+	defer f.Close()
+	if err := list.read(f); err != nil {
+		return nil, fmt.Errorf("读取密码黑名单失败: %w", err)
+	}
+	return list, nil
+}
+
+// Contains 判断密码（忽略大小写及 l33t 替换）是否在黑名单中
+func (d *DenyList) Contains(password string) bool {
+	lower := strings.ToLower(password)
+	if _, ok := d.ranks[lower]; ok {
+		return true
+	}
+	_, ok := d.ranks[unleet(lower)]
+	return ok
+}
+
+// Len 返回黑名单条数
+func (d *DenyList) Len() int {
+	return len(d.ranks)
+}
+
+// rank 返回词的排名（从 1 开始）
+func (d *DenyList) rank(word string) (int, bool) {
+	r, ok := d.ranks[word]
+	return r, ok
+}
+
+// read 逐行读取，已存在的词保留原排名
+func (d *DenyList) read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		w := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		if _, ok := d.ranks[w]; !ok {
+			d.ranks[w] = len(d.ranks) + 1
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,71 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDenyListContains(t *testing.T) {
+	d := Builtin()
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"password", true},
+		{"PassWord", true},
+		{"p@ssw0rd", true},
+		{"qwerty123", true},
+		{"x7#Kq9!vLm2$", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := d.Contains(tt.password); got != tt.want {
+			t.Errorf("Contains(%q) = %v, 期望 %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestLoadDenyList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deny.txt")
+	content := "# 公司内部常见密码\n\nCompany2024\n  acme!  \npassword\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := LoadDenyList(path)
+	if err != nil {
+		t.Fatalf("LoadDenyList 返回错误: %v", err)
+	}
+	// 注释、空行和内置列表中已有的词不计入
+	if got, want := d.Len(), Builtin().Len()+2; got != want {
+		t.Errorf("Len = %d, 期望 %d", got, want)
+	}
+	for _, pw := range []string{"company2024", "ACME!", "password"} {
+		if !d.Contains(pw) {
+			t.Errorf("Contains(%q) = false, 期望 true", pw)
+		}
+	}
+	if d.Contains("# 公司内部常见密码") {
+		t.Error("注释行不应加入黑名单")
+	}
+	// 已有的词保留原排名，新词追加在末尾
+	if r, _ := d.rank("password"); r != 2 {
+		t.Errorf("password 排名 = %d, 期望 2", r)
+	}
+	if r, _ := d.rank("company2024"); r != Builtin().Len()+1 {
+		t.Errorf("company2024 排名 = %d, 期望 %d", r, Builtin().Len()+1)
+	}
+	// 追加的内容不影响内置列表
+	if Builtin().Contains("company2024") {
+		t.Error("LoadDenyList 修改了内置黑名单")
+	}
+
+	empty, err := LoadDenyList("")
+	if err != nil || empty.Len() != Builtin().Len() {
+		t.Errorf("LoadDenyList(\"\") = (%d 条, %v), 期望与内置列表相同", empty.Len(), err)
+	}
+	if _, err := LoadDenyList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("文件不存在时期望返回错误")
+	}
+}
@@ -0,0 +1,115 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// 哈希算法
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// HasherConfig 密码哈希配置
+type HasherConfig struct {
+	Algorithm  string // 新密码使用的算法：argon2id 或 bcrypt
+	Argon2id   Argon2idParams
+	BcryptCost int
+	// Pepper 服务端密钥，与密码做 HMAC 后再哈希，不存入数据库；为空时不使用
+	Pepper string
+}
+
+// Hasher 按 PHC 字符串格式生成与校验密码哈希：新密码使用配置的算法，
+// 校验时根据哈希前缀识别算法，因此旧算法、旧参数生成的哈希仍然可以校验，并在登录时升级。
+type Hasher struct {
+	algorithm  string
+	argon2id   Argon2idParams
+	bcryptCost int
+	pepper     []byte
+	pepperID   string
+}
+
+// NewHasher 创建 Hasher，未设置的参数使用默认值
+func NewHasher(cfg HasherConfig) (*Hasher, error) {
+	h := &Hasher{
+		algorithm:  cfg.Algorithm,
+		argon2id:   cfg.Argon2id.withDefaults(),
+		bcryptCost: cfg.BcryptCost,
+	}
+	if h.algorithm == "" {
+		h.algorithm = AlgorithmArgon2id
+	}
+	if h.algorithm != AlgorithmArgon2id && h.algorithm != AlgorithmBcrypt {
+		return nil, fmt.Errorf("不支持的密码哈希算法: %s", cfg.Algorithm)
+	}
+	if h.bcryptCost == 0 {
+		h.bcryptCost = defaultBcryptCost
+	}
+	if h.bcryptCost < minBcryptCost || h.bcryptCost > maxBcryptCost {
+		return nil, fmt.Errorf("bcrypt cost 应在 %d-%d 之间", minBcryptCost, maxBcryptCost)
+	}
+	if cfg.Pepper != "" {
+		h.pepper = []byte(cfg.Pepper)
+		sum := sha256.Sum256(h.pepper)
+		h.pepperID = base64.RawStdEncoding.EncodeToString(sum[:6])
+	}
+	return h, nil
+}
+
+// Hash 使用当前配置的算法与参数生成哈希
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.algorithm == AlgorithmBcrypt {
+		return hashBcrypt(h.pepperInput(password, true), h.bcryptCost)
+	}
+	return hashArgon2id(h.pepperInput(password, true), h.argon2id, h.pepperID)
+}
+
+// Verify 校验密码，rehash 为 true 表示哈希的算法、参数或 pepper 与当前配置不一致，应在校验通过后重新生成
+func (h *Hasher) Verify(password, encoded string) (ok bool, rehash bool) {
+	switch {
+	case strings.HasPrefix(encoded, "$"+AlgorithmArgon2id+"$"):
+		parsed, err := parseArgon2id(encoded)
+		if err != nil {
+			return false, false
+		}
+		// 使用了 pepper 的哈希带有 keyid，keyid 与当前 pepper 不一致时无法校验
+		if parsed.keyID != "" && parsed.keyID != h.pepperID {
+			return false, false
+		}
+		if !parsed.verify(h.pepperInput(password, parsed.keyID != "")) {
+			return false, false
+		}
+		return true, h.algorithm != AlgorithmArgon2id || parsed.params != h.argon2id || parsed.keyID != h.pepperID
+	case isBcrypt(encoded):
+		// bcrypt 哈希无法记录是否使用了 pepper：配置了 pepper 时先按使用 pepper 校验，失败再按旧哈希校验
+		cost, err := bcryptCost(encoded)
+		if err != nil {
+			return false, false
+		}
+		outdated := h.algorithm != AlgorithmBcrypt || cost != h.bcryptCost
+		if verifyBcrypt(h.pepperInput(password, true), encoded) {
+			return true, outdated
+		}
+		if h.pepper != nil && verifyBcrypt(password, encoded) {
+			return true, true
+		}
+		return false, false
+	default:
+		return false, false
+	}
+}
+
+// pepperInput 返回实际参与哈希的内容：使用 pepper 时为 HMAC-SHA256(pepper, 密码) 的 Base64，
+// 长度固定且不含 NUL 字节，也避免了 bcrypt 只取前 72 字节的问题
+func (h *Hasher) pepperInput(password string, usePepper bool) string {
+	if !usePepper || h.pepper == nil {
+		return password
+	}
+	mac := hmac.New(sha256.New, h.pepper)
+	mac.Write([]byte(password))
+	return base64.RawStdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,168 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+// 测试使用较小的 argon2id 参数与最低 bcrypt cost，避免拖慢测试
+var (
+	testArgon2id     = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1}
+	testArgon2idSlow = Argon2idParams{Memory: 64, Iterations: 2, Parallelism: 1}
+)
+
+func newTestHasher(t *testing.T, cfg HasherConfig) *Hasher {
+	t.Helper()
+	h, err := NewHasher(cfg)
+	if err != nil {
+		t.Fatalf("NewHasher 返回错误: %v", err)
+	}
+	return h
+}
+
+func TestHasherVerify(t *testing.T) {
+	argon := HasherConfig{Algorithm: AlgorithmArgon2id, Argon2id: testArgon2id, BcryptCost: minBcryptCost}
+	argonSlow := HasherConfig{Algorithm: AlgorithmArgon2id, Argon2id: testArgon2idSlow, BcryptCost: minBcryptCost}
+	argonPepper := argon
+	argonPepper.Pepper = "pepper-a"
+	argonOtherPepper := argon
+	argonOtherPepper.Pepper = "pepper-b"
+	bcryptCfg := HasherConfig{Algorithm: AlgorithmBcrypt, Argon2id: testArgon2id, BcryptCost: minBcryptCost}
+	bcryptHigher := bcryptCfg
+	bcryptHigher.BcryptCost = minBcryptCost + 1
+	bcryptPepper := bcryptCfg
+	bcryptPepper.Pepper = "pepper-a"
+
+	const pw = "correct horse battery staple"
+	tests := []struct {
+		name       string
+		hashWith   HasherConfig
+		verifyWith HasherConfig
+		password   string
+		wantOK     bool
+		wantRehash bool
+	}{
+		{"argon2id 参数一致", argon, argon, pw, true, false},
+		{"argon2id 密码错误", argon, argon, "wrong", false, false},
+		{"argon2id 参数变化需升级", argon, argonSlow, pw, true, true},
+		{"argon2id 切换到 bcrypt 需升级", argon, bcryptCfg, pw, true, true},
+		{"无 pepper 哈希在启用 pepper 后仍可校验并升级", argon, argonPepper, pw, true, true},
+		{"argon2id pepper 一致", argonPepper, argonPepper, pw, true, false},
+		{"argon2id pepper 密码错误", argonPepper, argonPepper, "wrong", false, false},
+		{"argon2id 移除 pepper 后无法校验", argonPepper, argon, pw, false, false},
+		{"argon2id pepper 不一致无法校验", argonPepper, argonOtherPepper, pw, false, false},
+		{"bcrypt cost 一致", bcryptCfg, bcryptCfg, pw, true, false},
+		{"bcrypt 密码错误", bcryptCfg, bcryptCfg, "wrong", false, false},
+		{"bcrypt cost 变化需升级", bcryptCfg, bcryptHigher, pw, true, true},
+		{"bcrypt 切换到 argon2id 需升级", bcryptCfg, argon, pw, true, true},
+		{"无 pepper 的 bcrypt 哈希在启用 pepper 后仍可校验并升级", bcryptCfg, bcryptPepper, pw, true, true},
+		{"bcrypt pepper 一致", bcryptPepper, bcryptPepper, pw, true, false},
+		{"bcrypt pepper 密码错误", bcryptPepper, bcryptPepper, "wrong", false, false},
+		{"bcrypt 移除 pepper 后无法校验", bcryptPepper, bcryptCfg, pw, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := newTestHasher(t, tt.hashWith).Hash(pw)
+			if err != nil {
+				t.Fatalf("Hash 返回错误: %v", err)
+			}
+			ok, rehash := newTestHasher(t, tt.verifyWith).Verify(tt.password, encoded)
+			if ok != tt.wantOK || rehash != tt.wantRehash {
+				t.Errorf("Verify = (%v, %v), 期望 (%v, %v)，哈希 %s", ok, rehash, tt.wantOK, tt.wantRehash, encoded)
+			}
+		})
+	}
+}
+
+func TestHasherHashFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    HasherConfig
+		prefix string
+		keyID  bool
+	}{
+		{"argon2id", HasherConfig{Argon2id: testArgon2id}, "$argon2id$v=19$m=64,t=1,p=1$", false},
+		{"argon2id 带 pepper", HasherConfig{Argon2id: testArgon2id, Pepper: "pepper-a"}, "$argon2id$v=19$m=64,t=1,p=1,keyid=", true},
+		{"bcrypt", HasherConfig{Algorithm: AlgorithmBcrypt, BcryptCost: minBcryptCost}, "$2a$04$", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHasher(t, tt.cfg)
+			a, err := h.Hash("secret")
+			if err != nil {
+				t.Fatalf("Hash 返回错误: %v", err)
+			}
+			b, _ := h.Hash("secret")
+			if !strings.HasPrefix(a, tt.prefix) {
+				t.Errorf("Hash = %s, 期望前缀 %s", a, tt.prefix)
+			}
+			if a == b {
+				t.Error("两次哈希结果相同，盐没有随机生成")
+			}
+			if strings.Contains(a, "pepper-a") {
+				t.Error("哈希中泄露了 pepper 明文")
+			}
+		})
+	}
+}
+
+// bcrypt 只取前 72 字节，使用 pepper 时先做 HMAC，超出部分不同的密码也能区分
+func TestHasherBcryptLongPasswordWithPepper(t *testing.T) {
+	h := newTestHasher(t, HasherConfig{Algorithm: AlgorithmBcrypt, BcryptCost: minBcryptCost, Pepper: "pepper-a"})
+	prefix := strings.Repeat("a", 80)
+	encoded, err := h.Hash(prefix + "1")
+	if err != nil {
+		t.Fatalf("Hash 返回错误: %v", err)
+	}
+	if ok, _ := h.Verify(prefix+"2", encoded); ok {
+		t.Error("超过 72 字节后不同的密码校验通过")
+	}
+	if ok, _ := h.Verify(prefix+"1", encoded); !ok {
+		t.Error("正确密码校验失败")
+	}
+}
+
+func TestHasherVerifyMalformed(t *testing.T) {
+	h := newTestHasher(t, HasherConfig{Argon2id: testArgon2id})
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"空字符串", ""},
+		{"明文", "secret"},
+		{"未知算法", "$scrypt$ln=15,r=8,p=1$c2FsdA$aGFzaA"},
+		{"argon2id 段数不足", "$argon2id$v=19$m=64,t=1,p=1$c2FsdA"},
+		{"argon2id 版本不支持", "$argon2id$v=16$m=64,t=1,p=1$c2FsdA$aGFzaA"},
+		{"argon2id 参数缺失", "$argon2id$v=19$m=64,p=1$c2FsdA$aGFzaA"},
+		{"argon2id 参数非数字", "$argon2id$v=19$m=x,t=1,p=1$c2FsdA$aGFzaA"},
+		{"argon2id 并行度越界", "$argon2id$v=19$m=64,t=1,p=256$c2FsdA$aGFzaA"},
+		{"argon2id 盐非 Base64", "$argon2id$v=19$m=64,t=1,p=1$!!$aGFzaA"},
+		{"argon2id 哈希为空", "$argon2id$v=19$m=64,t=1,p=1$c2FsdA$"},
+		{"bcrypt 格式错误", "$2a$04$short"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ok, rehash := h.Verify("secret", tt.encoded); ok || rehash {
+				t.Errorf("Verify(%q) = (%v, %v), 期望 (false, false)", tt.encoded, ok, rehash)
+			}
+		})
+	}
+}
+
+func TestNewHasherErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  HasherConfig
+	}{
+		{"不支持的算法", HasherConfig{Algorithm: "md5"}},
+		{"bcrypt cost 过低", HasherConfig{Algorithm: AlgorithmBcrypt, BcryptCost: minBcryptCost - 1}},
+		{"bcrypt cost 过高", HasherConfig{Algorithm: AlgorithmBcrypt, BcryptCost: maxBcryptCost + 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewHasher(tt.cfg); err == nil {
+				t.Error("期望返回错误")
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Package password 提供密码策略校验与强度评估：长度、字符类别、不得包含用户信息、
+// 常见密码黑名单以及基于模式匹配的熵估计（参考 zxcvbn 的思路，规则做了简化）。
+package password
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 违规代码
+const (
+	CodeTooShort     = "too_short"
+	CodeTooLong      = "too_long"
+	CodeTooFewClass  = "too_few_classes"
+	CodeContainsUser = "contains_user_info"
+	CodeCommon       = "common_password"
+	CodeTooWeak      = "too_weak"
+	CodeBreached     = "breached"
+)
+
+// Violation 一条不满足的规则
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PolicyError 密码不满足策略，Violations 列出全部不满足的规则
+type PolicyError struct {
+	Violations []Violation
+}
+
+// Error 实现 error，多条违规以分号连接
+func (e *PolicyError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Message
+	}
+	return "密码不符合要求：" + strings.Join(msgs, "；")
+}
+
+// BreachChecker 泄露密码库，返回密码在库中出现的次数
+type BreachChecker interface {
+	Count(ctx context.Context, password string) (int, error)
+}
+
+// Policy 密码策略，零值字段表示不检查该项
+type Policy struct {
+	MinLength  int // 最少字符数
+	MaxLength  int // 最多字符数，bcrypt 只使用前 72 字节
+	MinClasses int // 大写、小写、数字、符号四类中至少包含的类别数
+	MinScore   int // 最低强度评分（0-4）
+	// DenyUserInfo 为 true 时密码不得包含用户名、邮箱前缀等用户信息
+	DenyUserInfo bool
+	// Deny 常见密码黑名单，为空时使用内置列表
+	Deny *DenyList
+	// Breach 泄露密码库，为空时不检查
+	Breach BreachChecker
+	// BreachThreshold 在泄露库中出现次数达到该值即拒绝，小于 1 时按 1 处理
+	BreachThreshold int
+}
+
+// Check 校验密码，userInputs 为用户名、邮箱等用户信息，全部满足时返回 nil，否则返回 *PolicyError。
+// 泄露库查询只在其他规则都满足时进行，查询失败时放行并记录日志。
+func (p *Policy) Check(ctx context.Context, password string, userInputs ...string) error {
+	var violations []Violation
+	add := func(code, format string, args ...interface{}) {
+		violations = append(violations, Violation{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		add(CodeTooShort, "长度至少 %d 位", p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		add(CodeTooLong, "长度不能超过 %d 位", p.MaxLength)
+	}
+	if p.MinClasses > 0 && characterClasses(password) < p.MinClasses {
+		add(CodeTooFewClass, "需包含大写字母、小写字母、数字、符号中的至少 %d 类", p.MinClasses)
+	}
+	if p.DenyUserInfo && containsUserInput(password, userInputs) {
+		add(CodeContainsUser, "不能包含用户名或邮箱")
+	}
+	deny := p.denyList()
+	if deny.Contains(password) {
+		add(CodeCommon, "不能使用常见密码")
+	}
+	if p.MinScore > 0 {
+		if s := Estimate(password, deny, userInputs...); s.Score < p.MinScore {
+			add(CodeTooWeak, "强度不足，请使用更长或更不易猜测的密码")
+		}
+	}
+
+	if len(violations) == 0 && p.Breach != nil {
+		threshold := max(p.BreachThreshold, 1)
+		if n, err := p.Breach.Count(ctx, password); err != nil {
+			log.Printf("查询泄露密码库失败: %v", err)
+		} else if n >= threshold {
+			add(CodeBreached, "该密码已出现在公开泄露的密码库中")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Strength 评估密码强度，不判断是否满足策略
+func (p *Policy) Strength(password string, userInputs ...string) Strength {
+	return Estimate(password, p.denyList(), userInputs...)
+}
+
+func (p *Policy) denyList() *DenyList {
+	if p.Deny != nil {
+		return p.Deny
+	}
+	return Builtin()
+}
+
+// characterClasses 统计密码包含的字符类别数
+func characterClasses(password string) int {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	n := 0
+	for _, ok := range []bool{upper, lower, digit, symbol} {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// containsUserInput 判断密码（忽略大小写）是否包含长度不少于 3 的用户信息，邮箱只比较 @ 之前的部分
+func containsUserInput(password string, userInputs []string) bool {
+	lower := strings.ToLower(password)
+	for _, in := range normalizeInputs(userInputs) {
+		if strings.Contains(lower, in) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeInputs 转为小写、去掉邮箱域名并过滤过短的内容
+func normalizeInputs(userInputs []string) []string {
+	list := make([]string, 0, len(userInputs))
+	for _, in := range userInputs {
+		in = strings.ToLower(strings.TrimSpace(in))
+		if i := strings.IndexByte(in, '@'); i >= 0 {
+			in = in[:i]
+		}
+		if utf8.RuneCountInString(in) >= 3 {
+			list = append(list, in)
+		}
+	}
+	return list
+}
@@ -0,0 +1,120 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeBreach 固定返回出现次数的泄露库
+type fakeBreach struct {
+	count int
+	err   error
+	calls int
+}
+
+func (f *fakeBreach) Count(context.Context, string) (int, error) {
+	f.calls++
+	return f.count, f.err
+}
+
+func TestPolicyCheck(t *testing.T) {
+	base := Policy{MinLength: 8, MaxLength: 64, MinClasses: 3, DenyUserInfo: true}
+	withScore := base
+	withScore.MinScore = 3
+
+	tests := []struct {
+		name       string
+		policy     Policy
+		password   string
+		userInputs []string
+		want       []string
+	}{
+		{"全部满足", base, "x7#Kq9!vLm2$", []string{"alice"}, nil},
+		{"零值策略只检查黑名单", Policy{}, "a", nil, nil},
+		{"长度按字符计算", base, "密码Ab1!", nil, []string{CodeTooShort}},
+		{"长度恰好等于下限", base, "x7#Kq9!v", nil, nil},
+		{"超过最大长度", Policy{MaxLength: 4}, "abcde", nil, []string{CodeTooLong}},
+		{"字符类别不足", base, "onlylowercase1", nil, []string{CodeTooFewClass}},
+		{"包含用户名（忽略大小写）", base, "xALICE#9k", []string{"alice"}, []string{CodeContainsUser}},
+		{"包含邮箱前缀", base, "Bob.smith#9", []string{"bob.smith@example.com"}, []string{CodeContainsUser}},
+		{"过短的用户信息不检查", base, "Bo#9xkqzm", []string{"bo"}, nil},
+		{"不检查用户信息", Policy{MinLength: 8}, "alice#2024", []string{"alice"}, nil},
+		{"常见密码", Policy{}, "P@ssw0rd", nil, []string{CodeCommon}},
+		{"强度不足", withScore, "Qwerty#2024", nil, []string{CodeTooWeak}},
+		{"强度足够", withScore, "x7#Kq9!vLm2$", nil, nil},
+		{"多条违规全部返回", base, "Abc1", []string{"abc1"}, []string{CodeTooShort, CodeContainsUser}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertViolations(t, tt.policy.Check(context.Background(), tt.password, tt.userInputs...), tt.want)
+		})
+	}
+}
+
+func TestPolicyCheckDenyList(t *testing.T) {
+	deny := &DenyList{ranks: map[string]int{"acme2024": 1}}
+	p := Policy{Deny: deny}
+	assertViolations(t, p.Check(context.Background(), "ACME2024"), []string{CodeCommon})
+	// 指定黑名单后不再使用内置列表
+	assertViolations(t, p.Check(context.Background(), "password"), nil)
+}
+
+func TestPolicyCheckBreach(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		count     int
+		err       error
+		password  string
+		want      []string
+		wantCalls int
+	}{
+		{"未出现", 1, 0, nil, "x7#Kq9!vLm2$", nil, 1},
+		{"阈值小于 1 按 1 处理", 0, 1, nil, "x7#Kq9!vLm2$", []string{CodeBreached}, 1},
+		{"低于阈值", 10, 9, nil, "x7#Kq9!vLm2$", nil, 1},
+		{"恰好达到阈值", 10, 10, nil, "x7#Kq9!vLm2$", []string{CodeBreached}, 1},
+		{"查询失败时放行", 1, 0, errors.New("timeout"), "x7#Kq9!vLm2$", nil, 1},
+		{"其他规则不满足时不查询", 1, 100, nil, "short", []string{CodeTooShort}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breach := &fakeBreach{count: tt.count, err: tt.err}
+			p := Policy{MinLength: 8, Breach: breach, BreachThreshold: tt.threshold}
+			assertViolations(t, p.Check(context.Background(), tt.password), tt.want)
+			if breach.calls != tt.wantCalls {
+				t.Errorf("查询泄露库 %d 次, 期望 %d 次", breach.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPolicyErrorMessage(t *testing.T) {
+	err := &PolicyError{Violations: []Violation{{CodeTooShort, "长度至少 8 位"}, {CodeCommon, "不能使用常见密码"}}}
+	if got, want := err.Error(), "密码不符合要求：长度至少 8 位；不能使用常见密码"; got != want {
+		t.Errorf("Error = %s, 期望 %s", got, want)
+	}
+}
+
+// assertViolations 校验 Check 返回的违规代码，want 为空时期望返回 nil
+func assertViolations(t *testing.T, err error, want []string) {
+	t.Helper()
+	if want == nil {
+		if err != nil {
+			t.Errorf("Check 返回错误: %v", err)
+		}
+		return
+	}
+	var perr *PolicyError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Check 错误 = %v, 期望 *PolicyError", err)
+	}
+	codes := make([]string, len(perr.Violations))
+	for i, v := range perr.Violations {
+		codes[i] = v.Code
+	}
+	if !reflect.DeepEqual(codes, want) {
+		t.Errorf("违规代码 = %v, 期望 %v", codes, want)
+	}
+}
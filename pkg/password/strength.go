@@ -0,0 +1,241 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// 评分阈值（比特），与 zxcvbn 的 0-4 分含义大致对应：
+// 0 极易猜测，1 很容易，2 能抵御在线猜测，3 能抵御有限的离线破解，4 强
+var scoreThresholds = [...]float64{20, 28, 36, 48}
+
+// maxPatternLen 单个可预测片段的最大长度，限制超长输入的计算量
+const maxPatternLen = 32
+
+// keyboardRows 常见的键盘连续按键
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+	"1qaz2wsx3edc4rfv5tgb6yhn7ujm8ik9ol0p",
+	"qazwsxedcrfvtgbyhnujmikolp",
+}
+
+// Strength 密码强度评估结果
+type Strength struct {
+	Score   int     `json:"score"`   // 0-4
+	Entropy float64 `json:"entropy"` // 估计的熵（比特）
+}
+
+// Estimate 估计密码的熵：把密码切分为字典词、键盘连续按键、顺序字符、重复字符、年份等可预测片段，
+// 其余字符按字符集暴力猜测计算，取所有切分中熵最小的一种。userInputs 视为排名最靠前的字典词。
+func Estimate(password string, deny *DenyList, userInputs ...string) Strength {
+	runes := []rune(password)
+	n := len(runes)
+	if n == 0 {
+		return Strength{}
+	}
+	if deny == nil {
+		deny = Builtin()
+	}
+	inputs := make(map[string]struct{})
+	for _, in := range normalizeInputs(userInputs) {
+		inputs[in] = struct{}{}
+	}
+
+	charBits := math.Log2(float64(charsetSize(runes)))
+	// best[i] 为前 i 个字符的最小熵
+	best := make([]float64, n+1)
+	for j := 1; j <= n; j++ {
+		best[j] = best[j-1] + charBits
+		for i := max(0, j-maxPatternLen); i+3 <= j; i++ {
+			if bits, ok := patternBits(runes[i:j], deny, inputs); ok && best[i]+bits < best[j] {
+				best[j] = best[i] + bits
+			}
+		}
+	}
+
+	entropy := math.Round(best[n]*10) / 10
+	score := 0
+	for score < len(scoreThresholds) && entropy >= scoreThresholds[score] {
+		score++
+	}
+	return Strength{Score: score, Entropy: entropy}
+}
+
+// patternBits 计算片段作为可预测模式时的熵，不属于任何模式时返回 false
+func patternBits(seg []rune, deny *DenyList, inputs map[string]struct{}) (float64, bool) {
+	bits, ok := math.Inf(1), false
+	try := func(b float64, matched bool) {
+		if matched && b < bits {
+			bits, ok = b, true
+		}
+	}
+	try(dictionaryBits(seg, deny, inputs))
+	try(repeatBits(seg))
+	try(sequenceBits(seg))
+	try(keyboardBits(seg))
+	try(yearBits(seg))
+	return bits, ok
+}
+
+// dictionaryBits 字典词（含倒序与 l33t 替换）：log2(排名) 加上大小写与变形带来的少量熵
+func dictionaryBits(seg []rune, deny *DenyList, inputs map[string]struct{}) (float64, bool) {
+	word := string(seg)
+	lower := strings.ToLower(word)
+	extra := 0.0
+	if lower != word {
+		extra++
+	}
+	candidates := []struct {
+		word  string
+		extra float64
+	}{
+		{lower, 0},
+		{unleet(lower), 1},
+		{reverse(lower), 1},
+	}
+	bits, ok := math.Inf(1), false
+	for _, c := range candidates {
+		if c.extra > 0 && c.word == lower {
+			continue
+		}
+		rank := 0
+		if _, hit := inputs[c.word]; hit {
+			rank = 1
+		} else if r, hit := deny.rank(c.word); hit {
+			rank = r
+		}
+		if rank == 0 {
+			continue
+		}
+		if b := math.Log2(float64(rank)) + 1 + extra + c.extra; b < bits {
+			bits, ok = b, true
+		}
+	}
+	return bits, ok
+}
+
+// repeatBits 同一字符重复，如 aaaa
+func repeatBits(seg []rune) (float64, bool) {
+	for _, r := range seg[1:] {
+		if r != seg[0] {
+			return 0, false
+		}
+	}
+	return math.Log2(float64(charsetSize(seg[:1]) * len(seg))), true
+}
+
+// sequenceBits 字符编码连续递增或递减，如 abcd、4321
+func sequenceBits(seg []rune) (float64, bool) {
+	delta := seg[1] - seg[0]
+	if delta != 1 && delta != -1 {
+		return 0, false
+	}
+	for i := 2; i < len(seg); i++ {
+		if seg[i]-seg[i-1] != delta {
+			return 0, false
+		}
+	}
+	base := float64(charsetSize(seg[:1]))
+	if strings.ContainsRune("aAzZ019", seg[0]) {
+		base = 4 // 从显眼的字符开始
+	}
+	if delta < 0 {
+		base *= 2
+	}
+	return math.Log2(base * float64(len(seg))), true
+}
+
+// keyboardBits 键盘上连续的按键（正序或倒序），至少 4 个字符
+func keyboardBits(seg []rune) (float64, bool) {
+	if len(seg) < 4 {
+		return 0, false
+	}
+	s := strings.ToLower(string(seg))
+	rs := reverse(s)
+	for _, row := range keyboardRows {
+		if strings.Contains(row, s) || strings.Contains(row, rs) {
+			return math.Log2(float64(len(keyboardRows) * 2 * len(row) * len(seg))), true
+		}
+	}
+	return 0, false
+}
+
+// yearBits 1900-2099 之间的年份
+func yearBits(seg []rune) (float64, bool) {
+	if len(seg) != 4 {
+		return 0, false
+	}
+	s := string(seg)
+	if (strings.HasPrefix(s, "19") || strings.HasPrefix(s, "20")) && isDigits(s) {
+		return math.Log2(200), true
+	}
+	return 0, false
+}
+
+// charsetSize 按密码中出现的字符类别估算暴力猜测的字符集大小
+func charsetSize(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	size := 0
+	if lower {
+		size += 26
+	}
+	if upper {
+		size += 26
+	}
+	if digit {
+		size += 10
+	}
+	if symbol {
+		size += 33
+	}
+	if other {
+		size += 100
+	}
+	return size
+}
+
+// leetTable 常见的 l33t 替换
+var leetTable = strings.NewReplacer(
+	"4", "a", "@", "a", "8", "b", "3", "e", "6", "g", "1", "i", "!", "i",
+	"0", "o", "5", "s", "$", "s", "7", "t", "+", "t", "2", "z",
+)
+
+// unleet 还原 l33t 替换
+func unleet(s string) string {
+	return leetTable.Replace(s)
+}
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,70 @@
+package password
+
+import "testing"
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		entropy    float64
+		score      int
+	}{
+		{"空密码", "", nil, 0, 0},
+		{"黑名单第一位", "123456", nil, 1, 0},
+		{"黑名单单词", "password", nil, 2, 0},
+		{"大小写变形", "Password", nil, 3, 0},
+		{"l33t 替换", "p4ssword", nil, 3, 0},
+		{"倒序", "drowssap", nil, 3, 0},
+		{"重复字符", "aaaa", nil, 6.7, 0},
+		{"顺序字符", "abcd", nil, 4, 0},
+		{"倒序顺序字符", "dcba", nil, 7.7, 0},
+		{"年份", "2024", nil, 7.6, 0},
+		{"用户名加年份", "alice2024", []string{"alice"}, 8.6, 0},
+		{"邮箱前缀", "zhangsan123", []string{"zhangsan@example.com"}, 4.6, 0},
+		{"不含用户信息时按暴力猜测", "zhangsan123", nil, 44.9, 3},
+		{"随机字符", "x7#Kq9!vLm2$", nil, 78.8, 4},
+		{"长口令", "correct horse battery staple", nil, 164.7, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Estimate(tt.password, nil, tt.userInputs...)
+			if got.Entropy != tt.entropy || got.Score != tt.score {
+				t.Errorf("Estimate(%q) = %+v, 期望 {Score:%d Entropy:%v}", tt.password, got, tt.score, tt.entropy)
+			}
+		})
+	}
+}
+
+func TestEstimateScoreThresholds(t *testing.T) {
+	// 评分随熵单调递增，且与阈值一致
+	for _, pw := range []string{"123456", "alice2024", "zhangsan123", "alicealice", "Tr0ub4dor&3"} {
+		s := Estimate(pw, nil)
+		want := 0
+		for want < len(scoreThresholds) && s.Entropy >= scoreThresholds[want] {
+			want++
+		}
+		if s.Score != want {
+			t.Errorf("Estimate(%q) = %+v, 期望评分 %d", pw, s, want)
+		}
+	}
+}
+
+func TestCharsetSize(t *testing.T) {
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"abc", 26},
+		{"aB", 52},
+		{"a1", 36},
+		{"a!", 59},
+		{"aB1!", 95},
+		{"密码", 100},
+	}
+	for _, tt := range tests {
+		if got := charsetSize([]rune(tt.password)); got != tt.want {
+			t.Errorf("charsetSize(%q) = %d, 期望 %d", tt.password, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,502 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 条件表达式语法：
+//
+//	expr    := or
+//	or      := and ( "||" and )*
+//	and     := not ( "&&" not )*
+//	not     := "!" not | cmp
+//	cmp     := primary [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" | "in" | "contains" ) primary ]
+//	primary := "(" expr ")" | list | string | number | "true" | "false" | "null" | path
+//	list    := "[" [ primary ( "," primary )* ] "]"
+//	path    := ident ( "." ident )*
+//
+// 例如：`'ROLE_SUPPORT' in subject.roles && resource.createdBy == subject.username`
+
+// Expr 已编译的条件表达式
+type Expr interface {
+	Eval(attrs Attributes) (interface{}, error)
+}
+
+// Compile 编译条件表达式，空字符串表示恒为真
+func Compile(src string) (Expr, error) {
+	if strings.TrimSpace(src) == "" {
+		return literal{value: true}, nil
+	}
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("表达式在 %q 处存在多余内容", p.peek().text)
+	}
+	return expr, nil
+}
+
+// ---------- 词法分析 ----------
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokComma
+	tokDot
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		ch := runes[i]
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '(':
+			tokens = append(tokens, token{tokLParen, "("})
+			i++
+		case ch == ')':
+			tokens = append(tokens, token{tokRParen, ")"})
+			i++
+		case ch == '[':
+			tokens = append(tokens, token{tokLBracket, "["})
+			i++
+		case ch == ']':
+			tokens = append(tokens, token{tokRBracket, "]"})
+			i++
+		case ch == ',':
+			tokens = append(tokens, token{tokComma, ","})
+			i++
+		case ch == '.':
+			tokens = append(tokens, token{tokDot, "."})
+			i++
+		case ch == '\'' || ch == '"':
+			j := i + 1
+			var sb strings.Builder
+			for j < len(runes) && runes[j] != ch {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				sb.WriteRune(runes[j])
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("字符串未闭合")
+			}
+			tokens = append(tokens, token{tokString, sb.String()})
+			i = j + 1
+		case unicode.IsDigit(ch) || (ch == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokNumber, string(runes[i:j])})
+			i = j
+		case unicode.IsLetter(ch) || ch == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[i:j])})
+			i = j
+		default:
+			if i+1 < len(runes) {
+				two := string(runes[i : i+2])
+				switch two {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					tokens = append(tokens, token{tokOp, two})
+					i += 2
+					continue
+				}
+			}
+			switch ch {
+			case '<', '>', '!':
+				tokens = append(tokens, token{tokOp, string(ch)})
+				i++
+			default:
+				return nil, fmt.Errorf("无法识别的字符 %q", ch)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// ---------- 语法分析 ----------
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{kind: -1}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) isOp(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokOp && t.kind != tokIdent {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.isOp("||"); !ok {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{op: "||", left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.isOp("&&"); !ok {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{op: "&&", left: left, right: right}
+	}
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if _, ok := p.isOp("!"); ok {
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return not{inner: inner}, nil
+	}
+	return p.parseCmp()
+}
+
+func (p *parser) parseCmp() (Expr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.isOp("==", "!=", "<", "<=", ">", ">=", "in", "contains")
+	if !ok {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return compare{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokRParen {
+			return nil, fmt.Errorf("缺少右括号")
+		}
+		return expr, nil
+	case tokLBracket:
+		var items []Expr
+		if p.peek().kind == tokRBracket {
+			p.next()
+			return list{items: items}, nil
+		}
+		for {
+			item, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			sep := p.next()
+			if sep.kind == tokRBracket {
+				return list{items: items}, nil
+			}
+			if sep.kind != tokComma {
+				return nil, fmt.Errorf("列表元素之间缺少逗号")
+			}
+		}
+	case tokString:
+		return literal{value: t.text}, nil
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("数字格式不正确: %s", t.text)
+		}
+		return literal{value: f}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return literal{value: true}, nil
+		case "false":
+			return literal{value: false}, nil
+		case "null":
+			return literal{value: nil}, nil
+		}
+		segments := []string{t.text}
+		for p.peek().kind == tokDot {
+			p.next()
+			seg := p.next()
+			if seg.kind != tokIdent {
+				return nil, fmt.Errorf("属性路径 %s 不完整", strings.Join(segments, "."))
+			}
+			segments = append(segments, seg.text)
+		}
+		return path{segments: segments}, nil
+	case -1:
+		return nil, fmt.Errorf("表达式意外结束")
+	default:
+		return nil, fmt.Errorf("表达式在 %q 处无法解析", t.text)
+	}
+}
+
+// ---------- 求值 ----------
+
+type literal struct{ value interface{} }
+
+func (l literal) Eval(Attributes) (interface{}, error) { return l.value, nil }
+
+type list struct{ items []Expr }
+
+func (l list) Eval(attrs Attributes) (interface{}, error) {
+	values := make([]interface{}, 0, len(l.items))
+	for _, item := range l.items {
+		v, err := item.Eval(attrs)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+type path struct{ segments []string }
+
+func (p path) Eval(attrs Attributes) (interface{}, error) {
+	var cur interface{} = map[string]interface{}(attrs)
+	for _, seg := range p.segments {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		cur = m[seg]
+	}
+	return normalize(cur), nil
+}
+
+type not struct{ inner Expr }
+
+func (n not) Eval(attrs Attributes) (interface{}, error) {
+	v, err := n.inner.Eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+type logical struct {
+	op          string
+	left, right Expr
+}
+
+func (l logical) Eval(attrs Attributes) (interface{}, error) {
+	lv, err := l.left.Eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+	// 短路求值
+	if l.op == "&&" && !truthy(lv) {
+		return false, nil
+	}
+	if l.op == "||" && truthy(lv) {
+		return true, nil
+	}
+	rv, err := l.right.Eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(rv), nil
+}
+
+type compare struct {
+	op          string
+	left, right Expr
+}
+
+func (c compare) Eval(attrs Attributes) (interface{}, error) {
+	lv, err := c.left.Eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := c.right.Eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+	switch c.op {
+	case "==":
+		return equal(lv, rv), nil
+	case "!=":
+		return !equal(lv, rv), nil
+	case "in":
+		return contains(rv, lv), nil
+	case "contains":
+		return contains(lv, rv), nil
+	default:
+		lf, lok := lv.(float64)
+		rf, rok := rv.(float64)
+		if lok && rok {
+			return compareOrdered(c.op, lf < rf, lf == rf), nil
+		}
+		ls, lok := lv.(string)
+		rs, rok := rv.(string)
+		if lok && rok {
+			return compareOrdered(c.op, ls < rs, ls == rs), nil
+		}
+		return false, nil
+	}
+}
+
+func compareOrdered(op string, less, eq bool) bool {
+	switch op {
+	case "<":
+		return less
+	case "<=":
+		return less || eq
+	case ">":
+		return !less && !eq
+	case ">=":
+		return !less
+	}
+	return false
+}
+
+// contains 判断 container 是否包含 item：列表按元素比较，字符串按子串比较
+func contains(container, item interface{}) bool {
+	switch c := container.(type) {
+	case []interface{}:
+		for _, v := range c {
+			if equal(v, item) {
+				return true
+			}
+		}
+	case string:
+		if s, ok := item.(string); ok {
+			return strings.Contains(c, s)
+		}
+	}
+	return false
+}
+
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case string:
+		return t != ""
+	case float64:
+		return t != 0
+	case []interface{}:
+		return len(t) > 0
+	}
+	return true
+}
+
+// normalize 将属性值统一为 string / float64 / bool / []interface{} / map，便于比较
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil, string, bool, float64, map[string]interface{}:
+		return t
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = normalize(item)
+		}
+		return out
+	case []string:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = item
+		}
+		return out
+	case int:
+		return float64(t)
+	case int32:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case uint:
+		return float64(t)
+	case uint64:
+		return float64(t)
+	case float32:
+		return float64(t)
+	}
+	return fmt.Sprint(v)
+}
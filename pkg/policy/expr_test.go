@@ -0,0 +1,89 @@
+package policy
+
+import "testing"
+
+func TestCompileEval(t *testing.T) {
+	attrs := Attributes{
+		"subject": map[string]interface{}{
+			"username": "alice",
+			"roles":    []string{"ROLE_SUPPORT", "ROLE_USER"},
+			"level":    3,
+		},
+		"resource": map[string]interface{}{
+			"createdBy": "alice",
+			"tenantId":  int64(7),
+		},
+	}
+
+	tests := []struct {
+		name string
+		src  string
+		want interface{}
+	}{
+		{"空表达式恒为真", "   ", true},
+		{"&& 优先于 ||", "true || false && false", true},
+		{"&& 优先于 || 左侧", "false && true || true", true},
+		{"括号改变优先级", "(true || false) && false", false},
+		{"! 优先于 &&", "!false && false", false},
+		{"! 作用于整个比较", "!1 == 2", true},
+		{"双重取反", "!!true", true},
+		{"|| 左结合", "false || false || true", true},
+		{"比较运算", "subject.level >= 3 && subject.level < 4", true},
+		{"负数", "-1 < 0", true},
+		{"字符串比较", "'a' < 'b'", true},
+		{"类型不同的有序比较为假", "'3' > 2", false},
+		{"in 列表属性", "'ROLE_SUPPORT' in subject.roles", true},
+		{"in 列表字面量", "subject.username in ['bob', 'alice']", true},
+		{"空列表", "subject.username in []", false},
+		{"contains 子串", "subject.username contains 'lic'", true},
+		{"整数属性与数字相等", "resource.tenantId == 7", true},
+		{"属性间比较", "resource.createdBy == subject.username", true},
+		{"缺失属性为 null", "subject.missing == null", true},
+		{"穿过非对象的路径为 null", "subject.username.length == null", true},
+		{"转义引号", `'it\'s' == "it's"`, true},
+		{"示例规则", "'ROLE_SUPPORT' in subject.roles && resource.createdBy == subject.username", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile(%q) 返回错误: %v", tt.src, err)
+			}
+			got, err := expr.Eval(attrs)
+			if err != nil {
+				t.Fatalf("Eval(%q) 返回错误: %v", tt.src, err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%q) = %v, 期望 %v", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"字符串未闭合", "'abc"},
+		{"无法识别的字符", "a # b"},
+		{"单个 &", "a & b"},
+		{"缺少右括号", "(a == b"},
+		{"多余的右括号", "a == b)"},
+		{"表达式意外结束", "a &&"},
+		{"比较缺少右操作数", "a =="},
+		{"列表缺少逗号", "a in ['x' 'y']"},
+		{"列表未闭合", "a in ['x',"},
+		{"属性路径不完整", "subject."},
+		{"数字格式不正确", "1.2.3 == 1"},
+		{"连续比较", "1 < 2 < 3"},
+		{"以运算符开头", "&& a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile(tt.src); err == nil {
+				t.Errorf("Compile(%q) 期望返回错误", tt.src)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Package policy 提供基于属性的访问控制（ABAC）策略引擎。
+//
+// 策略由若干条规则组成，每条规则声明效果（allow/deny）、适用的操作以及一个条件表达式，
+// 条件表达式针对 subject（当前主体）、resource（目标资源）、context（请求上下文）三类属性求值。
+// 判定采用“拒绝优先”：任一匹配的 deny 规则即拒绝，否则存在匹配的 allow 规则才允许，默认拒绝。
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Attributes 参与求值的属性，顶层键为 subject、resource、context
+type Attributes map[string]interface{}
+
+// Rule 单条策略规则
+type Rule struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Effect      string   `json:"effect"`    // allow 或 deny
+	Actions     []string `json:"actions"`   // 适用的操作，支持 "*" 与 "user:*" 形式的前缀通配
+	Condition   string   `json:"condition"` // 条件表达式，为空表示恒为真
+
+	expr Expr
+}
+
+// Decision 策略判定结果
+type Decision struct {
+	Allowed bool   `json:"allowed"`
+	Effect  string `json:"effect"`
+	Rule    string `json:"rule"`   // 命中的规则名，未命中时为空
+	Reason  string `json:"reason"` // 判定说明
+}
+
+// Engine 策略引擎，创建后只读，可并发使用
+type Engine struct {
+	rules []Rule
+}
+
+// NewEngine 编译规则并创建策略引擎
+func NewEngine(rules []Rule) (*Engine, error) {
+	compiled := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("策略规则缺少名称")
+		}
+		if r.Effect != EffectAllow && r.Effect != EffectDeny {
+			return nil, fmt.Errorf("规则 %s 的 effect 必须为 allow 或 deny", r.Name)
+		}
+		if len(r.Actions) == 0 {
+			return nil, fmt.Errorf("规则 %s 未声明适用的操作", r.Name)
+		}
+		expr, err := Compile(r.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("规则 %s 条件表达式错误: %w", r.Name, err)
+		}
+		r.expr = expr
+		compiled = append(compiled, r)
+	}
+	return &Engine{rules: compiled}, nil
+}
+
+// LoadRules 从 JSON 文件加载规则列表
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取策略文件失败: %w", err)
+	}
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("解析策略文件失败: %w", err)
+	}
+	return rules, nil
+}
+
+// Rules 返回引擎中的全部规则
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// Evaluate 判定主体能否对资源执行指定操作
+func (e *Engine) Evaluate(action string, attrs Attributes) (Decision, error) {
+	var allowRule *Rule
+	for i := range e.rules {
+		r := &e.rules[i]
+		if !matchAction(r.Actions, action) {
+			continue
+		}
+		v, err := r.expr.Eval(attrs)
+		if err != nil {
+			return Decision{}, fmt.Errorf("规则 %s 求值失败: %w", r.Name, err)
+		}
+		if !truthy(v) {
+			continue
+		}
+		if r.Effect == EffectDeny {
+			return Decision{Allowed: false, Effect: EffectDeny, Rule: r.Name, Reason: "命中拒绝规则"}, nil
+		}
+		if allowRule == nil {
+			allowRule = r
+		}
+	}
+	if allowRule != nil {
+		return Decision{Allowed: true, Effect: EffectAllow, Rule: allowRule.Name, Reason: "命中允许规则"}, nil
+	}
+	return Decision{Allowed: false, Effect: EffectDeny, Reason: "未命中任何允许规则"}, nil
+}
+
+// matchAction 判断操作是否在规则声明的操作列表中
+func matchAction(actions []string, action string) bool {
+	for _, a := range actions {
+		if a == "*" || a == action {
+			return true
+		}
+		if strings.HasSuffix(a, ":*") && strings.HasPrefix(action, strings.TrimSuffix(a, "*")) {
+			return true
+		}
+	}
+	return false
+}
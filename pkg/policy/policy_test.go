@@ -0,0 +1,64 @@
+package policy
+
+import "testing"
+
+func TestEngineEvaluate(t *testing.T) {
+	engine, err := NewEngine([]Rule{
+		{Name: "admin-all", Effect: EffectAllow, Actions: []string{"*"}, Condition: "'ROLE_ADMIN' in subject.roles"},
+		{Name: "support-own", Effect: EffectAllow, Actions: []string{"user:*"}, Condition: "'ROLE_SUPPORT' in subject.roles && resource.createdBy == subject.username"},
+		{Name: "no-self-delete", Effect: EffectDeny, Actions: []string{"user:delete"}, Condition: "resource.username == subject.username"},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine 返回错误: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		action  string
+		roles   []string
+		target  string
+		creator string
+		allowed bool
+		rule    string
+	}{
+		{"管理员通配允许", "user:update", []string{"ROLE_ADMIN"}, "bob", "", true, "admin-all"},
+		{"拒绝规则优先于允许规则", "user:delete", []string{"ROLE_ADMIN"}, "alice", "", false, "no-self-delete"},
+		{"前缀通配匹配", "user:block", []string{"ROLE_SUPPORT"}, "bob", "alice", true, "support-own"},
+		{"前缀通配不匹配其他资源", "role:update", []string{"ROLE_SUPPORT"}, "bob", "alice", false, ""},
+		{"条件不满足", "user:block", []string{"ROLE_SUPPORT"}, "bob", "carol", false, ""},
+		{"未命中任何规则", "user:update", []string{"ROLE_USER"}, "bob", "alice", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := engine.Evaluate(tt.action, Attributes{
+				"subject":  map[string]interface{}{"username": "alice", "roles": tt.roles},
+				"resource": map[string]interface{}{"username": tt.target, "createdBy": tt.creator},
+			})
+			if err != nil {
+				t.Fatalf("Evaluate 返回错误: %v", err)
+			}
+			if d.Allowed != tt.allowed || d.Rule != tt.rule {
+				t.Errorf("Evaluate(%s) = {Allowed:%v Rule:%q}, 期望 {Allowed:%v Rule:%q}", tt.action, d.Allowed, d.Rule, tt.allowed, tt.rule)
+			}
+		})
+	}
+}
+
+func TestNewEngineRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"缺少名称", Rule{Effect: EffectAllow, Actions: []string{"*"}}},
+		{"effect 不合法", Rule{Name: "r", Effect: "maybe", Actions: []string{"*"}}},
+		{"缺少操作", Rule{Name: "r", Effect: EffectAllow}},
+		{"条件表达式错误", Rule{Name: "r", Effect: EffectAllow, Actions: []string{"*"}, Condition: "a &&"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEngine([]Rule{tt.rule}); err == nil {
+				t.Error("期望返回错误")
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval 内存限流器清理过期 key 的间隔
+const sweepInterval = time.Minute
+
+// MemoryLimiter 进程内滑动窗口限流器，适用于单机部署与测试
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	hits      map[string][]time.Time
+	windows   map[string]time.Duration
+	lastSweep time.Time
+}
+
+// NewMemoryLimiter 创建内存限流器
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		hits:    make(map[string][]time.Time),
+		windows: make(map[string]time.Duration),
+	}
+}
+
+// Allow 实现 Limiter
+func (l *MemoryLimiter) Allow(_ context.Context, key string, rate Rate) (Result, error) {
+	if !rate.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > sweepInterval {
+		l.sweep(now)
+	}
+
+	hits := trim(l.hits[key], now.Add(-rate.Window))
+	l.windows[key] = rate.Window
+	if len(hits) >= rate.Limit {
+		l.hits[key] = hits
+		return Result{
+			Allowed:    false,
+			Limit:      rate.Limit,
+			RetryAfter: hits[0].Add(rate.Window).Sub(now),
+		}, nil
+	}
+	l.hits[key] = append(hits, now)
+	return Result{Allowed: true, Limit: rate.Limit, Remaining: rate.Limit - len(hits) - 1}, nil
+}
+
+// sweep 删除窗口内已无请求记录的 key，避免内存无限增长
+func (l *MemoryLimiter) sweep(now time.Time) {
+	for key, hits := range l.hits {
+		if hits = trim(hits, now.Add(-l.windows[key])); len(hits) == 0 {
+			delete(l.hits, key)
+			delete(l.windows, key)
+		} else {
+			l.hits[key] = hits
+		}
+	}
+	l.lastSweep = now
+}
+
+// trim 去掉 since 之前的请求记录
+func trim(hits []time.Time, since time.Time) []time.Time {
+	i := 0
+	for i < len(hits) && !hits[i].After(since) {
+		i++
+	}
+	return hits[i:]
+}
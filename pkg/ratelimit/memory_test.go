@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiterAllow(t *testing.T) {
+	type step struct {
+		key       string
+		allowed   bool
+		remaining int
+	}
+	tests := []struct {
+		name  string
+		rate  Rate
+		steps []step
+	}{
+		{"未启用时总是放行", Rate{}, []step{{"a", true, 0}, {"a", true, 0}, {"a", true, 0}}},
+		{"超出次数后拒绝", Rate{Limit: 2, Window: time.Minute}, []step{{"a", true, 1}, {"a", true, 0}, {"a", false, 0}, {"a", false, 0}}},
+		{"不同 key 分别计数", Rate{Limit: 1, Window: time.Minute}, []step{{"a", true, 0}, {"b", true, 0}, {"a", false, 0}, {"b", false, 0}}},
+		{"次数为 0 时不限流", Rate{Limit: 0, Window: time.Minute}, []step{{"a", true, 0}, {"a", true, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewMemoryLimiter()
+			for i, s := range tt.steps {
+				res, err := l.Allow(context.Background(), s.key, tt.rate)
+				if err != nil {
+					t.Fatalf("第 %d 次 Allow 返回错误: %v", i+1, err)
+				}
+				if res.Allowed != s.allowed || res.Remaining != s.remaining {
+					t.Errorf("第 %d 次 Allow(%s) = {Allowed:%v Remaining:%d}, 期望 {Allowed:%v Remaining:%d}",
+						i+1, s.key, res.Allowed, res.Remaining, s.allowed, s.remaining)
+				}
+				if !res.Allowed && (res.RetryAfter <= 0 || res.RetryAfter > tt.rate.Window) {
+					t.Errorf("第 %d 次 RetryAfter = %v, 应在 (0, %v] 内", i+1, res.RetryAfter, tt.rate.Window)
+				}
+			}
+		})
+	}
+}
+
+// 窗口滑过最早的请求后恢复额度，被拒绝的请求不计入次数
+func TestMemoryLimiterSlidingWindow(t *testing.T) {
+	l := NewMemoryLimiter()
+	rate := Rate{Limit: 2, Window: 300 * time.Millisecond}
+	ctx := context.Background()
+
+	l.Allow(ctx, "k", rate)
+	time.Sleep(150 * time.Millisecond)
+	l.Allow(ctx, "k", rate)
+	if res, _ := l.Allow(ctx, "k", rate); res.Allowed {
+		t.Fatal("窗口内超出次数仍被放行")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if res, _ := l.Allow(ctx, "k", rate); !res.Allowed {
+		t.Fatal("最早的请求滑出窗口后仍被拒绝")
+	}
+	if res, _ := l.Allow(ctx, "k", rate); res.Allowed {
+		t.Fatal("恢复一次额度后应再次被拒绝")
+	}
+}
+
+func TestMemoryLimiterSweep(t *testing.T) {
+	l := NewMemoryLimiter()
+	ctx := context.Background()
+	l.Allow(ctx, "short", Rate{Limit: 1, Window: time.Millisecond})
+	l.Allow(ctx, "long", Rate{Limit: 1, Window: time.Hour})
+
+	l.sweep(time.Now().Add(time.Second))
+	if _, ok := l.hits["short"]; ok {
+		t.Error("过期的 key 未被清理")
+	}
+	if _, ok := l.hits["long"]; !ok {
+		t.Error("未过期的 key 被清理")
+	}
+}
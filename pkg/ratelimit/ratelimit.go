@@ -0,0 +1,60 @@
+// Package ratelimit 提供滑动窗口限流，支持 Redis（多实例共享）与内存（单机、测试）两种后端。
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate 限流速率：Window 内最多 Limit 次
+type Rate struct {
+	Limit  int
+	Window time.Duration
+}
+
+// Enabled 判断是否启用限流
+func (r Rate) Enabled() bool {
+	return r.Limit > 0 && r.Window > 0
+}
+
+// String 返回 "次数/时长" 形式
+func (r Rate) String() string {
+	return strconv.Itoa(r.Limit) + "/" + r.Window.String()
+}
+
+// ParseRate 解析 "次数/时长" 形式的速率，如 "10/1m"、"100/1h"；空字符串表示不限流
+func ParseRate(s string) (Rate, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Rate{}, nil
+	}
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return Rate{}, fmt.Errorf("限流速率格式应为 次数/时长: %s", s)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || limit < 0 {
+		return Rate{}, fmt.Errorf("限流次数不合法: %s", s)
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+	if err != nil || window <= 0 {
+		return Rate{}, fmt.Errorf("限流时长不合法: %s", s)
+	}
+	return Rate{Limit: limit, Window: window}, nil
+}
+
+// Result 单次限流判定结果
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int           // 窗口内剩余可用次数
+	RetryAfter time.Duration // 被拒绝时距离下次可用的时间
+}
+
+// Limiter 限流器：判定 key 在 rate 限制下是否还能再请求一次，允许时计入本次请求
+type Limiter interface {
+	Allow(ctx context.Context, key string, rate Rate) (Result, error)
+}
@@ -0,0 +1,40 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rate
+		wantErr bool
+	}{
+		{"", Rate{}, false},
+		{"  ", Rate{}, false},
+		{"10/1m", Rate{Limit: 10, Window: time.Minute}, false},
+		{" 100 / 1h ", Rate{Limit: 100, Window: time.Hour}, false},
+		{"5/30s", Rate{Limit: 5, Window: 30 * time.Second}, false},
+		{"0/1m", Rate{Limit: 0, Window: time.Minute}, false},
+		{"10", Rate{}, true},
+		{"x/1m", Rate{}, true},
+		{"-1/1m", Rate{}, true},
+		{"10/forever", Rate{}, true},
+		{"10/0s", Rate{}, true},
+		{"10/-1m", Rate{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRate(%q) 错误 = %v, 期望错误 %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRate(%q) = %+v, 期望 %+v", tt.in, got, tt.want)
+		}
+		if got.Enabled() != (tt.want.Limit > 0) {
+			t.Errorf("ParseRate(%q).Enabled() = %v", tt.in, got.Enabled())
+		}
+	}
+}
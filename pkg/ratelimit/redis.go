@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// slidingWindowScript 基于有序集合的滑动窗口：清理窗口外记录后计数，未超限则记录本次请求。
+// 返回 {是否允许, 剩余次数, 需等待的毫秒数}
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	return {1, limit - count - 1, 0}
+end
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+return {0, 0, window - (now - tonumber(oldest[2]))}
+`)
+
+// RedisLimiter 基于 Redis 的滑动窗口限流器，多实例部署时共享计数
+type RedisLimiter struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+// NewRedisLimiter 创建 Redis 限流器，所有 key 都会加上 prefix 前缀
+func NewRedisLimiter(rdb *redis.Client, prefix string) *RedisLimiter {
+	return &RedisLimiter{rdb: rdb, prefix: prefix}
+}
+
+// Allow 实现 Limiter
+func (l *RedisLimiter) Allow(ctx context.Context, key string, rate Rate) (Result, error) {
+	if !rate.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+	member := make([]byte, 8)
+	_, _ = rand.Read(member)
+	now := time.Now().UnixMilli()
+
+	res, err := slidingWindowScript.Run(ctx, l.rdb, []string{l.prefix + key},
+		now, rate.Window.Milliseconds(), rate.Limit, strconv.FormatInt(now, 10)+"-"+hex.EncodeToString(member)).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    res[0] == 1,
+		Limit:      rate.Limit,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,102 @@
+package siem
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Formatter 将事件编码为 syslog 消息体
+type Formatter interface {
+	Format(e *Event) (string, error)
+}
+
+// JSONFormatter 以单行 JSON 作为消息体
+type JSONFormatter struct{}
+
+// Format 实现 Formatter
+func (JSONFormatter) Format(e *Event) (string, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// CEFFormatter 以 ArcSight Common Event Format (CEF:0) 作为消息体
+type CEFFormatter struct {
+	Vendor  string
+	Product string
+	Version string
+}
+
+// cefHeaderEscaper CEF 头部字段需要转义 \ 与 |
+var cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+
+// cefValueEscaper CEF 扩展字段值需要转义 \ 与 =，换行使用 \n 表示
+var cefValueEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\n", `\n`, "\r", `\r`)
+
+// Format 实现 Formatter，格式为 CEF:0|Vendor|Product|Version|SignatureID|Name|Severity|Extension
+func (f CEFFormatter) Format(e *Event) (string, error) {
+	ext := [][2]string{
+		{"rt", strconv.FormatInt(e.Time.UnixMilli(), 10)},
+		{"externalId", strconv.FormatInt(e.ID, 10)},
+		{"act", e.Action},
+		{"outcome", e.Result},
+		{"reason", e.Reason},
+		{"suid", strconv.FormatInt(e.ActorID, 10)},
+		{"suser", e.ActorName},
+		{"duid", strconv.FormatInt(e.TargetID, 10)},
+		{"duser", e.TargetName},
+		{"src", e.IP},
+		{"requestClientApplication", e.UserAgent},
+	}
+	// 自定义字符串字段只在有值时连同标签一起输出
+	custom := [][2]string{
+		{"requestId", e.RequestID},
+		{"tenantId", strconv.FormatInt(e.TenantID, 10)},
+		{"impersonator", e.Impersonator},
+		{"changes", e.Changes},
+	}
+	for i, kv := range custom {
+		if kv[1] != "" {
+			n := strconv.Itoa(i + 1)
+			ext = append(ext, [2]string{"cs" + n + "Label", kv[0]}, [2]string{"cs" + n, kv[1]})
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|%s|%s|%s|%s|%s|%d|",
+		cefHeaderEscaper.Replace(f.Vendor),
+		cefHeaderEscaper.Replace(f.Product),
+		cefHeaderEscaper.Replace(f.Version),
+		cefHeaderEscaper.Replace(e.Action),
+		cefHeaderEscaper.Replace(e.Action+" "+e.Result),
+		clampSeverity(e.Severity))
+	first := true
+	for _, kv := range ext {
+		if kv[1] == "" {
+			continue
+		}
+		if !first {
+			b.WriteByte(' ')
+		}
+		first = false
+		b.WriteString(kv[0])
+		b.WriteByte('=')
+		b.WriteString(cefValueEscaper.Replace(kv[1]))
+	}
+	return b.String(), nil
+}
+
+// clampSeverity 将严重级别限制在 CEF 允许的 0-10
+func clampSeverity(s int) int {
+	if s < 0 {
+		return 0
+	}
+	if s > 10 {
+		return 10
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+package siem
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func testEvent() Event {
+	return Event{
+		ID:         42,
+		Time:       time.UnixMilli(1700000000123),
+		Action:     "user.delete",
+		Result:     "success",
+		Severity:   8,
+		ActorID:    1,
+		ActorName:  "admin",
+		TargetID:   7,
+		TargetName: "bob",
+		IP:         "10.0.0.1",
+		RequestID:  "req-1",
+	}
+}
+
+func TestCEFFormatter(t *testing.T) {
+	f := CEFFormatter{Vendor: "Acme|Corp", Product: `sys\tem`, Version: "1.0\n"}
+
+	escaped := testEvent()
+	escaped.Reason = "a=b\\c\nd\r\ne"
+	escaped.TargetName = "bob|x"
+	escaped.Action = "user|delete"
+	escaped.Severity = 12
+
+	custom := testEvent()
+	custom.RequestID = ""
+	custom.TenantID = 3
+	custom.Impersonator = "root"
+	custom.Changes = `{"status":1}`
+	custom.Severity = -1
+
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{
+			name:  "基本字段",
+			event: testEvent(),
+			want: `CEF:0|Acme\|Corp|sys\\tem|1.0 |user.delete|user.delete success|8|` +
+				`rt=1700000000123 externalId=42 act=user.delete outcome=success suid=1 suser=admin duid=7 duser=bob src=10.0.0.1 ` +
+				`cs1Label=requestId cs1=req-1 cs2Label=tenantId cs2=0`,
+		},
+		{
+			name:  "头部与扩展字段转义",
+			event: escaped,
+			want: `CEF:0|Acme\|Corp|sys\\tem|1.0 |user\|delete|user\|delete success|10|` +
+				`rt=1700000000123 externalId=42 act=user|delete outcome=success reason=a\=b\\c\nd\ne suid=1 suser=admin duid=7 duser=bob|x src=10.0.0.1 ` +
+				`cs1Label=requestId cs1=req-1 cs2Label=tenantId cs2=0`,
+		},
+		{
+			name:  "自定义字段按编号输出",
+			event: custom,
+			want: `CEF:0|Acme\|Corp|sys\\tem|1.0 |user.delete|user.delete success|0|` +
+				`rt=1700000000123 externalId=42 act=user.delete outcome=success suid=1 suser=admin duid=7 duser=bob src=10.0.0.1 ` +
+				`cs2Label=tenantId cs2=3 cs3Label=impersonator cs3=root cs4Label=changes cs4={"status":1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.Format(&tt.event)
+			if err != nil {
+				t.Fatalf("Format 返回错误: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Format =\n%s\n期望\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONFormatter(t *testing.T) {
+	e := testEvent()
+	got, err := JSONFormatter{}.Format(&e)
+	if err != nil {
+		t.Fatalf("Format 返回错误: %v", err)
+	}
+	var decoded Event
+	if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+		t.Fatalf("输出不是合法 JSON: %v", err)
+	}
+	if !decoded.Time.Equal(e.Time) {
+		t.Errorf("time = %v, 期望 %v", decoded.Time, e.Time)
+	}
+	decoded.Time = e.Time
+	if decoded != e {
+		t.Errorf("解码结果 = %+v, 期望 %+v", decoded, e)
+	}
+}
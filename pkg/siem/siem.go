@@ -0,0 +1,155 @@
+// Package siem 将安全审计事件转发到外部 SIEM 系统。
+// 支持 RFC 5424 syslog（UDP/TCP/TLS）、ArcSight CEF 格式与 JSON Webhook，
+// 所有发送都在后台异步完成，缓冲区满时丢弃事件而不阻塞业务请求。
+package siem
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event 转发给 SIEM 的审计事件
+type Event struct {
+	ID           int64     `json:"id"`
+	Time         time.Time `json:"time"`
+	TenantID     int64     `json:"tenantId"`
+	Action       string    `json:"action"`
+	Result       string    `json:"result"`
+	Reason       string    `json:"reason,omitempty"`
+	Severity     int       `json:"severity"` // 0-10，与 CEF 严重级别一致
+	ActorID      int64     `json:"actorId"`
+	ActorName    string    `json:"actorName"`
+	Impersonator string    `json:"impersonator,omitempty"`
+	TargetID     int64     `json:"targetId"`
+	TargetName   string    `json:"targetName"`
+	IP           string    `json:"ip"`
+	UserAgent    string    `json:"userAgent"`
+	RequestID    string    `json:"requestId"`
+	Changes      string    `json:"changes,omitempty"`
+}
+
+// Sink 事件发送目标
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, e *Event) error
+	Close() error
+}
+
+const (
+	sendTimeout  = 5 * time.Second
+	sendAttempts = 3
+)
+
+// Pipeline 异步转发管道：每个 Sink 拥有独立的缓冲队列与后台协程，慢的 Sink 不会拖累其他 Sink
+type Pipeline struct {
+	mu      sync.RWMutex
+	closed  bool
+	workers []*worker
+}
+
+type worker struct {
+	sink    Sink
+	queue   chan Event
+	dropped uint64
+	done    chan struct{}
+}
+
+// NewPipeline 创建并启动转发管道，bufferSize 为每个 Sink 的缓冲事件数
+func NewPipeline(bufferSize int, sinks ...Sink) *Pipeline {
+	if bufferSize <= 0 {
+		bufferSize = 1000
+	}
+	p := &Pipeline{}
+	for _, s := range sinks {
+		w := &worker{sink: s, queue: make(chan Event, bufferSize), done: make(chan struct{})}
+		p.workers = append(p.workers, w)
+		go w.run()
+	}
+	return p
+}
+
+// Publish 投递事件，从不阻塞：队列已满或管道已关闭时丢弃并计数
+func (p *Pipeline) Publish(e Event) {
+	if p == nil {
+		return
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return
+	}
+	for _, w := range p.workers {
+		select {
+		case w.queue <- e:
+		default:
+			atomic.AddUint64(&w.dropped, 1)
+		}
+	}
+}
+
+// Dropped 返回各 Sink 因队列已满而丢弃的事件数
+func (p *Pipeline) Dropped() map[string]uint64 {
+	if p == nil {
+		return nil
+	}
+	stats := make(map[string]uint64, len(p.workers))
+	for _, w := range p.workers {
+		stats[w.sink.Name()] += atomic.LoadUint64(&w.dropped)
+	}
+	return stats
+}
+
+// Close 停止接收新事件，并在 ctx 到期前尽量发送完队列中的事件
+func (p *Pipeline) Close(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	for _, w := range p.workers {
+		close(w.queue)
+	}
+	p.mu.Unlock()
+
+	for _, w := range p.workers {
+		select {
+		case <-w.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if err := w.sink.Close(); err != nil {
+			log.Printf("关闭 SIEM 转发目标 %s 失败: %v", w.sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+// run 逐个发送队列中的事件，失败时按递增间隔重试
+func (w *worker) run() {
+	defer close(w.done)
+	for e := range w.queue {
+		e := e
+		var err error
+		for attempt := 1; attempt <= sendAttempts; attempt++ {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			err = w.sink.Send(ctx, &e)
+			cancel()
+			if err == nil {
+				break
+			}
+			if attempt < sendAttempts {
+				time.Sleep(time.Duration(attempt) * 200 * time.Millisecond)
+			}
+		}
+		if err != nil {
+			log.Printf("转发审计事件 %d 到 %s 失败: %v", e.ID, w.sink.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package siem
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordSink 记录收到的事件；release 不为空时每次发送前等待放行
+type recordSink struct {
+	mu       sync.Mutex
+	events   []int64
+	started  chan struct{}
+	release  chan struct{}
+	delay    time.Duration
+	closed   bool
+	sendOnce sync.Once
+}
+
+func (s *recordSink) Name() string { return "record" }
+
+func (s *recordSink) Send(_ context.Context, e *Event) error {
+	if s.started != nil {
+		s.sendOnce.Do(func() { close(s.started) })
+	}
+	if s.release != nil {
+		<-s.release
+	}
+	time.Sleep(s.delay)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e.ID)
+	return nil
+}
+
+func (s *recordSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *recordSink) received() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.events...)
+}
+
+func TestPipelinePublishDropsWhenFull(t *testing.T) {
+	sink := &recordSink{started: make(chan struct{}), release: make(chan struct{})}
+	p := NewPipeline(2, sink)
+
+	// 第一个事件被后台协程取出后阻塞在 Send 中，之后的事件只能进入缓冲队列
+	p.Publish(Event{ID: 1})
+	<-sink.started
+
+	done := make(chan struct{})
+	go func() {
+		for id := int64(2); id <= 6; id++ {
+			p.Publish(Event{ID: id})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("队列已满时 Publish 被阻塞")
+	}
+	if got := p.Dropped()["record"]; got != 3 {
+		t.Errorf("丢弃事件数 = %d, 期望 3", got)
+	}
+
+	close(sink.release)
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatalf("Close 返回错误: %v", err)
+	}
+	if got := sink.received(); len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("发送的事件 = %v, 期望 [1 2 3]", got)
+	}
+}
+
+func TestPipelineCloseDrainsQueue(t *testing.T) {
+	sink := &recordSink{delay: 5 * time.Millisecond}
+	p := NewPipeline(100, sink)
+	for id := int64(1); id <= 20; id++ {
+		p.Publish(Event{ID: id})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Close(ctx); err != nil {
+		t.Fatalf("Close 返回错误: %v", err)
+	}
+	got := sink.received()
+	if len(got) != 20 {
+		t.Fatalf("Close 返回时已发送 %d 个事件, 期望 20", len(got))
+	}
+	for i, id := range got {
+		if id != int64(i+1) {
+			t.Fatalf("发送顺序 = %v", got)
+		}
+	}
+	if !sink.closed {
+		t.Error("Close 未关闭 Sink")
+	}
+
+	// 关闭后的事件直接丢弃，重复关闭不报错
+	p.Publish(Event{ID: 21})
+	if err := p.Close(ctx); err != nil {
+		t.Errorf("重复 Close 返回错误: %v", err)
+	}
+	if got := sink.received(); len(got) != 20 {
+		t.Errorf("关闭后仍发送了事件: %v", got)
+	}
+}
+
+func TestPipelineCloseHonorsContext(t *testing.T) {
+	sink := &recordSink{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(sink.release)
+	p := NewPipeline(10, sink)
+	p.Publish(Event{ID: 1})
+	<-sink.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.Close(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Close 错误 = %v, 期望 context.DeadlineExceeded", err)
+	}
+}
+
+func TestNilPipeline(t *testing.T) {
+	var p *Pipeline
+	p.Publish(Event{ID: 1})
+	if p.Dropped() != nil {
+		t.Error("nil 管道的 Dropped 应返回 nil")
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Errorf("nil 管道 Close 返回错误: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package siem
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FacilityLogAudit RFC 5424 中的 log audit 设施
+const FacilityLogAudit = 13
+
+// 支持的 syslog 传输方式
+const (
+	NetworkUDP = "udp"
+	NetworkTCP = "tcp"
+	NetworkTLS = "tls"
+)
+
+// SyslogConfig syslog 发送配置
+type SyslogConfig struct {
+	Network   string      // udp、tcp 或 tls
+	Addr      string      // host:port
+	TLSConfig *tls.Config // Network 为 tls 时使用，为空时使用系统根证书
+	Facility  int         // 默认 FacilityLogAudit
+	AppName   string
+	Hostname  string // 为空时使用本机主机名
+	Formatter Formatter
+}
+
+// SyslogSink 按 RFC 5424 发送 syslog 消息；TCP 与 TLS 使用 RFC 6587 octet-counting 分帧
+type SyslogSink struct {
+	cfg  SyslogConfig
+	pid  string
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink 创建 syslog 发送目标，连接在首次发送时建立，断开后自动重连
+func NewSyslogSink(cfg SyslogConfig) (*SyslogSink, error) {
+	switch cfg.Network {
+	case NetworkUDP, NetworkTCP, NetworkTLS:
+	default:
+		return nil, fmt.Errorf("不支持的 syslog 传输方式: %s", cfg.Network)
+	}
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("syslog 地址不能为空")
+	}
+	if cfg.Facility == 0 {
+		cfg.Facility = FacilityLogAudit
+	}
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if cfg.Formatter == nil {
+		cfg.Formatter = JSONFormatter{}
+	}
+	return &SyslogSink{cfg: cfg, pid: strconv.Itoa(os.Getpid())}, nil
+}
+
+// Name 实现 Sink
+func (s *SyslogSink) Name() string {
+	return "syslog+" + s.cfg.Network + "://" + s.cfg.Addr
+}
+
+// Send 实现 Sink
+func (s *SyslogSink) Send(ctx context.Context, e *Event) error {
+	body, err := s.cfg.Formatter.Format(e)
+	if err != nil {
+		return err
+	}
+	msg := s.message(e, body)
+	if s.cfg.Network != NetworkUDP {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		if s.conn, err = s.dial(ctx); err != nil {
+			return err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(deadline)
+	}
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		// 连接失效，丢弃后由下一次重试重新建立
+		_ = s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Close 实现 Sink
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// message 组装 RFC 5424 消息：<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (s *SyslogSink) message(e *Event, body string) string {
+	pri := s.cfg.Facility*8 + syslogSeverity(e.Severity)
+	return fmt.Sprintf("<%d>1 %s %s %s %s %s - %s",
+		pri,
+		e.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		headerField(s.cfg.Hostname, 255),
+		headerField(s.cfg.AppName, 48),
+		headerField(s.pid, 128),
+		headerField(e.Action, 32),
+		body)
+}
+
+// dial 建立连接
+func (s *SyslogSink) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: sendTimeout}
+	if s.cfg.Network == NetworkTLS {
+		tlsConfig := s.cfg.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName, _, _ = net.SplitHostPort(s.cfg.Addr)
+		}
+		td := &tls.Dialer{NetDialer: dialer, Config: tlsConfig}
+		return td.DialContext(ctx, "tcp", s.cfg.Addr)
+	}
+	return dialer.DialContext(ctx, s.cfg.Network, s.cfg.Addr)
+}
+
+// syslogSeverity 将 0-10 的事件严重级别映射为 syslog 严重级别
+func syslogSeverity(s int) int {
+	switch {
+	case s >= 9:
+		return 2 // critical
+	case s >= 7:
+		return 4 // warning
+	case s >= 4:
+		return 5 // notice
+	default:
+		return 6 // informational
+	}
+}
+
+// headerField 头部字段只允许可打印 ASCII 且不能包含空格，为空时使用 NILVALUE
+func headerField(v string, maxLen int) string {
+	v = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, v)
+	if v == "" {
+		return "-"
+	}
+	if len(v) > maxLen {
+		v = v[:maxLen]
+	}
+	return v
+}
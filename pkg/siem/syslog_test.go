@@ -0,0 +1,172 @@
+package siem
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bodyFormatter 直接以固定内容作为消息体，便于断言头部
+type bodyFormatter string
+
+func (f bodyFormatter) Format(*Event) (string, error) { return string(f), nil }
+
+func newTestSyslogSink(t *testing.T, network, addr string) *SyslogSink {
+	t.Helper()
+	s, err := NewSyslogSink(SyslogConfig{
+		Network:   network,
+		Addr:      addr,
+		AppName:   "system",
+		Hostname:  "host-1",
+		Formatter: bodyFormatter("审计 body"),
+	})
+	if err != nil {
+		t.Fatalf("NewSyslogSink 返回错误: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// wantMessage 返回 testEvent 对应的 RFC 5424 消息，facility 13、严重级别 8 映射为 warning(4)
+func wantMessage(msgID string) string {
+	return "<108>1 2023-11-14T22:13:20.123000Z host-1 system " + strconv.Itoa(os.Getpid()) + " " + msgID + " - 审计 body"
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	s := newTestSyslogSink(t, NetworkUDP, pc.LocalAddr().String())
+	e := testEvent()
+	if err := s.Send(context.Background(), &e); err != nil {
+		t.Fatalf("Send 返回错误: %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// UDP 每个数据报一条消息，不加长度前缀
+	if got, want := string(buf[:n]), wantMessage("user.delete"); got != want {
+		t.Errorf("消息 =\n%s\n期望\n%s", got, want)
+	}
+}
+
+func TestSyslogSinkTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+		var frames []string
+		for len(frames) < 2 {
+			prefix, err := r.ReadString(' ')
+			if err != nil {
+				break
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+			if err != nil {
+				break
+			}
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				break
+			}
+			frames = append(frames, string(msg))
+		}
+		received <- frames
+	}()
+
+	s := newTestSyslogSink(t, NetworkTCP, ln.Addr().String())
+	first, second := testEvent(), testEvent()
+	second.Action = "auth login"
+	for _, e := range []*Event{&first, &second} {
+		if err := s.Send(context.Background(), e); err != nil {
+			t.Fatalf("Send 返回错误: %v", err)
+		}
+	}
+
+	frames := <-received
+	// 长度按字节计算，消息体中的中文不能破坏分帧；头部字段中的空格替换为 _
+	want := []string{wantMessage("user.delete"), wantMessage("auth_login")}
+	if len(frames) != len(want) {
+		t.Fatalf("收到 %d 条消息 %q, 期望 %d 条", len(frames), frames, len(want))
+	}
+	for i := range want {
+		if frames[i] != want[i] {
+			t.Errorf("第 %d 条消息 =\n%s\n期望\n%s", i+1, frames[i], want[i])
+		}
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	tests := []struct {
+		severity int
+		want     int
+	}{
+		{0, 6}, {3, 6}, {4, 5}, {6, 5}, {7, 4}, {8, 4}, {9, 2}, {10, 2},
+	}
+	for _, tt := range tests {
+		if got := syslogSeverity(tt.severity); got != tt.want {
+			t.Errorf("syslogSeverity(%d) = %d, 期望 %d", tt.severity, got, tt.want)
+		}
+	}
+}
+
+func TestHeaderField(t *testing.T) {
+	tests := []struct {
+		value  string
+		maxLen int
+		want   string
+	}{
+		{"user.delete", 32, "user.delete"},
+		{"", 32, "-"},
+		{"a b\tc", 32, "a_b_c"},
+		{"用户", 32, "__"},
+		{"abcdef", 4, "abcd"},
+	}
+	for _, tt := range tests {
+		if got := headerField(tt.value, tt.maxLen); got != tt.want {
+			t.Errorf("headerField(%q, %d) = %q, 期望 %q", tt.value, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
+func TestNewSyslogSinkErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  SyslogConfig
+	}{
+		{"不支持的传输方式", SyslogConfig{Network: "http", Addr: "127.0.0.1:514"}},
+		{"缺少地址", SyslogConfig{Network: NetworkUDP}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSyslogSink(tt.cfg); err == nil {
+				t.Error("期望返回错误")
+			}
+		})
+	}
+}
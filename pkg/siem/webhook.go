@@ -0,0 +1,72 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// SignatureHeader Webhook 请求签名所在的 Header，值为 sha256=<HMAC-SHA256(secret, body) 的十六进制>
+const SignatureHeader = "X-Signature-256"
+
+// WebhookSink 以 JSON 形式将事件 POST 到指定 URL
+type WebhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookSink 创建 Webhook 发送目标，secret 不为空时为每个请求附加 HMAC 签名
+func NewWebhookSink(url, secret string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: sendTimeout},
+	}
+}
+
+// Name 实现 Sink
+func (s *WebhookSink) Name() string {
+	return "webhook:" + s.url
+}
+
+// Send 实现 Sink，非 2xx 响应视为失败
+func (s *WebhookSink) Send(ctx context.Context, e *Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.secret) > 0 {
+		mac := hmac.New(sha256.New, s.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close 实现 Sink
+func (s *WebhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
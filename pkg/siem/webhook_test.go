@@ -0,0 +1,70 @@
+package siem
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookSink(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		status  int
+		wantErr bool
+	}{
+		{"带签名", "s3cret", http.StatusOK, false},
+		{"未配置密钥时不签名", "", http.StatusNoContent, false},
+		{"非 2xx 视为失败", "s3cret", http.StatusInternalServerError, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				body      []byte
+				signature string
+				header    http.Header
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				header = r.Header.Clone()
+				signature = r.Header.Get(SignatureHeader)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			s := NewWebhookSink(srv.URL, tt.secret)
+			defer s.Close()
+			e := testEvent()
+			err := s.Send(context.Background(), &e)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send 错误 = %v, 期望错误 %v", err, tt.wantErr)
+			}
+
+			if ct := header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %s", ct)
+			}
+			var decoded Event
+			if err := json.Unmarshal(body, &decoded); err != nil || decoded.ID != e.ID || decoded.Action != e.Action {
+				t.Errorf("请求体 = %s, 解码错误 %v", body, err)
+			}
+
+			if tt.secret == "" {
+				if signature != "" {
+					t.Errorf("未配置密钥时签名 = %s, 期望为空", signature)
+				}
+				return
+			}
+			mac := hmac.New(sha256.New, []byte(tt.secret))
+			mac.Write(body)
+			if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+				t.Errorf("签名 = %s, 期望 %s", signature, want)
+			}
+		})
+	}
+}
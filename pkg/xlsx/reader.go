@@ -0,0 +1,263 @@
+// Package xlsx 读写 Office Open XML 电子表格（.xlsx）中的单元格文本，只依赖标准库。
+//
+// Writer 逐行流式写出只有一个工作表的文件，单元格均为文本。
+// 读取时只读取工作簿中的第一个工作表，单元格按文本返回：共享字符串、内联字符串和公式的缓存结果
+// 原样返回，数字返回文件中保存的原始值（日期为 Excel 序列号，可用 DateFromSerial 转换），
+// 布尔值返回 TRUE/FALSE。不计算公式，不处理合并单元格。
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrFormat 文件不是有效的 xlsx
+var ErrFormat = errors.New("不是有效的 xlsx 文件")
+
+// maxPartSize 单个 XML 部件解压后的最大字节数，防止压缩炸弹
+const maxPartSize = 256 << 20
+
+// IsXLSX 根据文件头判断是否为 zip 格式（xlsx 即 zip 压缩包）
+func IsXLSX(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+// ReadRows 读取第一个工作表的全部行，行尾的空单元格被去除，中间缺失的行以空行补齐
+func ReadRows(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrFormat
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheet, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+	return readSheet(sheet, shared)
+}
+
+// DateFromSerial 将 Excel 日期序列号（1900 日期系统）转换为日期
+func DateFromSerial(serial float64) time.Time {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	days := int(serial)
+	return base.AddDate(0, 0, days)
+}
+
+// firstSheet 按 workbook.xml 中的顺序找到第一个工作表文件
+func firstSheet(files map[string]*zip.File) (*zip.File, error) {
+	var workbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodePart(files["xl/workbook.xml"], &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("%w: 没有工作表", ErrFormat)
+	}
+
+	var rels struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(files["xl/_rels/workbook.xml.rels"], &rels); err != nil {
+		return nil, err
+	}
+	for _, r := range rels.Items {
+		if r.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		name := r.Target
+		if strings.HasPrefix(name, "/") {
+			name = strings.TrimPrefix(name, "/")
+		} else {
+			name = path.Join("xl", name)
+		}
+		if f, ok := files[name]; ok {
+			return f, nil
+		}
+		break
+	}
+	return nil, fmt.Errorf("%w: 找不到第一个工作表", ErrFormat)
+}
+
+// readSharedStrings 读取共享字符串表，富文本按顺序拼接各段文字
+func readSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []richText `xml:"si"`
+	}
+	if err := decodePart(f, &sst); err != nil {
+		return nil, err
+	}
+	out := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		out[i] = si.String()
+	}
+	return out, nil
+}
+
+type richText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (r richText) String() string {
+	if len(r.Runs) == 0 {
+		return r.T
+	}
+	var b strings.Builder
+	b.WriteString(r.T)
+	for _, run := range r.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+// readSheet 逐个元素解码工作表，避免一次性构造整张表的 DOM
+func readSheet(f *zip.File, shared []string) ([][]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, ErrFormat
+	}
+	defer rc.Close()
+	dec := xml.NewDecoder(io.LimitReader(rc, maxPartSize))
+
+	var rows [][]string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline richText `xml:"is"`
+			} `xml:"c"`
+		}
+		if err := dec.DecodeElement(&row, &start); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+		}
+
+		index := len(rows)
+		if row.R > 0 {
+			index = row.R - 1
+		}
+		if index < len(rows) {
+			return nil, fmt.Errorf("%w: 行号重复或乱序", ErrFormat)
+		}
+		for len(rows) < index {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				if col, err = columnIndex(c.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if col < len(cells) {
+				return nil, fmt.Errorf("%w: 单元格 %s 重复或乱序", ErrFormat, c.Ref)
+			}
+			for len(cells) < col {
+				cells = append(cells, "")
+			}
+			v, err := cellText(c.Type, c.Value, c.Inline, shared)
+			if err != nil {
+				return nil, err
+			}
+			cells = append(cells, v)
+		}
+		for len(cells) > 0 && cells[len(cells)-1] == "" {
+			cells = cells[:len(cells)-1]
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+func cellText(typ, value string, inline richText, shared []string) (string, error) {
+	switch typ {
+	case "s":
+		i, err := strconv.Atoi(value)
+		if err != nil || i < 0 || i >= len(shared) {
+			return "", fmt.Errorf("%w: 共享字符串索引错误", ErrFormat)
+		}
+		return shared[i], nil
+	case "inlineStr":
+		return inline.String(), nil
+	case "b":
+		if value == "1" {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	default: // n、str、e 及省略类型
+		return value, nil
+	}
+}
+
+// columnIndex 将单元格引用（如 AB12）的列字母转换为从 0 开始的列号
+func columnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 || n > 3 {
+		return 0, fmt.Errorf("%w: 单元格引用错误 %q", ErrFormat, ref)
+	}
+	return col - 1, nil
+}
+
+func decodePart(f *zip.File, v interface{}) error {
+	if f == nil {
+		return ErrFormat
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return ErrFormat
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	testWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="用户" sheetId="2" r:id="rId7"/><sheet name="说明" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	testRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId7" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/users.xml"/>
+</Relationships>`
+	testSharedStrings = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="3" uniqueCount="3">
+<si><t>username</t></si><si><t>email</t></si><si><r><t>张</t></r><r><rPr><b/></rPr><t>三</t></r></si></sst>`
+)
+
+// buildXLSX 将给定部件（文件名 -> 内容）打包为 xlsx
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range parts {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// sheetParts 返回一个完整工作簿的部件，sheetData 为第一个工作表 xl/worksheets/users.xml 的行内容
+func sheetParts(sheetData string) map[string]string {
+	return map[string]string{
+		"xl/workbook.xml":            testWorkbook,
+		"xl/_rels/workbook.xml.rels": testRels,
+		"xl/sharedStrings.xml":       testSharedStrings,
+		"xl/worksheets/sheet1.xml":   `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>不是第一个工作表</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/users.xml":    `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+}
+
+func TestReadRows(t *testing.T) {
+	tests := []struct {
+		name      string
+		sheetData string
+		want      [][]string
+	}{
+		{
+			name:      "共享字符串与富文本",
+			sheetData: `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row><row r="2"><c r="A2" t="s"><v>2</v></c></row>`,
+			want:      [][]string{{"username", "email"}, {"张三"}},
+		},
+		{
+			name: "各类单元格",
+			sheetData: `<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve"> 内联 </t></is></c>` +
+				`<c r="B1"><v>42.5</v></c><c r="C1" t="n"><v>45292</v></c><c r="D1" t="b"><v>1</v></c><c r="E1" t="b"><v>0</v></c>` +
+				`<c r="F1" t="str"><f>A1&amp;"x"</f><v>公式结果</v></c><c r="G1" t="e"><v>#N/A</v></c></row>`,
+			want: [][]string{{" 内联 ", "42.5", "45292", "TRUE", "FALSE", "公式结果", "#N/A"}},
+		},
+		{
+			name:      "缺失的行与单元格",
+			sheetData: `<row r="2"><c r="B2" t="inlineStr"><is><t>b</t></is></c><c r="D2" t="inlineStr"><is><t>d</t></is></c></row><row r="4"><c r="A4"><v>1</v></c></row>`,
+			want:      [][]string{nil, {"", "b", "", "d"}, nil, {"1"}},
+		},
+		{
+			name:      "去除行尾空单元格",
+			sheetData: `<row r="1"><c r="A1"><v>1</v></c><c r="B1" t="inlineStr"><is><t></t></is></c><c r="C1"/></row>`,
+			want:      [][]string{{"1"}},
+		},
+		{
+			name:      "省略行号与单元格引用",
+			sheetData: `<row><c><v>1</v></c><c><v>2</v></c></row><row><c><v>3</v></c></row>`,
+			want:      [][]string{{"1", "2"}, {"3"}},
+		},
+		{
+			name:      "多字母列",
+			sheetData: `<row r="1"><c r="AA1"><v>x</v></c></row>`,
+			want:      [][]string{append(make([]string, 26), "x")},
+		},
+		{
+			name:      "空工作表",
+			sheetData: ``,
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadRows(buildXLSX(t, sheetParts(tt.sheetData)))
+			if err != nil {
+				t.Fatalf("ReadRows 返回错误: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadRows = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRowsErrors(t *testing.T) {
+	withoutPart := func(name string) map[string]string {
+		parts := sheetParts(`<row r="1"><c r="A1"><v>1</v></c></row>`)
+		delete(parts, name)
+		return parts
+	}
+	noSheets := sheetParts("")
+	noSheets["xl/workbook.xml"] = `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheets/></workbook>`
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"不是 zip", []byte("username,email\nalice,a@example.com\n")},
+		{"缺少 workbook.xml", buildXLSX(t, withoutPart("xl/workbook.xml"))},
+		{"缺少关系文件", buildXLSX(t, withoutPart("xl/_rels/workbook.xml.rels"))},
+		{"缺少工作表文件", buildXLSX(t, withoutPart("xl/worksheets/users.xml"))},
+		{"没有工作表", buildXLSX(t, noSheets)},
+		{"行号重复", buildXLSX(t, sheetParts(`<row r="2"/><row r="2"/>`))},
+		{"行号乱序", buildXLSX(t, sheetParts(`<row r="3"/><row r="1"/>`))},
+		{"单元格乱序", buildXLSX(t, sheetParts(`<row r="1"><c r="B1"><v>1</v></c><c r="A1"><v>2</v></c></row>`))},
+		{"单元格引用错误", buildXLSX(t, sheetParts(`<row r="1"><c r="1A"><v>1</v></c></row>`))},
+		{"共享字符串越界", buildXLSX(t, sheetParts(`<row r="1"><c r="A1" t="s"><v>3</v></c></row>`))},
+		{"XML 格式错误", buildXLSX(t, sheetParts(`<row r="1"><c r="A1"><v>1</c></row>`))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadRows(tt.data); !errors.Is(err, ErrFormat) {
+				t.Errorf("ReadRows 错误 = %v, 期望 ErrFormat", err)
+			}
+		})
+	}
+}
+
+func TestIsXLSX(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want bool
+	}{
+		{buildXLSX(t, sheetParts("")), true},
+		{[]byte("username,email\n"), false},
+		{[]byte("PK"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsXLSX(tt.data); got != tt.want {
+			t.Errorf("IsXLSX(%q...) = %v, 期望 %v", tt.data[:min(len(tt.data), 8)], got, tt.want)
+		}
+	}
+}
+
+func TestDateFromSerial(t *testing.T) {
+	tests := []struct {
+		serial float64
+		want   string
+	}{
+		{1, "1899-12-31"},
+		{61, "1900-03-01"},
+		{45292, "2024-01-01"},
+		{45292.75, "2024-01-01"},
+		{45351, "2024-02-29"},
+	}
+	for _, tt := range tests {
+		if got := DateFromSerial(tt.serial).Format(time.DateOnly); got != tt.want {
+			t.Errorf("DateFromSerial(%v) = %s, 期望 %s", tt.serial, got, tt.want)
+		}
+	}
+}
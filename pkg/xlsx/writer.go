@@ -0,0 +1,123 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxCellLength Excel 单元格最多容纳的字符数，超出部分被截断
+const MaxCellLength = 32767
+
+// 工作表之前的固定部件，只有一个名为 Sheet1 的工作表，单元格均为内联字符串，不需要共享字符串表
+var staticParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// Writer 逐行写出只有一个工作表的 xlsx，行写入后即压缩输出，内存占用与行数无关
+type Writer struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+	err   error
+}
+
+// NewWriter 创建 Writer，写完后必须调用 Close
+func NewWriter(w io.Writer) (*Writer, error) {
+	zw := zip.NewWriter(w)
+	for _, p := range staticParts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return nil, err
+		}
+	}
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(f)
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return &Writer{zw: zw, sheet: sheet}, nil
+}
+
+// Write 写出一行，所有单元格按文本保存
+func (w *Writer) Write(row []string) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.rows++
+	r := strconv.Itoa(w.rows)
+	w.sheet.WriteString(`<row r="` + r + `">`)
+	for i, v := range row {
+		if v == "" {
+			continue
+		}
+		w.sheet.WriteString(`<c r="` + columnName(i) + r + `" t="inlineStr"><is><t xml:space="preserve">`)
+		w.err = xml.EscapeText(w.sheet, []byte(cellValue(v)))
+		w.sheet.WriteString(`</t></is></c>`)
+	}
+	w.sheet.WriteString(`</row>`)
+	return w.err
+}
+
+// Flush 将已写入的行压缩输出到底层 Writer
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.err = w.sheet.Flush(); w.err != nil {
+		return w.err
+	}
+	w.err = w.zw.Flush()
+	return w.err
+}
+
+// Close 结束工作表并写出 zip 目录
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+// cellValue 去除 XML 不允许的控制字符并截断超长文本
+func cellValue(v string) string {
+	v = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || r >= 0x20 && r != 0xFFFE && r != 0xFFFF && r != utf8.RuneError {
+			return r
+		}
+		return -1
+	}, v)
+	if utf8.RuneCountInString(v) > MaxCellLength {
+		v = string([]rune(v)[:MaxCellLength])
+	}
+	return v
+}
+
+// columnName 将从 0 开始的列号转换为列字母（0 -> A，26 -> AA）
+func columnName(i int) string {
+	var b []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		b = append([]byte{byte('A' + (i-1)%26)}, b...)
+	}
+	return string(b)
+}
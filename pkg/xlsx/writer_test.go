@@ -0,0 +1,112 @@
+package xlsx
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	long := strings.Repeat("长", MaxCellLength+10)
+
+	tests := []struct {
+		name string
+		rows [][]string
+		want [][]string
+	}{
+		{
+			name: "表头与数据",
+			rows: [][]string{{"id", "username", "email"}, {"1", "alice", "alice@example.com"}, {"2", "张三", "zhang@example.com"}},
+			want: [][]string{{"id", "username", "email"}, {"1", "alice", "alice@example.com"}, {"2", "张三", "zhang@example.com"}},
+		},
+		{
+			name: "XML 特殊字符与空白",
+			rows: [][]string{{`<a href="x">&amp;</a>`, "  前后空格  ", "多\n行\r\n文本", "制表\t符"}},
+			want: [][]string{{`<a href="x">&amp;</a>`, "  前后空格  ", "多\n行\r\n文本", "制表\t符"}},
+		},
+		{
+			name: "去除 XML 不允许的控制字符",
+			rows: [][]string{{"a\x00b\x1fc\ufffed"}},
+			want: [][]string{{"abcd"}},
+		},
+		{
+			name: "空单元格与空行",
+			rows: [][]string{{"", "b", "", "d", ""}, {}, {"x"}},
+			want: [][]string{{"", "b", "", "d"}, nil, {"x"}},
+		},
+		{
+			name: "超过 26 列",
+			rows: [][]string{append(make([]string, 27), "AB")},
+			want: [][]string{append(make([]string, 27), "AB")},
+		},
+		{
+			name: "超长文本被截断",
+			rows: [][]string{{long}},
+			want: [][]string{{long[:len(strings.Repeat("长", MaxCellLength))]}},
+		},
+		{
+			name: "公式前缀按文本保存",
+			rows: [][]string{{"=1+1", "+SUM(A1)", "0012"}},
+			want: [][]string{{"=1+1", "+SUM(A1)", "0012"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf)
+			if err != nil {
+				t.Fatalf("NewWriter 返回错误: %v", err)
+			}
+			for i, row := range tt.rows {
+				if err := w.Write(row); err != nil {
+					t.Fatalf("Write 返回错误: %v", err)
+				}
+				if i == 0 {
+					if err := w.Flush(); err != nil {
+						t.Fatalf("Flush 返回错误: %v", err)
+					}
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close 返回错误: %v", err)
+			}
+
+			if !IsXLSX(buf.Bytes()) {
+				t.Fatal("输出不是 zip 格式")
+			}
+			got, err := ReadRows(buf.Bytes())
+			if err != nil {
+				t.Fatalf("ReadRows 返回错误: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadRows = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+		{16383, "XFD"},
+	}
+	for _, tt := range tests {
+		got := columnName(tt.index)
+		if got != tt.want {
+			t.Errorf("columnName(%d) = %s, 期望 %s", tt.index, got, tt.want)
+		}
+		if back, err := columnIndex(got + "1"); err != nil || back != tt.index {
+			t.Errorf("columnIndex(%s1) = (%d, %v), 期望 %d", got, back, err, tt.index)
+		}
+	}
+}